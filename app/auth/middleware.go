@@ -3,6 +3,7 @@ package auth
 import (
 	apperrors "dungeons/app/errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -34,11 +35,12 @@ func RequireAuth(secret string) gin.HandlerFunc {
 	}
 }
 
-func RequireRole(role string) gin.HandlerFunc {
+// RequireRole lets the request through when the caller has any of roles.
+func RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		v, ok := c.Get(CtxRole)
-		if !ok || v.(string) != role {
-			c.Error(fmt.Errorf("role %s required: %w", role, apperrors.ErrForbidden))
+		if !ok || !slices.Contains(roles, v.(string)) {
+			c.Error(fmt.Errorf("role %s required: %w", strings.Join(roles, " or "), apperrors.ErrForbidden))
 			c.Abort()
 			return
 		}
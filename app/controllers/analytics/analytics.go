@@ -0,0 +1,42 @@
+package analytics
+
+import (
+	"dungeons/app/auth"
+	"dungeons/app/httpapi"
+	service "dungeons/app/services/analytics"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *service.Service
+}
+
+func New(s *service.Service) *Handler {
+	return &Handler{service: s}
+}
+
+func (h *Handler) DungeonStats(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	from, err := httpapi.ParseTimeQuery(c, "from")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	to, err := httpapi.ParseTimeQuery(c, "to")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	stats, err := h.service.DungeonStats(c.Request.Context(), auth.PlayerID(c), dungeonID, from, to)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, stats)
+}
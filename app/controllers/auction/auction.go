@@ -2,10 +2,13 @@ package auction
 
 import (
 	"dungeons/app/auth"
+	apperrors "dungeons/app/errors"
 	"dungeons/app/httpapi"
 	"dungeons/app/models"
 	service "dungeons/app/services/auction"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -34,7 +37,12 @@ func (h *Handler) CreateListing(c *gin.Context) {
 
 func (h *Handler) ListActive(c *gin.Context) {
 	params := httpapi.ParsePagination(c)
-	listings, err := h.service.ListActive(c.Request.Context(), params)
+	var query models.ListingSearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		httpapi.JSONError(c, fmt.Errorf("bind listing search: %w", apperrors.ErrValidation))
+		return
+	}
+	listings, err := h.service.ListActive(c.Request.Context(), query, params)
 	if err != nil {
 		httpapi.JSONError(c, err)
 		return
@@ -80,3 +88,236 @@ func (h *Handler) Cancel(c *gin.Context) {
 	}
 	httpapi.JSON(c, http.StatusOK, listing)
 }
+
+func (h *Handler) ExpireListings(c *gin.Context) {
+	expired, err := h.service.ExpireListings(c.Request.Context())
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, gin.H{"expired": expired})
+}
+
+func (h *Handler) CreateAuction(c *gin.Context) {
+	var req models.CreateAuctionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	auction, err := h.service.CreateAuction(c.Request.Context(), auth.PlayerID(c), req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusCreated, auction)
+}
+
+func (h *Handler) ListAuctions(c *gin.Context) {
+	params := httpapi.ParsePagination(c)
+	auctions, err := h.service.ListAuctions(c.Request.Context(), params)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.Auction]{
+		Data: auctions,
+		Pagination: models.Pagination{
+			Page:  params.Page,
+			Limit: params.Limit,
+		},
+	})
+}
+
+func (h *Handler) GetAuction(c *gin.Context) {
+	auctionID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	auction, err := h.service.GetAuction(c.Request.Context(), auctionID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, auction)
+}
+
+func (h *Handler) PlaceBid(c *gin.Context) {
+	auctionID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	var req models.BidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	auction, err := h.service.PlaceBid(c.Request.Context(), auth.PlayerID(c), auctionID, req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, auction)
+}
+
+func (h *Handler) ListBids(c *gin.Context) {
+	auctionID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	params := httpapi.ParsePagination(c)
+	bids, err := h.service.ListBids(c.Request.Context(), auctionID, params)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.Bid]{
+		Data: bids,
+		Pagination: models.Pagination{
+			Page:  params.Page,
+			Limit: params.Limit,
+		},
+	})
+}
+
+func (h *Handler) MyBids(c *gin.Context) {
+	params := httpapi.ParsePagination(c)
+	bids, err := h.service.MyBids(c.Request.Context(), auth.PlayerID(c), params)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.MyBid]{
+		Data: bids,
+		Pagination: models.Pagination{
+			Page:  params.Page,
+			Limit: params.Limit,
+		},
+	})
+}
+
+func (h *Handler) SettleAuctions(c *gin.Context) {
+	settled, err := h.service.SettleAuctions(c.Request.Context())
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, gin.H{"settled": settled})
+}
+
+func (h *Handler) CreateBuyOrder(c *gin.Context) {
+	var req models.CreateBuyOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	order, err := h.service.CreateBuyOrder(c.Request.Context(), auth.PlayerID(c), req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusCreated, order)
+}
+
+func (h *Handler) CancelBuyOrder(c *gin.Context) {
+	orderID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	order, err := h.service.CancelBuyOrder(c.Request.Context(), auth.PlayerID(c), orderID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, order)
+}
+
+func (h *Handler) MyBuyOrders(c *gin.Context) {
+	params := httpapi.ParsePagination(c)
+	orders, err := h.service.MyBuyOrders(c.Request.Context(), auth.PlayerID(c), params)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.BuyOrder]{
+		Data: orders,
+		Pagination: models.Pagination{
+			Page:  params.Page,
+			Limit: params.Limit,
+		},
+	})
+}
+
+func (h *Handler) OrderBook(c *gin.Context) {
+	itemID, err := httpapi.ParseID(c, "itemId")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	depth, _ := strconv.ParseInt(c.DefaultQuery("depth", "0"), 10, 64)
+	book, err := h.service.OrderBook(c.Request.Context(), itemID, depth)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, book)
+}
+
+func (h *Handler) Treasury(c *gin.Context) {
+	treasury, err := h.service.Treasury(c.Request.Context())
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, treasury)
+}
+
+func (h *Handler) ItemHistory(c *gin.Context) {
+	itemID, err := httpapi.ParseID(c, "itemId")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	buckets, _ := strconv.ParseInt(c.DefaultQuery("buckets", "0"), 10, 64)
+	market, err := h.service.ItemHistory(c.Request.Context(), itemID, c.Query("interval"), buckets)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, market)
+}
+
+func (h *Handler) MyListings(c *gin.Context) {
+	params := httpapi.ParsePagination(c)
+	listings, err := h.service.MyListings(c.Request.Context(), auth.PlayerID(c), params)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.Listing]{
+		Data: listings,
+		Pagination: models.Pagination{
+			Page:  params.Page,
+			Limit: params.Limit,
+		},
+	})
+}
+
+func (h *Handler) MyTrades(c *gin.Context) {
+	params := httpapi.ParsePagination(c)
+	trades, err := h.service.MyTrades(c.Request.Context(), auth.PlayerID(c), models.TradeSide(c.Query("side")), params)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.MyTrade]{
+		Data: trades,
+		Pagination: models.Pagination{
+			Page:  params.Page,
+			Limit: params.Limit,
+		},
+	})
+}
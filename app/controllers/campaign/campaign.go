@@ -0,0 +1,120 @@
+package campaign
+
+import (
+	"dungeons/app/auth"
+	"dungeons/app/httpapi"
+	"dungeons/app/models"
+	service "dungeons/app/services/campaign"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *service.Service
+}
+
+func New(s *service.Service) *Handler {
+	return &Handler{service: s}
+}
+
+func (h *Handler) Create(c *gin.Context) {
+	var req models.CampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	campaign, err := h.service.CreateCampaign(c.Request.Context(), auth.PlayerID(c), req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusCreated, campaign)
+}
+
+func (h *Handler) Update(c *gin.Context) {
+	campaignID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	var req models.CampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	campaign, err := h.service.UpdateCampaign(c.Request.Context(), auth.PlayerID(c), campaignID, req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, campaign)
+}
+
+func (h *Handler) Publish(c *gin.Context) {
+	h.setStatus(c, models.CampaignStatusPublished)
+}
+
+func (h *Handler) Unpublish(c *gin.Context) {
+	h.setStatus(c, models.CampaignStatusDraft)
+}
+
+func (h *Handler) setStatus(c *gin.Context, status models.CampaignStatus) {
+	campaignID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	campaign, err := h.service.SetStatus(c.Request.Context(), auth.PlayerID(c), campaignID, status)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, campaign)
+}
+
+func (h *Handler) ListMine(c *gin.Context) {
+	params := httpapi.ParsePagination(c)
+	campaigns, err := h.service.ListMine(c.Request.Context(), auth.PlayerID(c), params)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.Campaign]{
+		Data: campaigns,
+		Pagination: models.Pagination{
+			Page:  params.Page,
+			Limit: params.Limit,
+		},
+	})
+}
+
+func (h *Handler) ListPublished(c *gin.Context) {
+	params := httpapi.ParsePagination(c)
+	campaigns, err := h.service.ListPublished(c.Request.Context(), params)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.Campaign]{
+		Data: campaigns,
+		Pagination: models.Pagination{
+			Page:  params.Page,
+			Limit: params.Limit,
+		},
+	})
+}
+
+func (h *Handler) Progress(c *gin.Context) {
+	campaignID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	progress, err := h.service.Progress(c.Request.Context(), auth.PlayerID(c), campaignID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, progress)
+}
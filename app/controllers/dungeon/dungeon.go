@@ -1,11 +1,17 @@
 package dungeon
 
 import (
+	"bytes"
 	"dungeons/app/auth"
+	apperrors "dungeons/app/errors"
 	"dungeons/app/httpapi"
 	"dungeons/app/models"
 	service "dungeons/app/services/dungeon"
+	mediaservice "dungeons/app/services/media"
+	"encoding/csv"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -66,6 +72,25 @@ func (h *Handler) PublishDungeon(c *gin.Context) {
 	httpapi.JSON(c, http.StatusOK, d)
 }
 
+func (h *Handler) ScheduleDungeon(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	var req models.ScheduleDungeonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	d, err := h.service.ScheduleDungeon(c.Request.Context(), auth.PlayerID(c), dungeonID, req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, d)
+}
+
 func (h *Handler) CreateStep(c *gin.Context) {
 	dungeonID, err := httpapi.ParseID(c, "id")
 	if err != nil {
@@ -130,12 +155,35 @@ func (h *Handler) ReorderSteps(c *gin.Context) {
 
 func (h *Handler) ListPublished(c *gin.Context) {
 	params := httpapi.ParsePagination(c)
-	out, err := h.service.ListPublished(c.Request.Context(), params)
+	var query models.DungeonSearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		httpapi.JSONError(c, fmt.Errorf("bind search query: %w", apperrors.ErrValidation))
+		return
+	}
+	res, err := h.service.SearchPublished(c.Request.Context(), query, params, httpapi.Languages(c))
 	if err != nil {
 		httpapi.JSONError(c, err)
 		return
 	}
-	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.Dungeon]{
+	httpapi.JSON(c, http.StatusOK, models.DungeonSearchResponse{
+		Data: res.Dungeons,
+		Pagination: models.Pagination{
+			Page:  params.Page,
+			Limit: params.Limit,
+		},
+		Total:  res.Total,
+		Facets: res.Facets,
+	})
+}
+
+func (h *Handler) ListUpcoming(c *gin.Context) {
+	params := httpapi.ParsePagination(c)
+	out, err := h.service.ListUpcoming(c.Request.Context(), params, httpapi.Languages(c))
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.UpcomingDungeon]{
 		Data: out,
 		Pagination: models.Pagination{
 			Page:  params.Page,
@@ -150,10 +198,438 @@ func (h *Handler) GetPublished(c *gin.Context) {
 		httpapi.JSONError(c, err)
 		return
 	}
-	d, steps, err := h.service.GetPublishedByID(c.Request.Context(), dungeonID)
+	d, steps, err := h.service.GetPublishedByID(c.Request.Context(), dungeonID, httpapi.Languages(c))
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, gin.H{"dungeon": d, "steps": steps, "route": h.service.PlanRoute(steps)})
+}
+
+func (h *Handler) CloneDungeon(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	var req models.CloneDungeonRequest
+	if err := httpapi.BindOptionalJSON(c, &req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	d, steps, err := h.service.CloneDungeon(c.Request.Context(), auth.PlayerID(c), dungeonID, req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusCreated, gin.H{"dungeon": d, "steps": steps})
+}
+
+func (h *Handler) MarkTemplate(c *gin.Context) {
+	h.setTemplate(c, true)
+}
+
+func (h *Handler) UnmarkTemplate(c *gin.Context) {
+	h.setTemplate(c, false)
+}
+
+func (h *Handler) setTemplate(c *gin.Context, isTemplate bool) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	d, err := h.service.SetTemplate(c.Request.Context(), auth.PlayerID(c), dungeonID, isTemplate)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, d)
+}
+
+func (h *Handler) ListTemplates(c *gin.Context) {
+	params := httpapi.ParsePagination(c)
+	out, err := h.service.ListTemplates(c.Request.Context(), params)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.Dungeon]{
+		Data: out,
+		Pagination: models.Pagination{
+			Page:  params.Page,
+			Limit: params.Limit,
+		},
+	})
+}
+
+func (h *Handler) InstantiateTemplate(c *gin.Context) {
+	templateID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	var req models.CloneDungeonRequest
+	if err := httpapi.BindOptionalJSON(c, &req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	d, steps, err := h.service.InstantiateTemplate(c.Request.Context(), auth.PlayerID(c), templateID, req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusCreated, gin.H{"dungeon": d, "steps": steps})
+}
+
+func (h *Handler) ListMine(c *gin.Context) {
+	params := httpapi.ParsePagination(c)
+	out, err := h.service.ListMine(c.Request.Context(), auth.PlayerID(c), params)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.Dungeon]{
+		Data: out,
+		Pagination: models.Pagination{
+			Page:  params.Page,
+			Limit: params.Limit,
+		},
+	})
+}
+
+func (h *Handler) ListCollaborators(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	out, err := h.service.ListCollaborators(c.Request.Context(), auth.PlayerID(c), dungeonID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, out)
+}
+
+func (h *Handler) AddCollaborator(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	var req models.AddCollaboratorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	d, err := h.service.AddCollaborator(c.Request.Context(), auth.PlayerID(c), dungeonID, req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, d)
+}
+
+func (h *Handler) RemoveCollaborator(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	playerID, err := httpapi.ParseID(c, "playerId")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	d, err := h.service.RemoveCollaborator(c.Request.Context(), auth.PlayerID(c), dungeonID, playerID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, d)
+}
+
+func (h *Handler) TransferOwnership(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	var req models.TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	d, err := h.service.TransferOwnership(c.Request.Context(), auth.PlayerID(c), dungeonID, req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, d)
+}
+
+func (h *Handler) TranslationReport(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	report, err := h.service.TranslationReport(c.Request.Context(), auth.PlayerID(c), dungeonID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, report)
+}
+
+func (h *Handler) SetDungeonTranslation(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	lang, err := httpapi.ParseID(c, "lang")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	var req models.DungeonTranslation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	d, err := h.service.SetDungeonTranslation(c.Request.Context(), auth.PlayerID(c), dungeonID, lang, req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, d)
+}
+
+func (h *Handler) SetStepTranslation(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	stepID, err := httpapi.ParseID(c, "stepId")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	lang, err := httpapi.ParseID(c, "lang")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	var req models.StepTranslation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	step, err := h.service.SetStepTranslation(c.Request.Context(), auth.PlayerID(c), dungeonID, stepID, lang, req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, step)
+}
+
+func (h *Handler) RemoveTranslation(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	lang, err := httpapi.ParseID(c, "lang")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	d, err := h.service.RemoveTranslation(c.Request.Context(), auth.PlayerID(c), dungeonID, lang)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, d)
+}
+
+func (h *Handler) SetProofPolicy(c *gin.Context) {
+	dungeonID, stepID, ok := stepParams(c)
+	if !ok {
+		return
+	}
+	var req models.ProofPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	step, err := h.service.SetProofPolicy(c.Request.Context(), auth.PlayerID(c), dungeonID, stepID, req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, step)
+}
+
+func (h *Handler) RotateProofCode(c *gin.Context) {
+	dungeonID, stepID, ok := stepParams(c)
+	if !ok {
+		return
+	}
+	step, err := h.service.RotateProofCode(c.Request.Context(), auth.PlayerID(c), dungeonID, stepID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, step)
+}
+
+func (h *Handler) RevokeProofCode(c *gin.Context) {
+	dungeonID, stepID, ok := stepParams(c)
+	if !ok {
+		return
+	}
+	step, err := h.service.RevokeProofCode(c.Request.Context(), auth.PlayerID(c), dungeonID, stepID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, step)
+}
+
+func (h *Handler) CodeSheet(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	sheet, err := h.service.CodeSheet(c.Request.Context(), auth.PlayerID(c), dungeonID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	if c.Query("format") != "csv" {
+		httpapi.JSON(c, http.StatusOK, sheet)
+		return
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"order", "stepId", "name", "require", "mode", "version", "code", "payload", "keyUri"})
+	for _, e := range sheet {
+		_ = w.Write([]string{strconv.Itoa(e.Order), e.StepID, e.Name, string(e.Require), string(e.Mode), strconv.Itoa(e.Version), e.Code, e.Payload, e.KeyURI})
+	}
+	w.Flush()
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="codes-%s.csv"`, dungeonID))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+func stepParams(c *gin.Context) (string, string, bool) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return "", "", false
+	}
+	stepID, err := httpapi.ParseID(c, "stepId")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return "", "", false
+	}
+	return dungeonID, stepID, true
+}
+
+func (h *Handler) SetCover(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	file, err := httpapi.UploadedFile(c, "image", mediaservice.MaxImageBytes)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	defer file.Close()
+	dungeon, err := h.service.SetCover(c.Request.Context(), auth.PlayerID(c), dungeonID, file)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, dungeon)
+}
+
+func (h *Handler) RemoveCover(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	dungeon, err := h.service.RemoveCover(c.Request.Context(), auth.PlayerID(c), dungeonID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, dungeon)
+}
+
+func (h *Handler) SetStepArt(c *gin.Context) {
+	dungeonID, stepID, ok := stepParams(c)
+	if !ok {
+		return
+	}
+	file, err := httpapi.UploadedFile(c, "image", mediaservice.MaxImageBytes)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	defer file.Close()
+	step, err := h.service.SetStepArt(c.Request.Context(), auth.PlayerID(c), dungeonID, stepID, file)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, step)
+}
+
+func (h *Handler) RemoveStepArt(c *gin.Context) {
+	dungeonID, stepID, ok := stepParams(c)
+	if !ok {
+		return
+	}
+	step, err := h.service.RemoveStepArt(c.Request.Context(), auth.PlayerID(c), dungeonID, stepID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, step)
+}
+
+func (h *Handler) MapGeoJSON(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	fc, err := h.service.MapGeoJSON(c.Request.Context(), dungeonID, httpapi.Languages(c))
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.GeoJSON(c, http.StatusOK, fc)
+}
+
+func (h *Handler) DungeonRoute(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	plan, err := h.service.DungeonRoute(c.Request.Context(), auth.PlayerID(c), dungeonID)
 	if err != nil {
 		httpapi.JSONError(c, err)
 		return
 	}
-	httpapi.JSON(c, http.StatusOK, gin.H{"dungeon": d, "steps": steps})
+	httpapi.JSON(c, http.StatusOK, plan)
 }
@@ -0,0 +1,116 @@
+package mail
+
+import (
+	"dungeons/app/auth"
+	"dungeons/app/httpapi"
+	"dungeons/app/models"
+	service "dungeons/app/services/mail"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *service.Service
+}
+
+func New(s *service.Service) *Handler {
+	return &Handler{service: s}
+}
+
+func (h *Handler) Send(c *gin.Context) {
+	var req models.SendMailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	mail, err := h.service.Send(c.Request.Context(), auth.PlayerID(c), req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusCreated, mail)
+}
+
+func (h *Handler) Inbox(c *gin.Context) {
+	params := httpapi.ParsePagination(c)
+	mail, err := h.service.Inbox(c.Request.Context(), auth.PlayerID(c), params)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.Mail]{
+		Data: mail,
+		Pagination: models.Pagination{
+			Page:  params.Page,
+			Limit: params.Limit,
+		},
+	})
+}
+
+func (h *Handler) Sent(c *gin.Context) {
+	params := httpapi.ParsePagination(c)
+	mail, err := h.service.Sent(c.Request.Context(), auth.PlayerID(c), params)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.Mail]{
+		Data: mail,
+		Pagination: models.Pagination{
+			Page:  params.Page,
+			Limit: params.Limit,
+		},
+	})
+}
+
+func (h *Handler) Get(c *gin.Context) {
+	mailID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	mail, err := h.service.Get(c.Request.Context(), auth.PlayerID(c), mailID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, mail)
+}
+
+func (h *Handler) Claim(c *gin.Context) {
+	mailID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	mail, err := h.service.Claim(c.Request.Context(), auth.PlayerID(c), mailID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, mail)
+}
+
+func (h *Handler) SendSystem(c *gin.Context) {
+	var req models.SystemMailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	sent, err := h.service.SendSystem(c.Request.Context(), auth.PlayerID(c), req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusCreated, gin.H{"sent": sent})
+}
+
+func (h *Handler) ExpireMail(c *gin.Context) {
+	expired, err := h.service.ExpireMail(c.Request.Context())
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, gin.H{"expired": expired})
+}
@@ -0,0 +1,37 @@
+package media
+
+import (
+	"dungeons/app/httpapi"
+	service "dungeons/app/services/media"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *service.Service
+}
+
+func New(s *service.Service) *Handler {
+	return &Handler{service: s}
+}
+
+// Get serves an image. Names are content hashes, so a name never changes
+// content and the response can be cached for good.
+func (h *Handler) Get(c *gin.Context) {
+	name := c.Param("name")
+	etag := `"` + name + `"`
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	blob, contentType, err := h.service.Open(c.Request.Context(), name)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	defer blob.Close()
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Header("ETag", etag)
+	c.DataFromReader(http.StatusOK, -1, contentType, blob, map[string]string{"X-Content-Type-Options": "nosniff"})
+}
@@ -0,0 +1,149 @@
+package offer
+
+import (
+	"dungeons/app/auth"
+	"dungeons/app/httpapi"
+	"dungeons/app/models"
+	service "dungeons/app/services/offer"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *service.Service
+}
+
+func New(s *service.Service) *Handler {
+	return &Handler{service: s}
+}
+
+func (h *Handler) Create(c *gin.Context) {
+	var req models.CreateOfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	offer, err := h.service.Create(c.Request.Context(), auth.PlayerID(c), req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusCreated, offer)
+}
+
+func (h *Handler) Get(c *gin.Context) {
+	offerID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	offer, err := h.service.Get(c.Request.Context(), auth.PlayerID(c), offerID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, offer)
+}
+
+func (h *Handler) Inbox(c *gin.Context) {
+	params := httpapi.ParsePagination(c)
+	offers, err := h.service.Inbox(c.Request.Context(), auth.PlayerID(c), params)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.TradeOffer]{
+		Data: offers,
+		Pagination: models.Pagination{
+			Page:  params.Page,
+			Limit: params.Limit,
+		},
+	})
+}
+
+func (h *Handler) Sent(c *gin.Context) {
+	params := httpapi.ParsePagination(c)
+	offers, err := h.service.Sent(c.Request.Context(), auth.PlayerID(c), params)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.TradeOffer]{
+		Data: offers,
+		Pagination: models.Pagination{
+			Page:  params.Page,
+			Limit: params.Limit,
+		},
+	})
+}
+
+func (h *Handler) Accept(c *gin.Context) {
+	offerID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	offer, err := h.service.Accept(c.Request.Context(), auth.PlayerID(c), offerID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, offer)
+}
+
+func (h *Handler) Decline(c *gin.Context) {
+	offerID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	offer, err := h.service.Decline(c.Request.Context(), auth.PlayerID(c), offerID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, offer)
+}
+
+func (h *Handler) Cancel(c *gin.Context) {
+	offerID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	offer, err := h.service.Cancel(c.Request.Context(), auth.PlayerID(c), offerID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, offer)
+}
+
+func (h *Handler) Counter(c *gin.Context) {
+	offerID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	var req models.CounterOfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	offer, err := h.service.Counter(c.Request.Context(), auth.PlayerID(c), offerID, req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusCreated, offer)
+}
+
+func (h *Handler) ExpireOffers(c *gin.Context) {
+	expired, err := h.service.ExpireOffers(c.Request.Context())
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, gin.H{"expired": expired})
+}
@@ -104,3 +104,22 @@ func (h *Handler) Update(c *gin.Context) {
 	}
 	httpapi.JSON(c, http.StatusOK, player)
 }
+
+func (h *Handler) GrantGold(c *gin.Context) {
+	id, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	var req models.GrantGoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	player, err := h.service.GrantGold(c.Request.Context(), auth.PlayerID(c), id, req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, player)
+}
@@ -0,0 +1,102 @@
+package review
+
+import (
+	"dungeons/app/auth"
+	"dungeons/app/httpapi"
+	"dungeons/app/models"
+	service "dungeons/app/services/review"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *service.Service
+}
+
+func New(s *service.Service) *Handler {
+	return &Handler{service: s}
+}
+
+func (h *Handler) Upsert(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	var req models.UpsertReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	review, err := h.service.Upsert(c.Request.Context(), auth.PlayerID(c), dungeonID, req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, review)
+}
+
+func (h *Handler) List(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	params := httpapi.ParsePagination(c)
+	reviews, err := h.service.List(c.Request.Context(), dungeonID, params)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.Review]{
+		Data: reviews,
+		Pagination: models.Pagination{
+			Page:  params.Page,
+			Limit: params.Limit,
+		},
+	})
+}
+
+func (h *Handler) Reply(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	reviewID, err := httpapi.ParseID(c, "reviewId")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	var req models.ReplyReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	review, err := h.service.Reply(c.Request.Context(), auth.PlayerID(c), dungeonID, reviewID, req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, review)
+}
+
+func (h *Handler) Moderate(c *gin.Context) {
+	reviewID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	var req models.ModerateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	review, err := h.service.Moderate(c.Request.Context(), auth.PlayerID(c), reviewID, req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, review)
+}
@@ -2,10 +2,14 @@ package run
 
 import (
 	"dungeons/app/auth"
+	apperrors "dungeons/app/errors"
 	"dungeons/app/httpapi"
 	"dungeons/app/models"
 	service "dungeons/app/services/run"
+	"encoding/xml"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -85,3 +89,213 @@ func (h *Handler) Attempt(c *gin.Context) {
 	}
 	httpapi.JSON(c, http.StatusOK, attempt)
 }
+
+func (h *Handler) BuyHint(c *gin.Context) {
+	runID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	stepID, err := httpapi.ParseID(c, "stepId")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		httpapi.JSONError(c, fmt.Errorf("invalid hint index: %w", apperrors.ErrValidation))
+		return
+	}
+	hint, err := h.service.BuyHint(c.Request.Context(), auth.PlayerID(c), runID, stepID, index)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, hint)
+}
+
+func (h *Handler) StartDryRun(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	run, err := h.service.StartDryRun(c.Request.Context(), auth.PlayerID(c), dungeonID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusCreated, run)
+}
+
+func (h *Handler) GetDryRun(c *gin.Context) {
+	runID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	run, err := h.service.GetDryRun(c.Request.Context(), auth.PlayerID(c), runID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, run)
+}
+
+func (h *Handler) DryRunAttempt(c *gin.Context) {
+	runID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	stepID, err := httpapi.ParseID(c, "stepId")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	var req models.DryRunAttemptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	attempt, err := h.service.DryRunAttempt(c.Request.Context(), auth.PlayerID(c), runID, stepID, req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, attempt)
+}
+
+func (h *Handler) SubmitPhoto(c *gin.Context) {
+	runID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	stepID, err := httpapi.ParseID(c, "stepId")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	photo, err := httpapi.UploadedFile(c, "photo", service.MaxPhotoBytes)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	defer photo.Close()
+	var req models.PhotoAttemptRequest
+	if err := c.ShouldBind(&req); err != nil {
+		httpapi.JSONError(c, fmt.Errorf("invalid photo form: %w", apperrors.ErrValidation))
+		return
+	}
+	sub, err := h.service.SubmitPhoto(c.Request.Context(), auth.PlayerID(c), runID, stepID, req, photo)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusAccepted, sub)
+}
+
+func (h *Handler) ReviewQueue(c *gin.Context) {
+	dungeonID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	params := httpapi.ParsePagination(c)
+	subs, err := h.service.ReviewQueue(c.Request.Context(), auth.PlayerID(c), dungeonID, params)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, models.ListResponse[models.PhotoSubmission]{
+		Data: subs,
+		Pagination: models.Pagination{
+			Page:  params.Page,
+			Limit: params.Limit,
+		},
+	})
+}
+
+func (h *Handler) SubmissionPhoto(c *gin.Context) {
+	submissionID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	photo, sub, err := h.service.OpenSubmissionPhoto(c.Request.Context(), auth.PlayerID(c), submissionID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	defer photo.Close()
+	c.Header("Cache-Control", "private, no-store")
+	c.DataFromReader(http.StatusOK, sub.Size, sub.ContentType, photo, nil)
+}
+
+func (h *Handler) ApprovePhoto(c *gin.Context) {
+	submissionID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	resp, err := h.service.ApprovePhoto(c.Request.Context(), auth.PlayerID(c), submissionID)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, resp)
+}
+
+func (h *Handler) RejectPhoto(c *gin.Context) {
+	submissionID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	var req models.RejectPhotoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	resp, err := h.service.RejectPhoto(c.Request.Context(), auth.PlayerID(c), submissionID, req)
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.JSON(c, http.StatusOK, resp)
+}
+
+func (h *Handler) TrackGeoJSON(c *gin.Context) {
+	runID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	fc, err := h.service.TrackGeoJSON(c.Request.Context(), auth.PlayerID(c), runID, httpapi.Languages(c))
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	httpapi.GeoJSON(c, http.StatusOK, fc)
+}
+
+func (h *Handler) TrackGPX(c *gin.Context) {
+	runID, err := httpapi.ParseID(c, "id")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	track, err := h.service.TrackGPX(c.Request.Context(), auth.PlayerID(c), runID, httpapi.Languages(c))
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	body, err := xml.MarshalIndent(track, "", "  ")
+	if err != nil {
+		httpapi.JSONError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="run-%s.gpx"`, runID))
+	c.Data(http.StatusOK, "application/gpx+xml", append([]byte(xml.Header), body...))
+}
@@ -13,4 +13,8 @@ var (
 	ErrNotInRange      = errors.New("not_in_range")
 	ErrAlreadyHandled  = errors.New("already_handled")
 	ErrInvalidArgument = errors.New("invalid_argument")
+	ErrWrongAnswer     = errors.New("wrong_answer")
+	ErrNoTriesLeft     = errors.New("no_tries_left")
+	ErrInvalidProof    = errors.New("invalid_proof")
+	ErrLocked          = errors.New("locked")
 )
@@ -47,3 +47,12 @@ func TestEncrypt_Decrypt(t *testing.T) {
 	}
 
 }
+
+func TestNormalizeAnswer(t *testing.T) {
+	if NormalizeAnswer("  Le  Grand ÉLÉPHANT ") != "le grand elephant" {
+		t.Error("case, accents or spacing not folded")
+	}
+	if NormalizeAnswer("Noël") != NormalizeAnswer("noel") {
+		t.Error("diaeresis not folded")
+	}
+}
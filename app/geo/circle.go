@@ -0,0 +1,14 @@
+package geo
+
+// Circle approximates a circle as a closed ring of [lon, lat] positions, the
+// order GeoJSON expects. The ring runs counterclockwise from north, as RFC
+// 7946 asks of exterior rings.
+func Circle(lat, lon, radiusMeters float64, segments int) [][2]float64 {
+	segments = max(segments, 3)
+	ring := make([][2]float64, 0, segments+1)
+	for i := 0; i < segments; i++ {
+		pLat, pLon := Destination(lat, lon, 360-float64(i)*360/float64(segments), radiusMeters)
+		ring = append(ring, [2]float64{pLon, pLat})
+	}
+	return append(ring, ring[0])
+}
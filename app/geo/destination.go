@@ -0,0 +1,43 @@
+package geo
+
+import "math"
+
+func InitialBearingDegrees(lat1, lon1, lat2, lon2 float64) float64 {
+	lat1R := toRadians(lat1)
+	lat2R := toRadians(lat2)
+	dLon := toRadians(lon2 - lon1)
+
+	y := math.Sin(dLon) * math.Cos(lat2R)
+	x := math.Cos(lat1R)*math.Sin(lat2R) - math.Sin(lat1R)*math.Cos(lat2R)*math.Cos(dLon)
+	return math.Mod(toDegrees(math.Atan2(y, x))+360, 360)
+}
+
+func Destination(lat, lon, bearingDegrees, distanceMeters float64) (float64, float64) {
+	latR := toRadians(lat)
+	lonR := toRadians(lon)
+	brng := toRadians(bearingDegrees)
+	delta := distanceMeters / earthRadiusMeters
+
+	lat2 := math.Asin(math.Sin(latR)*math.Cos(delta) + math.Cos(latR)*math.Sin(delta)*math.Cos(brng))
+	lon2 := lonR + math.Atan2(math.Sin(brng)*math.Sin(delta)*math.Cos(latR), math.Cos(delta)-math.Sin(latR)*math.Sin(lat2))
+	return toDegrees(lat2), normalizeLongitude(toDegrees(lon2))
+}
+
+// Translate moves a point so that it keeps the same distance and bearing from
+// newAnchor as it had from oldAnchor.
+func Translate(oldAnchorLat, oldAnchorLon, newAnchorLat, newAnchorLon, lat, lon float64) (float64, float64) {
+	distance := HaversineMeters(oldAnchorLat, oldAnchorLon, lat, lon)
+	if distance == 0 {
+		return newAnchorLat, newAnchorLon
+	}
+	bearing := InitialBearingDegrees(oldAnchorLat, oldAnchorLon, lat, lon)
+	return Destination(newAnchorLat, newAnchorLon, bearing, distance)
+}
+
+func toDegrees(v float64) float64 {
+	return v * (180 / math.Pi)
+}
+
+func normalizeLongitude(lon float64) float64 {
+	return math.Mod(lon+540, 360) - 180
+}
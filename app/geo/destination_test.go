@@ -0,0 +1,52 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDestinationRoundTrip(t *testing.T) {
+	lat, lon := Destination(48.8566, 2.3522, 90, 1000)
+	d := HaversineMeters(48.8566, 2.3522, lat, lon)
+	if math.Abs(d-1000) > 0.5 {
+		t.Fatalf("unexpected distance %.2f", d)
+	}
+	b := InitialBearingDegrees(48.8566, 2.3522, lat, lon)
+	if math.Abs(b-90) > 0.1 {
+		t.Fatalf("unexpected bearing %.2f", b)
+	}
+}
+
+func TestTranslateKeepsRelativeGeometry(t *testing.T) {
+	// Paris route moved to Lyon: legs keep their length.
+	aLat, aLon := 48.8566, 2.3522
+	bLat, bLon := 48.8584, 2.2945
+	newALat, newALon := 45.7640, 4.8357
+
+	newBLat, newBLon := Translate(aLat, aLon, newALat, newALon, bLat, bLon)
+	before := HaversineMeters(aLat, aLon, bLat, bLon)
+	after := HaversineMeters(newALat, newALon, newBLat, newBLon)
+	if math.Abs(before-after) > 1 {
+		t.Fatalf("leg length changed: %.2f -> %.2f", before, after)
+	}
+}
+
+func TestCircleIsClosedCounterclockwiseAtRadius(t *testing.T) {
+	ring := Circle(48.8566, 2.3522, 50, 32)
+	if len(ring) != 33 || ring[0] != ring[32] {
+		t.Fatalf("ring not closed: %d points", len(ring))
+	}
+	for _, p := range ring {
+		if d := HaversineMeters(48.8566, 2.3522, p[1], p[0]); math.Abs(d-50) > 0.01 {
+			t.Fatalf("point %v at %.3f m", p, d)
+		}
+	}
+	// A positive shoelace area in lon/lat means counterclockwise.
+	area := 0.0
+	for i := 0; i < len(ring)-1; i++ {
+		area += ring[i][0]*ring[i+1][1] - ring[i+1][0]*ring[i][1]
+	}
+	if area <= 0 {
+		t.Fatalf("ring is clockwise (signed area %g)", area)
+	}
+}
@@ -0,0 +1,67 @@
+package geo
+
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// PathMeters is the straight-line length of visiting points in order.
+func PathMeters(points []Point, order []int) float64 {
+	total := 0.0
+	for i := 1; i < len(order); i++ {
+		a, b := points[order[i-1]], points[order[i]]
+		total += HaversineMeters(a.Lat, a.Lon, b.Lat, b.Lon)
+	}
+	return total
+}
+
+// PlanRoute suggests a short open path through all points starting at the
+// first one: nearest neighbour, then 2-opt until no swap helps. It is a
+// heuristic, fine for the few dozen steps a dungeon has.
+func PlanRoute(points []Point) []int {
+	n := len(points)
+	if n == 0 {
+		return []int{}
+	}
+	dist := make([][]float64, n)
+	for i := range points {
+		dist[i] = make([]float64, n)
+		for j := range points {
+			dist[i][j] = HaversineMeters(points[i].Lat, points[i].Lon, points[j].Lat, points[j].Lon)
+		}
+	}
+
+	order := []int{0}
+	visited := make([]bool, n)
+	visited[0] = true
+	for len(order) < n {
+		last, next := order[len(order)-1], -1
+		for j := 0; j < n; j++ {
+			if !visited[j] && (next < 0 || dist[last][j] < dist[last][next]) {
+				next = j
+			}
+		}
+		visited[next] = true
+		order = append(order, next)
+	}
+
+	const epsilon = 1e-6
+	for improved, rounds := true, 0; improved && rounds < 100; rounds++ {
+		improved = false
+		for i := 1; i < n-1; i++ {
+			for k := i + 1; k < n; k++ {
+				delta := dist[order[i-1]][order[k]] - dist[order[i-1]][order[i]]
+				if k+1 < n {
+					delta += dist[order[i]][order[k+1]] - dist[order[k]][order[k+1]]
+				}
+				if delta < -epsilon {
+					for a, b := i, k; a < b; a, b = a+1, b-1 {
+						order[a], order[b] = order[b], order[a]
+					}
+					improved = true
+				}
+			}
+		}
+	}
+	return order
+}
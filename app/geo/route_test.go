@@ -0,0 +1,22 @@
+package geo
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPlanRouteWalksAlongAStreet(t *testing.T) {
+	// Five stops 100 m apart on one meridian, listed out of order.
+	lats := []float64{0, 4, 1, 3, 2}
+	points := make([]Point, 0, len(lats))
+	for _, l := range lats {
+		points = append(points, Point{Lat: 48.85 + l*0.0009, Lon: 2.35})
+	}
+	order := PlanRoute(points)
+	if want := []int{0, 2, 4, 3, 1}; !slices.Equal(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	if PathMeters(points, order) >= PathMeters(points, []int{0, 1, 2, 3, 4}) {
+		t.Fatal("suggested order is not shorter than the given one")
+	}
+}
@@ -2,11 +2,16 @@ package httpapi
 
 import (
 	apperrors "dungeons/app/errors"
+	"dungeons/app/i18n"
 	"dungeons/app/models"
 	"fmt"
+	"mime/multipart"
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
 )
 
 func ParseID(c *gin.Context, key string) (string, error) {
@@ -22,3 +27,46 @@ func ParsePagination(c *gin.Context) models.QueryParams {
 	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "20"), 10, 64)
 	return models.QueryParams{Page: page, Limit: limit}.Normalize()
 }
+
+func BindOptionalJSON(c *gin.Context, out any) error {
+	if c.Request.ContentLength == 0 {
+		return nil
+	}
+	return c.ShouldBindJSON(out)
+}
+
+// ParseTimeQuery accepts RFC3339 timestamps or plain dates (UTC midnight).
+func ParseTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			t = t.UTC()
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid time in query param %s: %w", key, apperrors.ErrValidation)
+}
+
+// Languages returns the caller's content language preferences: the lang
+// query parameter first, then Accept-Language.
+func Languages(c *gin.Context) []language.Tag {
+	return i18n.Preferences(c.Query("lang"), c.GetHeader("Accept-Language"))
+}
+
+// UploadedFile caps the request body at limit plus some room for the other
+// form fields and opens the multipart file in field.
+func UploadedFile(c *gin.Context, field string, limit int64) (multipart.File, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit+64<<10)
+	header, err := c.FormFile(field)
+	if err != nil {
+		return nil, fmt.Errorf("missing %s file: %w", field, apperrors.ErrValidation)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open %s upload: %w", field, err)
+	}
+	return file, nil
+}
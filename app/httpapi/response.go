@@ -15,6 +15,11 @@ func JSON(c *gin.Context, status int, payload interface{}) {
 	c.JSON(status, payload)
 }
 
+func GeoJSON(c *gin.Context, status int, payload interface{}) {
+	c.Header("Content-Type", "application/geo+json")
+	c.JSON(status, payload)
+}
+
 func JSONError(c *gin.Context, err error) {
 	status, code := MapError(err)
 	c.JSON(status, models.ErrorEnvelope{
@@ -37,12 +42,20 @@ func MapError(err error) (int, string) {
 		return http.StatusUnauthorized, "unauthorized"
 	case errors.Is(err, apperrors.ErrForbidden):
 		return http.StatusForbidden, "forbidden"
+	case errors.Is(err, apperrors.ErrLocked):
+		return http.StatusForbidden, "DUNGEON_LOCKED"
 	case errors.Is(err, apperrors.ErrNotFound):
 		return http.StatusNotFound, "not_found"
 	case errors.Is(err, apperrors.ErrWrongStepOrder):
 		return http.StatusConflict, "WRONG_STEP_ORDER"
 	case errors.Is(err, apperrors.ErrNotInRange):
 		return http.StatusConflict, "NOT_IN_RANGE"
+	case errors.Is(err, apperrors.ErrWrongAnswer):
+		return http.StatusConflict, "WRONG_ANSWER"
+	case errors.Is(err, apperrors.ErrNoTriesLeft):
+		return http.StatusConflict, "NO_TRIES_LEFT"
+	case errors.Is(err, apperrors.ErrInvalidProof):
+		return http.StatusConflict, "INVALID_PROOF"
 	case errors.Is(err, apperrors.ErrAlreadyHandled):
 		return http.StatusConflict, "ATTEMPT_ALREADY_HANDLED"
 	case errors.Is(err, apperrors.ErrConflict):
@@ -0,0 +1,52 @@
+package i18n
+
+import (
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLang labels content created before dungeons carried a language.
+const DefaultLang = "en"
+
+// Normalize returns the canonical form of a BCP 47 tag ("en-us" -> "en-US").
+func Normalize(tag string) (string, bool) {
+	t, err := language.Parse(strings.TrimSpace(tag))
+	if err != nil || t == language.Und {
+		return "", false
+	}
+	return t.String(), true
+}
+
+// Preferences merges an explicit lang parameter, which wins, with an
+// Accept-Language header. Unparseable input is ignored.
+func Preferences(lang, acceptLanguage string) []language.Tag {
+	var prefs []language.Tag
+	if t, err := language.Parse(strings.TrimSpace(lang)); err == nil && t != language.Und {
+		prefs = append(prefs, t)
+	}
+	if tags, _, err := language.ParseAcceptLanguage(acceptLanguage); err == nil {
+		prefs = append(prefs, tags...)
+	}
+	return prefs
+}
+
+// Pick chooses the best of the available languages for prefs. available[0]
+// is the fallback used when nothing matches.
+func Pick(prefs []language.Tag, available []string) string {
+	if len(available) == 0 {
+		return ""
+	}
+	if len(prefs) == 0 || len(available) == 1 {
+		return available[0]
+	}
+	tags := make([]language.Tag, 0, len(available))
+	for _, a := range available {
+		tags = append(tags, language.Make(a))
+	}
+	_, idx, conf := language.NewMatcher(tags).Match(prefs...)
+	if conf == language.No {
+		return available[0]
+	}
+	return available[idx]
+}
@@ -0,0 +1,32 @@
+package i18n
+
+import "testing"
+
+func TestPickFallsBackToDefault(t *testing.T) {
+	available := []string{"fr", "en"}
+	cases := []struct {
+		lang, accept, want string
+	}{
+		{"", "", "fr"},
+		{"", "en-GB,en;q=0.9", "en"},
+		{"fr", "en-GB,en;q=0.9", "fr"},
+		{"", "de-DE", "fr"},
+		{"de", "en;q=0.5", "en"},
+		{"not a tag", "", "fr"},
+	}
+	for _, tc := range cases {
+		got := Pick(Preferences(tc.lang, tc.accept), available)
+		if got != tc.want {
+			t.Errorf("lang=%q accept=%q: got %q, want %q", tc.lang, tc.accept, got, tc.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got, ok := Normalize("en-us"); !ok || got != "en-US" {
+		t.Fatalf("Normalize(en-us) = %q, %v", got, ok)
+	}
+	if _, ok := Normalize("???"); ok {
+		t.Fatal("expected invalid tag")
+	}
+}
@@ -0,0 +1,194 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+// MaxPixels guards against decompression bombs: a tiny file can declare
+// a huge canvas.
+const MaxPixels = 40_000_000
+
+type Encoded struct {
+	Data        []byte
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+}
+
+// Load decodes a JPEG, PNG or GIF and applies the EXIF orientation so the
+// pixels are upright once the metadata is dropped.
+func Load(data []byte) (image.Image, string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("read image header: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, "", fmt.Errorf("image is %dx%d, too large", cfg.Width, cfg.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("decode image: %w", err)
+	}
+	if format == "jpeg" {
+		img = Orient(img, Orientation(data))
+	}
+	return img, format, nil
+}
+
+// Encode writes JPEG sources back as JPEG and everything else as PNG. Only
+// pixels are written, so EXIF and other metadata do not survive.
+func Encode(img image.Image, format string) (Encoded, error) {
+	var buf bytes.Buffer
+	out := Encoded{Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}
+	if format == "jpeg" {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return out, fmt.Errorf("encode jpeg: %w", err)
+		}
+		out.ContentType, out.Ext = "image/jpeg", "jpg"
+	} else {
+		if err := png.Encode(&buf, img); err != nil {
+			return out, fmt.Errorf("encode png: %w", err)
+		}
+		out.ContentType, out.Ext = "image/png", "png"
+	}
+	out.Data = buf.Bytes()
+	return out, nil
+}
+
+// Fit scales img down so its longest side is at most maxSide, averaging the
+// source pixels under each destination pixel. It never upscales.
+func Fit(img image.Image, maxSide int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSide && h <= maxSide {
+		return img
+	}
+	dw, dh := maxSide, h*maxSide/w
+	if h > w {
+		dw, dh = w*maxSide/h, maxSide
+	}
+	dw, dh = max(dw, 1), max(dh, 1)
+
+	src := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r, g, bl, a = r+uint32(p[0]), g+uint32(p[1]), bl+uint32(p[2]), a+uint32(p[3])
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(bl / n), A: uint8(a / n)})
+		}
+	}
+	return dst
+}
+
+// Orient applies an EXIF orientation (1-8). Unknown values leave img as is.
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			dst.Set(x, y, img.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return dst
+}
+
+// Orientation reads the EXIF orientation tag of a JPEG, or 1 when absent.
+func Orientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		seg := data[i+4 : i+2+size]
+		if marker == 0xE1 && len(seg) > 6 && string(seg[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(seg[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for e := 0; e < count; e++ {
+		off := ifd + 2 + e*12
+		if off+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[off:]) == 0x0112 {
+			return int(order.Uint16(tiff[off+8:]))
+		}
+	}
+	return 1
+}
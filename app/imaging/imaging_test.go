@@ -0,0 +1,64 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+func jpegWithOrientation(t *testing.T, w, h int, orientation uint16) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01\x01\x12\x00\x03\x00\x00\x00\x01")
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	seg := []byte{0xFF, 0xE1}
+	seg = binary.BigEndian.AppendUint16(seg, uint16(len(payload)+2))
+	seg = append(seg, payload...)
+	raw := buf.Bytes()
+	return append(append(append([]byte{}, raw[:2]...), seg...), raw[2:]...)
+}
+
+func TestLoadAppliesOrientationAndEncodeDropsExif(t *testing.T) {
+	data := jpegWithOrientation(t, 40, 20, 6)
+	if got := Orientation(data); got != 6 {
+		t.Fatalf("orientation = %d, want 6", got)
+	}
+	img, format, err := Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "jpeg" || img.Bounds().Dx() != 20 || img.Bounds().Dy() != 40 {
+		t.Fatalf("got %s %v, want rotated jpeg 20x40", format, img.Bounds())
+	}
+	out, err := Encode(img, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out.Data, []byte("Exif")) {
+		t.Fatal("re-encoded image still carries EXIF")
+	}
+	if Orientation(out.Data) != 1 {
+		t.Fatal("re-encoded image still carries an orientation")
+	}
+}
+
+func TestFitKeepsAspectAndNeverUpscales(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 100))
+	got := Fit(img, 100).Bounds()
+	if got.Dx() != 100 || got.Dy() != 25 {
+		t.Fatalf("got %v, want 100x25", got)
+	}
+	if Fit(img, 1000) != image.Image(img) {
+		t.Fatal("small image should be returned as is")
+	}
+}
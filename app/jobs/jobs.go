@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Start runs every job on its own ticker until ctx is cancelled. A tick may
+// not outlive its interval, so a stuck database call cannot pile up runs.
+func Start(ctx context.Context, jobs ...Job) {
+	for _, job := range jobs {
+		if job.Interval <= 0 {
+			log.Warn().Str("job", job.Name).Msg("job disabled: non-positive interval")
+			continue
+		}
+		go loop(ctx, job)
+	}
+}
+
+func loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		tick(ctx, job)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func tick(ctx context.Context, job Job) {
+	tctx, cancel := context.WithTimeout(ctx, job.Interval)
+	defer cancel()
+	if err := job.Run(tctx); err != nil {
+		log.Error().Err(err).Str("job", job.Name).Msg("job failed")
+	}
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+type RunStateCounts struct {
+	Started   int64 `json:"started"`
+	Active    int64 `json:"active"`
+	Completed int64 `json:"completed"`
+	Abandoned int64 `json:"abandoned"`
+}
+
+type StepFunnel struct {
+	StepID        string  `json:"stepId"`
+	Order         int     `json:"order"`
+	Name          string  `json:"name"`
+	Reached       int64   `json:"reached"`
+	Killed        int64   `json:"killed"`
+	DropOff       int64   `json:"dropOff"`
+	DropOffRate   float64 `json:"dropOffRate"`
+	MedianSeconds float64 `json:"medianSecondsFromPrevious"`
+}
+
+type Payout struct {
+	Gold  int64        `json:"gold"`
+	Items []RewardItem `json:"items"`
+}
+
+type DungeonAnalytics struct {
+	DungeonID string         `json:"dungeonId"`
+	From      *time.Time     `json:"from,omitempty"`
+	To        *time.Time     `json:"to,omitempty"`
+	Runs      RunStateCounts `json:"runs"`
+	Steps     []StepFunnel   `json:"steps"`
+	Payout    Payout         `json:"payout"`
+}
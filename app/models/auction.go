@@ -0,0 +1,180 @@
+package models
+
+import "time"
+
+// Auction is a bidding sale. The item is escrowed from the seller when it
+// opens, and the leading bid is escrowed from the bidder's gold until they
+// are outbid or the auction settles. Status reuses the listing states.
+type Auction struct {
+	ID           string        `bson:"_id" json:"id"`
+	SellerID     string        `bson:"sellerId" json:"sellerId"`
+	ItemID       string        `bson:"itemId" json:"itemId"`
+	Qty          int64         `bson:"qty" json:"qty"`
+	StartPrice   int64         `bson:"startPrice" json:"startPrice"`
+	MinIncrement int64         `bson:"minIncrement" json:"minIncrement"`
+	BuyoutPrice  int64         `bson:"buyoutPrice,omitempty" json:"buyoutPrice,omitempty"`
+	Deposit      int64         `bson:"deposit" json:"deposit"`
+	HighBid      int64         `bson:"highBid" json:"highBid"`
+	HighBidderID string        `bson:"highBidderId,omitempty" json:"highBidderId,omitempty"`
+	BidCount     int           `bson:"bidCount" json:"bidCount"`
+	BidderIDs    []string      `bson:"bidderIds" json:"-"`
+	Status       ListingStatus `bson:"status" json:"status"`
+	EndsAt       time.Time     `bson:"endsAt" json:"endsAt"`
+	CreatedAt    time.Time     `bson:"createdAt" json:"createdAt"`
+	SettledAt    *time.Time    `bson:"settledAt,omitempty" json:"settledAt,omitempty"`
+}
+
+// MinimumBid is the lowest amount the next bid may offer.
+func (a Auction) MinimumBid() int64 {
+	if a.HighBidderID == "" {
+		return a.StartPrice
+	}
+	return a.HighBid + a.MinIncrement
+}
+
+type Bid struct {
+	ID        string    `bson:"_id" json:"id"`
+	AuctionID string    `bson:"auctionId" json:"auctionId"`
+	BidderID  string    `bson:"bidderId" json:"bidderId"`
+	Amount    int64     `bson:"amount" json:"amount"`
+	Buyout    bool      `bson:"buyout,omitempty" json:"buyout,omitempty"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+type MyBid struct {
+	Auction Auction `json:"auction"`
+	Leading bool    `json:"leading"`
+}
+
+type CreateAuctionRequest struct {
+	ItemID       string `json:"itemId" validate:"required,min=1,max=64"`
+	Qty          int64  `json:"qty" validate:"required,min=1"`
+	StartPrice   int64  `json:"startPrice" validate:"required,min=1"`
+	MinIncrement int64  `json:"minIncrement" validate:"required,min=1"`
+	BuyoutPrice  int64  `json:"buyoutPrice" validate:"omitempty,gtfield=StartPrice"`
+	DurationH    int64  `json:"durationHours" validate:"required,min=1,max=168"`
+}
+
+type BidRequest struct {
+	Amount int64 `json:"amount" validate:"required,min=1"`
+}
+
+type BuyOrderStatus string
+
+const (
+	BuyOrderStatusOpen      BuyOrderStatus = "open"
+	BuyOrderStatusFilled    BuyOrderStatus = "filled"
+	BuyOrderStatusCancelled BuyOrderStatus = "cancelled"
+)
+
+// BuyOrder is a standing bid for Qty units at up to MaxUnitPrice each. The
+// buyer's gold for the unfilled part, Remaining*MaxUnitPrice, stays
+// escrowed until the order fills or is cancelled.
+type BuyOrder struct {
+	ID           string         `bson:"_id" json:"id"`
+	BuyerID      string         `bson:"buyerId" json:"buyerId"`
+	ItemID       string         `bson:"itemId" json:"itemId"`
+	Qty          int64          `bson:"qty" json:"qty"`
+	Remaining    int64          `bson:"remaining" json:"remaining"`
+	MaxUnitPrice int64          `bson:"maxUnitPrice" json:"maxUnitPrice"`
+	Status       BuyOrderStatus `bson:"status" json:"status"`
+	CreatedAt    time.Time      `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time      `bson:"updatedAt" json:"updatedAt"`
+}
+
+type CreateBuyOrderRequest struct {
+	ItemID       string `json:"itemId" validate:"required,min=1,max=64"`
+	Qty          int64  `json:"qty" validate:"required,min=1,max=1000000"`
+	MaxUnitPrice int64  `json:"maxUnitPrice" validate:"required,min=1,max=1000000000"`
+}
+
+type BookLevel struct {
+	PricePerUnit int64 `bson:"_id" json:"pricePerUnit"`
+	Qty          int64 `bson:"qty" json:"qty"`
+	Orders       int   `bson:"orders" json:"orders"`
+}
+
+// OrderBook lists buy orders (best price first) and sell listings
+// (cheapest first) for one item, aggregated by price.
+type OrderBook struct {
+	ItemID string      `json:"itemId"`
+	Bids   []BookLevel `json:"bids"`
+	Asks   []BookLevel `json:"asks"`
+}
+
+// FeeRule takes Percent of an amount, raised to Min and capped at Max when
+// Max is set. The fee never exceeds the amount itself.
+type FeeRule struct {
+	Percent float64 `json:"percent"`
+	Min     int64   `json:"min"`
+	Max     int64   `json:"max"`
+}
+
+func (r FeeRule) Apply(amount int64) int64 {
+	fee := int64(float64(amount) * r.Percent / 100)
+	fee = max(fee, r.Min)
+	if r.Max > 0 {
+		fee = min(fee, r.Max)
+	}
+	return max(min(fee, amount), 0)
+}
+
+type RarityFees struct {
+	Deposit    FeeRule `json:"deposit"`
+	Commission FeeRule `json:"commission"`
+}
+
+// FeeSchedule maps an item rarity to its fees. The "default" entry covers
+// rarities without their own.
+type FeeSchedule map[string]RarityFees
+
+func (f FeeSchedule) For(rarity string) RarityFees {
+	if fees, ok := f[rarity]; ok {
+		return fees
+	}
+	return f["default"]
+}
+
+// Treasury collects the gold the auction house takes out of circulation.
+type Treasury struct {
+	ID          string    `bson:"_id" json:"id"`
+	Deposits    int64     `bson:"deposits" json:"deposits"`
+	Commissions int64     `bson:"commissions" json:"commissions"`
+	Total       int64     `bson:"total" json:"total"`
+	UpdatedAt   time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// PriceBucket holds the unit prices traded in one interval starting at
+// Start. Value is the gold that changed hands.
+type PriceBucket struct {
+	Start  time.Time `bson:"_id" json:"start"`
+	Open   float64   `bson:"open" json:"open"`
+	High   float64   `bson:"high" json:"high"`
+	Low    float64   `bson:"low" json:"low"`
+	Close  float64   `bson:"close" json:"close"`
+	Volume int64     `bson:"volume" json:"volume"`
+	Value  int64     `bson:"value" json:"value"`
+	Trades int       `bson:"trades" json:"trades"`
+}
+
+type LastSale struct {
+	UnitPrice float64   `json:"unitPrice"`
+	Qty       int64     `json:"qty"`
+	At        time.Time `json:"at"`
+}
+
+// MarketSummary is the volume-weighted average unit price and the volume
+// traded over a window.
+type MarketSummary struct {
+	AvgUnitPrice float64 `bson:"avgUnitPrice" json:"avgUnitPrice"`
+	Volume       int64   `bson:"volume" json:"volume"`
+	Trades       int     `bson:"trades" json:"trades"`
+}
+
+type ItemMarket struct {
+	ItemID   string        `json:"itemId"`
+	Interval string        `json:"interval"`
+	Buckets  []PriceBucket `json:"buckets"`
+	LastSale *LastSale     `json:"lastSale,omitempty"`
+	Last7d   MarketSummary `json:"last7d"`
+}
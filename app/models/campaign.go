@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+type CampaignStatus string
+
+const (
+	CampaignStatusDraft     CampaignStatus = "draft"
+	CampaignStatusPublished CampaignStatus = "published"
+)
+
+type UnlockKind string
+
+const (
+	UnlockCompletePrevious UnlockKind = "complete_previous"
+	UnlockCompleteDungeon  UnlockKind = "complete_dungeon"
+	UnlockHoldItem         UnlockKind = "hold_item"
+)
+
+type UnlockRule struct {
+	Kind      UnlockKind `bson:"kind" json:"kind" validate:"required,oneof=complete_previous complete_dungeon hold_item"`
+	DungeonID string     `bson:"dungeonId,omitempty" json:"dungeonId,omitempty" validate:"required_if=Kind complete_dungeon,max=64"`
+	ItemID    string     `bson:"itemId,omitempty" json:"itemId,omitempty" validate:"required_if=Kind hold_item,max=64"`
+	Qty       int64      `bson:"qty,omitempty" json:"qty,omitempty" validate:"min=0"`
+}
+
+// CampaignEntry is unlocked when any of its rules holds. An entry without
+// rules is open from the start.
+type CampaignEntry struct {
+	DungeonID string       `bson:"dungeonId" json:"dungeonId"`
+	Unlock    []UnlockRule `bson:"unlock" json:"unlock"`
+}
+
+type Campaign struct {
+	ID          string          `bson:"_id" json:"id"`
+	Title       string          `bson:"title" json:"title"`
+	Description string          `bson:"description" json:"description"`
+	CreatedBy   string          `bson:"createdBy" json:"createdBy"`
+	Status      CampaignStatus  `bson:"status" json:"status"`
+	Entries     []CampaignEntry `bson:"entries" json:"entries"`
+	Rewards     Rewards         `bson:"rewards" json:"rewards"`
+	CreatedAt   time.Time       `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time       `bson:"updatedAt" json:"updatedAt"`
+}
+
+// CampaignEntryRequest leaves Unlock nil to mean "complete the previous
+// dungeon"; send an empty list for an entry open from the start.
+type CampaignEntryRequest struct {
+	DungeonID string       `json:"dungeonId" validate:"required,min=1,max=64"`
+	Unlock    []UnlockRule `json:"unlock" validate:"omitempty,max=5,dive"`
+}
+
+type CampaignRequest struct {
+	Title       string                 `json:"title" validate:"required,min=3,max=120"`
+	Description string                 `json:"description" validate:"required,min=3,max=1024"`
+	Entries     []CampaignEntryRequest `json:"entries" validate:"required,min=1,max=30,dive"`
+	Rewards     Rewards                `json:"rewards"`
+}
+
+type CampaignCompletion struct {
+	ID          string    `bson:"_id" json:"id"`
+	CampaignID  string    `bson:"campaignId" json:"campaignId"`
+	PlayerID    string    `bson:"playerId" json:"playerId"`
+	Rewards     Rewards   `bson:"rewards" json:"rewards"`
+	CompletedAt time.Time `bson:"completedAt" json:"completedAt"`
+}
+
+type CampaignEntryProgress struct {
+	Order     int          `json:"order"`
+	DungeonID string       `json:"dungeonId"`
+	Title     string       `json:"title"`
+	Unlock    []UnlockRule `json:"unlock"`
+	Unlocked  bool         `json:"unlocked"`
+	Completed bool         `json:"completed"`
+}
+
+type CampaignProgress struct {
+	Campaign   Campaign                `json:"campaign"`
+	Entries    []CampaignEntryProgress `json:"entries"`
+	Completed  int                     `json:"completed"`
+	Total      int                     `json:"total"`
+	Completion *CampaignCompletion     `json:"completion,omitempty"`
+}
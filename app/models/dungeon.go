@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 type DungeonStatus string
 
@@ -10,15 +13,175 @@ const (
 	DungeonStatusArchived  DungeonStatus = "archived"
 )
 
+type CollaboratorRole string
+
+const (
+	CollaboratorOwner  CollaboratorRole = "owner"
+	CollaboratorEditor CollaboratorRole = "editor"
+	CollaboratorViewer CollaboratorRole = "viewer"
+)
+
+var collaboratorRank = map[CollaboratorRole]int{
+	CollaboratorViewer: 1,
+	CollaboratorEditor: 2,
+	CollaboratorOwner:  3,
+}
+
+// Allows reports whether r grants at least the access level of need.
+func (r CollaboratorRole) Allows(need CollaboratorRole) bool {
+	return collaboratorRank[r] > 0 && collaboratorRank[r] >= collaboratorRank[need]
+}
+
+type Collaborator struct {
+	PlayerID string           `bson:"playerId" json:"playerId"`
+	Role     CollaboratorRole `bson:"role" json:"role"`
+	AddedAt  time.Time        `bson:"addedAt" json:"addedAt"`
+}
+
+// DungeonStats is derived from the boss steps and refreshed whenever they change.
+type DungeonStats struct {
+	StepCount       int     `bson:"stepCount" json:"stepCount"`
+	AvgDifficulty   float64 `bson:"avgDifficulty" json:"avgDifficulty"`
+	TotalRewardGold int64   `bson:"totalRewardGold" json:"totalRewardGold"`
+}
+
+type DungeonTranslation struct {
+	Title       string `bson:"title" json:"title" validate:"required,min=3,max=120"`
+	Description string `bson:"description" json:"description" validate:"required,min=3,max=1024"`
+}
+
+type StepTranslation struct {
+	Name            string `bson:"name" json:"name" validate:"required,min=2,max=120"`
+	ZoneDescription string `bson:"zoneDescription" json:"zoneDescription" validate:"required,min=2,max=512"`
+	Question        string `bson:"question,omitempty" json:"question,omitempty" validate:"omitempty,min=2,max=512"`
+}
+
+type DungeonRating struct {
+	Average float64 `bson:"average" json:"average"`
+	Count   int64   `bson:"count" json:"count"`
+}
+
+// CreatedBy holds the current owner; it moves on ownership transfer.
+// Title and Description are written in DefaultLang; Translations holds the
+// other languages keyed by BCP 47 tag.
 type Dungeon struct {
-	ID          string        `bson:"_id" json:"id"`
-	Title       string        `bson:"title" json:"title"`
-	Description string        `bson:"description" json:"description"`
-	CreatedBy   string        `bson:"createdBy" json:"createdBy"`
-	AreaName    string        `bson:"areaName" json:"areaName"`
-	Status      DungeonStatus `bson:"status" json:"status"`
-	CreatedAt   time.Time     `bson:"createdAt" json:"createdAt"`
-	UpdatedAt   time.Time     `bson:"updatedAt" json:"updatedAt"`
+	ID            string                        `bson:"_id" json:"id"`
+	Title         string                        `bson:"title" json:"title"`
+	Description   string                        `bson:"description" json:"description"`
+	DefaultLang   string                        `bson:"defaultLang,omitempty" json:"defaultLang,omitempty"`
+	Translations  map[string]DungeonTranslation `bson:"translations,omitempty" json:"translations,omitempty"`
+	Lang          string                        `bson:"-" json:"lang,omitempty"`
+	CreatedBy     string                        `bson:"createdBy" json:"createdBy"`
+	Collaborators []Collaborator                `bson:"collaborators,omitempty" json:"collaborators,omitempty"`
+	AreaName      string                        `bson:"areaName" json:"areaName"`
+	Cover         *Image                        `bson:"cover,omitempty" json:"cover,omitempty"`
+	Status        DungeonStatus                 `bson:"status" json:"status"`
+	Stats         DungeonStats                  `bson:"stats" json:"stats"`
+	Rating        DungeonRating                 `bson:"rating" json:"rating"`
+	IsTemplate    bool                          `bson:"isTemplate" json:"isTemplate"`
+	ClonedFrom    string                        `bson:"clonedFrom,omitempty" json:"clonedFrom,omitempty"`
+	PublishAt     *time.Time                    `bson:"publishAt,omitempty" json:"publishAt,omitempty"`
+	UnpublishAt   *time.Time                    `bson:"unpublishAt,omitempty" json:"unpublishAt,omitempty"`
+	ScheduleError string                        `bson:"scheduleError,omitempty" json:"scheduleError,omitempty"`
+	CreatedAt     time.Time                     `bson:"createdAt" json:"createdAt"`
+	UpdatedAt     time.Time                     `bson:"updatedAt" json:"updatedAt"`
+}
+
+// IsLive reports whether players may start runs: published and the event
+// window, if any, has not ended yet.
+func (d Dungeon) IsLive(now time.Time) bool {
+	if d.Status != DungeonStatusPublished {
+		return false
+	}
+	return d.UnpublishAt == nil || now.Before(*d.UnpublishAt)
+}
+
+// Languages lists the default language first, then the translations.
+func (d Dungeon) Languages() []string {
+	out := []string{d.DefaultLang}
+	for lang := range d.Translations {
+		out = append(out, lang)
+	}
+	sort.Strings(out[1:])
+	return out
+}
+
+// Localized returns the dungeon with its text in lang and the translation
+// map dropped. An unknown lang keeps the default text.
+func (d Dungeon) Localized(lang string) Dungeon {
+	d.Lang = d.DefaultLang
+	if t, ok := d.Translations[lang]; ok {
+		d.Title, d.Description, d.Lang = t.Title, t.Description, lang
+	}
+	d.Translations = nil
+	return d
+}
+
+func (s BossStep) Localized(lang, defaultLang string) BossStep {
+	s.Lang = defaultLang
+	if t, ok := s.Translations[lang]; ok {
+		s.Name, s.ZoneDescription, s.Lang = t.Name, t.ZoneDescription, lang
+		if s.Riddle != nil && t.Question != "" {
+			riddle := *s.Riddle
+			riddle.Question = t.Question
+			s.Riddle = &riddle
+		}
+	}
+	s.Translations = nil
+	return s
+}
+
+// Kind defaults steps created before step types existed to location.
+func (s BossStep) Kind() StepType {
+	if s.Type == "" {
+		return StepTypeLocation
+	}
+	return s.Type
+}
+
+// Proofs reports which proofs of presence the step requires. Steps without
+// a policy only need GPS.
+func (s BossStep) Proofs() (gps, code bool) {
+	if s.Proof == nil {
+		return true, false
+	}
+	switch s.Proof.Require {
+	case ProofCode:
+		return false, true
+	case ProofBoth:
+		return true, true
+	default:
+		return true, false
+	}
+}
+
+// PlayerView hides hint texts.
+func (s BossStep) PlayerView() BossStep {
+	if s.Riddle == nil {
+		return s
+	}
+	riddle := *s.Riddle
+	riddle.Hints = make([]RiddleHint, 0, len(s.Riddle.Hints))
+	for _, h := range s.Riddle.Hints {
+		riddle.Hints = append(riddle.Hints, RiddleHint{Cost: h.Cost})
+	}
+	s.Riddle = &riddle
+	return s
+}
+
+func (d Dungeon) RoleOf(playerID string) CollaboratorRole {
+	if playerID == "" {
+		return ""
+	}
+	if d.CreatedBy == playerID {
+		return CollaboratorOwner
+	}
+	for _, c := range d.Collaborators {
+		if c.PlayerID == playerID {
+			return c.Role
+		}
+	}
+	return ""
 }
 
 type BossLocation struct {
@@ -37,23 +200,78 @@ type Rewards struct {
 	Items []RewardItem `bson:"items" json:"items"`
 }
 
+type StepType string
+
+const (
+	StepTypeLocation StepType = "location"
+	StepTypeRiddle   StepType = "riddle"
+	StepTypePhoto    StepType = "photo"
+)
+
+// Answers are stored as bcrypt hashes of their normalized form and never
+// serialized; hint texts are stripped from player views until bought.
+type Riddle struct {
+	Question     string       `bson:"question" json:"question"`
+	AnswerHashes []string     `bson:"answerHashes" json:"-"`
+	MaxTries     int          `bson:"maxTries" json:"maxTries"`
+	Hints        []RiddleHint `bson:"hints" json:"hints"`
+}
+
+type RiddleHint struct {
+	Text string `bson:"text" json:"text,omitempty" validate:"required,min=2,max=512"`
+	Cost int64  `bson:"cost" json:"cost" validate:"min=0"`
+}
+
+type ProofRequirement string
+
+const (
+	ProofGPS  ProofRequirement = "gps"
+	ProofCode ProofRequirement = "code"
+	ProofBoth ProofRequirement = "both"
+)
+
+type CodeMode string
+
+const (
+	CodeModeStatic CodeMode = "static"
+	CodeModeTOTP   CodeMode = "totp"
+)
+
+// ProofPolicy says what a player must present at the boss. Codes derive from
+// Secret, so rotating it invalidates every printed tag of the step.
+type ProofPolicy struct {
+	Require       ProofRequirement `bson:"require" json:"require"`
+	Mode          CodeMode         `bson:"mode,omitempty" json:"mode,omitempty"`
+	PeriodSeconds int              `bson:"periodSeconds,omitempty" json:"periodSeconds,omitempty"`
+	Secret        string           `bson:"secret,omitempty" json:"-"`
+	Version       int              `bson:"version" json:"version"`
+	RotatedAt     *time.Time       `bson:"rotatedAt,omitempty" json:"rotatedAt,omitempty"`
+}
+
 type BossStep struct {
-	ID              string       `bson:"_id" json:"id"`
-	DungeonID       string       `bson:"dungeonId" json:"dungeonId"`
-	Order           int          `bson:"order" json:"order"`
-	Name            string       `bson:"name" json:"name"`
-	Location        BossLocation `bson:"location" json:"location"`
-	ZoneDescription string       `bson:"zoneDescription" json:"zoneDescription"`
-	Difficulty      int          `bson:"difficulty" json:"difficulty"`
-	Rewards         Rewards      `bson:"rewards" json:"rewards"`
-	CreatedAt       time.Time    `bson:"createdAt" json:"createdAt"`
-	UpdatedAt       time.Time    `bson:"updatedAt" json:"updatedAt"`
+	ID              string                     `bson:"_id" json:"id"`
+	DungeonID       string                     `bson:"dungeonId" json:"dungeonId"`
+	Order           int                        `bson:"order" json:"order"`
+	Type            StepType                   `bson:"type,omitempty" json:"type,omitempty"`
+	Riddle          *Riddle                    `bson:"riddle,omitempty" json:"riddle,omitempty"`
+	Proof           *ProofPolicy               `bson:"proof,omitempty" json:"proof,omitempty"`
+	Name            string                     `bson:"name" json:"name"`
+	Location        BossLocation               `bson:"location" json:"location"`
+	ZoneDescription string                     `bson:"zoneDescription" json:"zoneDescription"`
+	Art             *Image                     `bson:"art,omitempty" json:"art,omitempty"`
+	Translations    map[string]StepTranslation `bson:"translations,omitempty" json:"translations,omitempty"`
+	Lang            string                     `bson:"-" json:"lang,omitempty"`
+	Difficulty      int                        `bson:"difficulty" json:"difficulty"`
+	Rewards         Rewards                    `bson:"rewards" json:"rewards"`
+	CreatedAt       time.Time                  `bson:"createdAt" json:"createdAt"`
+	UpdatedAt       time.Time                  `bson:"updatedAt" json:"updatedAt"`
 }
 
 type CreateDungeonRequest struct {
 	Title       string `json:"title" validate:"required,min=3,max=120"`
 	Description string `json:"description" validate:"required,min=3,max=1024"`
 	AreaName    string `json:"areaName" validate:"required,min=2,max=120"`
+	DefaultLang string `json:"defaultLang" validate:"omitempty,bcp47_language_tag"`
 }
 
 type UpdateDungeonRequest struct {
@@ -61,25 +279,152 @@ type UpdateDungeonRequest struct {
 	Description string `json:"description" validate:"required,min=3,max=1024"`
 	AreaName    string `json:"areaName" validate:"required,min=2,max=120"`
 	Status      string `json:"status" validate:"omitempty,oneof=draft published archived"`
+	DefaultLang string `json:"defaultLang" validate:"omitempty,bcp47_language_tag"`
+}
+
+type RiddleRequest struct {
+	Question string       `json:"question" validate:"required,min=2,max=512"`
+	Answers  []string     `json:"answers" validate:"required,min=1,max=20,dive,required,max=200"`
+	MaxTries int          `json:"maxTries" validate:"min=0,max=100"`
+	Hints    []RiddleHint `json:"hints" validate:"max=10,dive"`
 }
 
 type CreateBossStepRequest struct {
-	Order           int          `json:"order" validate:"required,min=1"`
-	Name            string       `json:"name" validate:"required,min=2,max=120"`
-	Location        BossLocation `json:"location" validate:"required"`
-	ZoneDescription string       `json:"zoneDescription" validate:"required,min=2,max=512"`
-	Difficulty      int          `json:"difficulty" validate:"required,min=1,max=10"`
-	Rewards         Rewards      `json:"rewards" validate:"required"`
+	Order           int            `json:"order" validate:"required,min=1"`
+	Type            StepType       `json:"type" validate:"omitempty,oneof=location riddle photo"`
+	Riddle          *RiddleRequest `json:"riddle" validate:"required_if=Type riddle,omitempty"`
+	Name            string         `json:"name" validate:"required,min=2,max=120"`
+	Location        BossLocation   `json:"location" validate:"required"`
+	ZoneDescription string         `json:"zoneDescription" validate:"required,min=2,max=512"`
+	Difficulty      int            `json:"difficulty" validate:"required,min=1,max=10"`
+	Rewards         Rewards        `json:"rewards" validate:"required"`
 }
 
 type UpdateBossStepRequest struct {
-	Name            string       `json:"name" validate:"required,min=2,max=120"`
-	Location        BossLocation `json:"location" validate:"required"`
-	ZoneDescription string       `json:"zoneDescription" validate:"required,min=2,max=512"`
-	Difficulty      int          `json:"difficulty" validate:"required,min=1,max=10"`
-	Rewards         Rewards      `json:"rewards" validate:"required"`
+	Type            StepType       `json:"type" validate:"omitempty,oneof=location riddle photo"`
+	Riddle          *RiddleRequest `json:"riddle" validate:"required_if=Type riddle,omitempty"`
+	Name            string         `json:"name" validate:"required,min=2,max=120"`
+	Location        BossLocation   `json:"location" validate:"required"`
+	ZoneDescription string         `json:"zoneDescription" validate:"required,min=2,max=512"`
+	Difficulty      int            `json:"difficulty" validate:"required,min=1,max=10"`
+	Rewards         Rewards        `json:"rewards" validate:"required"`
+}
+
+type ProofPolicyRequest struct {
+	Require       ProofRequirement `json:"require" validate:"required,oneof=gps code both"`
+	Mode          CodeMode         `json:"mode" validate:"omitempty,oneof=static totp"`
+	PeriodSeconds int              `json:"periodSeconds" validate:"omitempty,min=15,max=3600"`
+}
+
+type CodeSheetEntry struct {
+	StepID  string           `json:"stepId"`
+	Order   int              `json:"order"`
+	Name    string           `json:"name"`
+	Require ProofRequirement `json:"require"`
+	Mode    CodeMode         `json:"mode"`
+	Version int              `json:"version"`
+	Code    string           `json:"code,omitempty"`
+	Payload string           `json:"payload,omitempty"`
+	KeyURI  string           `json:"keyUri,omitempty"`
 }
 
 type ReorderBossStepsRequest struct {
 	StepIDs []string `json:"stepIds" validate:"required,min=1,dive,required"`
 }
+
+type MissingStepTranslation struct {
+	StepID string `json:"stepId"`
+	Order  int    `json:"order"`
+	Name   string `json:"name"`
+}
+
+type TranslationStatus struct {
+	Lang         string                   `json:"lang"`
+	Dungeon      bool                     `json:"dungeon"`
+	MissingSteps []MissingStepTranslation `json:"missingSteps"`
+	Complete     bool                     `json:"complete"`
+}
+
+type TranslationReport struct {
+	DefaultLang string              `json:"defaultLang"`
+	Languages   []TranslationStatus `json:"languages"`
+}
+
+type GeoPoint struct {
+	Lat float64 `json:"lat" validate:"latitude"`
+	Lon float64 `json:"lon" validate:"longitude"`
+}
+
+type CloneDungeonRequest struct {
+	Title    string    `json:"title" validate:"omitempty,min=3,max=120"`
+	AreaName string    `json:"areaName" validate:"omitempty,min=2,max=120"`
+	Anchor   *GeoPoint `json:"anchor" validate:"omitempty"`
+}
+
+// A nil timestamp clears that side of the schedule.
+type ScheduleDungeonRequest struct {
+	PublishAt   *time.Time `json:"publishAt"`
+	UnpublishAt *time.Time `json:"unpublishAt"`
+}
+
+type UpcomingDungeon struct {
+	Dungeon
+	StartsInSeconds int64 `json:"startsInSeconds"`
+}
+
+// RoutePlan measures straight lines between step centres, so real walks are
+// longer. SuggestedOrder is only a hint for players free to pick the order.
+type RoutePlan struct {
+	TotalMeters      float64    `json:"totalMeters"`
+	Legs             []RouteLeg `json:"legs"`
+	EstimatedMinutes int        `json:"estimatedMinutes"`
+	SuggestedOrder   []string   `json:"suggestedOrder"`
+	SuggestedMeters  float64    `json:"suggestedMeters"`
+	Warnings         []string   `json:"warnings,omitempty"`
+}
+
+type RouteLeg struct {
+	FromStepID string  `json:"fromStepId"`
+	ToStepID   string  `json:"toStepId"`
+	Meters     float64 `json:"meters"`
+}
+
+type AddCollaboratorRequest struct {
+	PlayerID string           `json:"playerId" validate:"required,min=1,max=64"`
+	Role     CollaboratorRole `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+type TransferOwnershipRequest struct {
+	PlayerID string `json:"playerId" validate:"required,min=1,max=64"`
+}
+
+type DungeonSearchQuery struct {
+	Q             string   `form:"q" validate:"omitempty,max=200"`
+	Area          string   `form:"area" validate:"omitempty,max=120"`
+	MinDifficulty *float64 `form:"minDifficulty" validate:"omitempty,min=1,max=10"`
+	MaxDifficulty *float64 `form:"maxDifficulty" validate:"omitempty,min=1,max=10"`
+	Sort          string   `form:"sort" validate:"omitempty,oneof=relevance createdAt -createdAt difficulty -difficulty reward -reward steps -steps rating -rating"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type DungeonFacets struct {
+	Areas      []FacetCount `json:"areas"`
+	Difficulty []FacetCount `json:"difficulty"`
+}
+
+type DungeonSearchResult struct {
+	Dungeons []Dungeon
+	Total    int64
+	Facets   DungeonFacets
+}
+
+type DungeonSearchResponse struct {
+	Data       []Dungeon     `json:"data"`
+	Pagination Pagination    `json:"pagination"`
+	Total      int64         `json:"total"`
+	Facets     DungeonFacets `json:"facets"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// GeoJSON (RFC 7946). Positions are [lon, lat].
+
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string         `json:"type"`
+	ID         string         `json:"id,omitempty"`
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+func NewFeatureCollection(features []Feature) FeatureCollection {
+	if features == nil {
+		features = make([]Feature, 0)
+	}
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+func PointFeature(id string, lat, lon float64, props map[string]any) Feature {
+	return Feature{Type: "Feature", ID: id, Geometry: Geometry{Type: "Point", Coordinates: [2]float64{lon, lat}}, Properties: props}
+}
+
+// GPX 1.1.
+
+type GPX struct {
+	XMLName   xml.Name      `xml:"http://www.topografix.com/GPX/1/1 gpx"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Metadata  GPXMetadata   `xml:"metadata"`
+	Waypoints []GPXWaypoint `xml:"wpt"`
+	Tracks    []GPXTrack    `xml:"trk"`
+}
+
+type GPXMetadata struct {
+	Name string     `xml:"name,omitempty"`
+	Time *time.Time `xml:"time,omitempty"`
+}
+
+type GPXWaypoint struct {
+	Lat  float64    `xml:"lat,attr"`
+	Lon  float64    `xml:"lon,attr"`
+	Time *time.Time `xml:"time,omitempty"`
+	Name string     `xml:"name,omitempty"`
+}
+
+type GPXTrack struct {
+	Name     string       `xml:"name,omitempty"`
+	Segments []GPXSegment `xml:"trkseg"`
+}
+
+type GPXSegment struct {
+	Points []GPXWaypoint `xml:"trkpt"`
+}
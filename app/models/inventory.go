@@ -49,9 +49,11 @@ type Listing struct {
 	ItemID       string        `bson:"itemId" json:"itemId"`
 	Qty          int64         `bson:"qty" json:"qty"`
 	PricePerUnit int64         `bson:"pricePerUnit" json:"pricePerUnit"`
+	Deposit      int64         `bson:"deposit" json:"deposit"`
 	Status       ListingStatus `bson:"status" json:"status"`
 	CreatedAt    time.Time     `bson:"createdAt" json:"createdAt"`
 	ExpiresAt    *time.Time    `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	PriceWarning string        `bson:"-" json:"priceWarning,omitempty"`
 }
 
 type Trade struct {
@@ -59,16 +61,40 @@ type Trade struct {
 	BuyerID    string    `bson:"buyerId" json:"buyerId"`
 	SellerID   string    `bson:"sellerId" json:"sellerId"`
 	ListingID  string    `bson:"listingId" json:"listingId"`
+	OrderID    string    `bson:"orderId,omitempty" json:"orderId,omitempty"`
 	ItemID     string    `bson:"itemId" json:"itemId"`
 	Qty        int64     `bson:"qty" json:"qty"`
 	TotalPrice int64     `bson:"totalPrice" json:"totalPrice"`
+	Fee        int64     `bson:"fee" json:"fee"`
 	CreatedAt  time.Time `bson:"createdAt" json:"createdAt"`
 }
 
+type ListingSearchQuery struct {
+	ItemID   string `form:"itemId" validate:"omitempty,max=64"`
+	Type     string `form:"type" validate:"omitempty,max=64"`
+	Rarity   string `form:"rarity" validate:"omitempty,max=64"`
+	SellerID string `form:"sellerId" validate:"omitempty,max=64"`
+	MinPrice *int64 `form:"minPrice" validate:"omitempty,min=0"`
+	MaxPrice *int64 `form:"maxPrice" validate:"omitempty,min=0"`
+	Sort     string `form:"sort" validate:"omitempty,oneof=createdAt -createdAt price -price total -total endingSoon"`
+}
+
+type TradeSide string
+
+const (
+	TradeSideBuy  TradeSide = "buy"
+	TradeSideSell TradeSide = "sell"
+)
+
+type MyTrade struct {
+	Trade Trade     `json:"trade"`
+	Side  TradeSide `json:"side"`
+}
+
 type CreateListingRequest struct {
 	ItemID       string `json:"itemId" validate:"required,min=1,max=64"`
-	Qty          int64  `json:"qty" validate:"required,min=1"`
-	PricePerUnit int64  `json:"pricePerUnit" validate:"required,min=1"`
+	Qty          int64  `json:"qty" validate:"required,min=1,max=1000000"`
+	PricePerUnit int64  `json:"pricePerUnit" validate:"required,min=1,max=1000000000"`
 	ExpiresInH   int64  `json:"expiresInHours" validate:"omitempty,min=1,max=720"`
 }
 
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type LedgerReason string
+
+const (
+	LedgerReasonOpening        LedgerReason = "opening_balance"
+	LedgerReasonStepReward     LedgerReason = "step_reward"
+	LedgerReasonCampaignReward LedgerReason = "campaign_reward"
+	LedgerReasonHint           LedgerReason = "hint"
+	LedgerReasonTrade          LedgerReason = "trade"
+	LedgerReasonFee            LedgerReason = "fee"
+	LedgerReasonDeposit        LedgerReason = "deposit"
+	LedgerReasonEscrow         LedgerReason = "escrow"
+	LedgerReasonRefund         LedgerReason = "refund"
+	LedgerReasonAdminGrant     LedgerReason = "admin_grant"
+	LedgerReasonMail           LedgerReason = "mail"
+	LedgerReasonVendor         LedgerReason = "vendor"
+)
+
+// System accounts. The mint goes negative by the gold created for rewards
+// and grants; the sink, treasury and escrow hold gold taken from players.
+const (
+	AccountMint     = "system:mint"
+	AccountSink     = "system:sink"
+	AccountTreasury = "system:treasury"
+	AccountEscrow   = "system:escrow"
+)
+
+const playerAccountPrefix = "player:"
+
+func PlayerAccount(playerID string) string {
+	return playerAccountPrefix + playerID
+}
+
+// AccountPlayer returns the player behind a player account.
+func AccountPlayer(account string) (string, bool) {
+	return strings.CutPrefix(account, playerAccountPrefix)
+}
+
+// GoldTransfer moves Amount from one account to another. It is written as
+// two ledger entries that sum to zero.
+type GoldTransfer struct {
+	From   string
+	To     string
+	Amount int64
+	Reason LedgerReason
+	Ref    string
+	Memo   string
+}
+
+type LedgerEntry struct {
+	ID         string       `bson:"_id" json:"id"`
+	TransferID string       `bson:"transferId" json:"transferId"`
+	Account    string       `bson:"account" json:"account"`
+	Amount     int64        `bson:"amount" json:"amount"`
+	Reason     LedgerReason `bson:"reason" json:"reason"`
+	Ref        string       `bson:"ref,omitempty" json:"ref,omitempty"`
+	Memo       string       `bson:"memo,omitempty" json:"memo,omitempty"`
+	CreatedAt  time.Time    `bson:"createdAt" json:"createdAt"`
+}
+
+type GrantGoldRequest struct {
+	Amount int64  `json:"amount" validate:"required,ne=0"`
+	Memo   string `json:"memo" validate:"required,min=3,max=200"`
+}
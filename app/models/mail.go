@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+type MailStatus string
+
+const (
+	MailStatusSent     MailStatus = "sent"
+	MailStatusClaimed  MailStatus = "claimed"
+	MailStatusReturned MailStatus = "returned"
+	MailStatusExpired  MailStatus = "expired"
+)
+
+type MailItem struct {
+	ItemID string `bson:"itemId" json:"itemId" validate:"required,min=1,max=64"`
+	Qty    int64  `bson:"qty" json:"qty" validate:"required,min=1"`
+}
+
+// Mail carries attachments escrowed from the sender until the recipient
+// claims them. System mail has no sender and its attachments are created on
+// claim.
+type Mail struct {
+	ID        string     `bson:"_id" json:"id"`
+	FromID    string     `bson:"fromId,omitempty" json:"fromId,omitempty"`
+	System    bool       `bson:"system" json:"system"`
+	SentBy    string     `bson:"sentBy,omitempty" json:"-"`
+	ToID      string     `bson:"toId" json:"toId"`
+	Subject   string     `bson:"subject" json:"subject"`
+	Body      string     `bson:"body" json:"body"`
+	Items     []MailItem `bson:"items" json:"items"`
+	Gold      int64      `bson:"gold" json:"gold"`
+	Status    MailStatus `bson:"status" json:"status"`
+	ExpiresAt time.Time  `bson:"expiresAt" json:"expiresAt"`
+	CreatedAt time.Time  `bson:"createdAt" json:"createdAt"`
+	ReadAt    *time.Time `bson:"readAt,omitempty" json:"readAt,omitempty"`
+	ClosedAt  *time.Time `bson:"closedAt,omitempty" json:"closedAt,omitempty"`
+}
+
+func (m Mail) HasAttachments() bool {
+	return len(m.Items) > 0 || m.Gold > 0
+}
+
+type SendMailRequest struct {
+	ToID    string     `json:"toId" validate:"required,min=1,max=64"`
+	Subject string     `json:"subject" validate:"required,min=1,max=120"`
+	Body    string     `json:"body" validate:"max=2000"`
+	Items   []MailItem `json:"items" validate:"max=10,unique=ItemID,dive"`
+	Gold    int64      `json:"gold" validate:"min=0"`
+}
+
+// SystemMailRequest goes to ToIDs, or to every player when All is set.
+type SystemMailRequest struct {
+	ToIDs         []string   `json:"toIds" validate:"required_without=All,max=1000,unique,dive,min=1,max=64"`
+	All           bool       `json:"all"`
+	Subject       string     `json:"subject" validate:"required,min=1,max=120"`
+	Body          string     `json:"body" validate:"max=2000"`
+	Items         []MailItem `json:"items" validate:"max=10,unique=ItemID,dive"`
+	Gold          int64      `json:"gold" validate:"min=0"`
+	ExpiresInDays int64      `json:"expiresInDays" validate:"omitempty,min=1,max=365"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Image points at content-addressed blobs served from /v1/media. The same
+// bytes always get the same URL, so clients may cache them forever.
+type Image struct {
+	URL          string    `bson:"url" json:"url"`
+	ThumbnailURL string    `bson:"thumbnailUrl" json:"thumbnailUrl"`
+	ContentType  string    `bson:"contentType" json:"contentType"`
+	Width        int       `bson:"width" json:"width"`
+	Height       int       `bson:"height" json:"height"`
+	UploadedAt   time.Time `bson:"uploadedAt" json:"uploadedAt"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+type OfferStatus string
+
+const (
+	OfferStatusPending   OfferStatus = "pending"
+	OfferStatusAccepted  OfferStatus = "accepted"
+	OfferStatusDeclined  OfferStatus = "declined"
+	OfferStatusCountered OfferStatus = "countered"
+	OfferStatusCancelled OfferStatus = "cancelled"
+	OfferStatusExpired   OfferStatus = "expired"
+)
+
+type OfferItem struct {
+	ItemID string `bson:"itemId" json:"itemId" validate:"required,min=1,max=64"`
+	Qty    int64  `bson:"qty" json:"qty" validate:"required,min=1"`
+}
+
+// OfferSide is what one player puts into a trade offer.
+type OfferSide struct {
+	Items []OfferItem `bson:"items" json:"items" validate:"max=20,unique=ItemID,dive"`
+	Gold  int64       `bson:"gold" json:"gold" validate:"min=0"`
+}
+
+func (s OfferSide) Empty() bool {
+	return len(s.Items) == 0 && s.Gold == 0
+}
+
+// TradeOffer swaps Give, escrowed from the sender, for Want, taken from the
+// recipient when they accept.
+type TradeOffer struct {
+	ID          string      `bson:"_id" json:"id"`
+	FromID      string      `bson:"fromId" json:"fromId"`
+	ToID        string      `bson:"toId" json:"toId"`
+	Give        OfferSide   `bson:"give" json:"give"`
+	Want        OfferSide   `bson:"want" json:"want"`
+	Message     string      `bson:"message,omitempty" json:"message,omitempty"`
+	CounterOf   string      `bson:"counterOf,omitempty" json:"counterOf,omitempty"`
+	Status      OfferStatus `bson:"status" json:"status"`
+	ExpiresAt   time.Time   `bson:"expiresAt" json:"expiresAt"`
+	CreatedAt   time.Time   `bson:"createdAt" json:"createdAt"`
+	RespondedAt *time.Time  `bson:"respondedAt,omitempty" json:"respondedAt,omitempty"`
+}
+
+type CreateOfferRequest struct {
+	ToID       string    `json:"toId" validate:"required,min=1,max=64"`
+	Give       OfferSide `json:"give"`
+	Want       OfferSide `json:"want"`
+	Message    string    `json:"message" validate:"max=500"`
+	ExpiresInH int64     `json:"expiresInHours" validate:"omitempty,min=1,max=168"`
+}
+
+// CounterOfferRequest is written from the counterer's side: Give is what they
+// now offer and Want what they ask for in return.
+type CounterOfferRequest struct {
+	Give       OfferSide `json:"give"`
+	Want       OfferSide `json:"want"`
+	Message    string    `json:"message" validate:"max=500"`
+	ExpiresInH int64     `json:"expiresInHours" validate:"omitempty,min=1,max=168"`
+}
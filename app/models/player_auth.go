@@ -5,6 +5,7 @@ type Role string
 const (
 	RolePlayer Role = "player"
 	RoleMJ     Role = "mj"
+	RoleAdmin  Role = "admin"
 )
 
 type RegisterRequest struct {
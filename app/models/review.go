@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+type ReviewReply struct {
+	AuthorID  string    `bson:"authorId" json:"authorId"`
+	Text      string    `bson:"text" json:"text"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+type Review struct {
+	ID           string       `bson:"_id" json:"id"`
+	DungeonID    string       `bson:"dungeonId" json:"dungeonId"`
+	PlayerID     string       `bson:"playerId" json:"playerId"`
+	Rating       int          `bson:"rating" json:"rating"`
+	Text         string       `bson:"text" json:"text"`
+	Reply        *ReviewReply `bson:"reply,omitempty" json:"reply,omitempty"`
+	Hidden       bool         `bson:"hidden" json:"hidden"`
+	HiddenBy     string       `bson:"hiddenBy,omitempty" json:"hiddenBy,omitempty"`
+	HiddenReason string       `bson:"hiddenReason,omitempty" json:"hiddenReason,omitempty"`
+	CreatedAt    time.Time    `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time    `bson:"updatedAt" json:"updatedAt"`
+}
+
+type UpsertReviewRequest struct {
+	Rating int    `json:"rating" validate:"required,min=1,max=5"`
+	Text   string `json:"text" validate:"omitempty,max=1000"`
+}
+
+type ReplyReviewRequest struct {
+	Text string `json:"text" validate:"required,min=1,max=1000"`
+}
+
+type ModerateReviewRequest struct {
+	Hidden *bool  `json:"hidden" validate:"required"`
+	Reason string `json:"reason" validate:"omitempty,max=256"`
+}
@@ -16,16 +16,26 @@ type KilledStep struct {
 	AttemptID  string    `bson:"attemptId" json:"attemptId"`
 }
 
+type PendingProof struct {
+	SubmissionID string    `bson:"submissionId" json:"submissionId"`
+	StepID       string    `bson:"stepId" json:"stepId"`
+	SubmittedAt  time.Time `bson:"submittedAt" json:"submittedAt"`
+}
+
 type Run struct {
-	ID          string       `bson:"_id" json:"id"`
-	DungeonID   string       `bson:"dungeonId" json:"dungeonId"`
-	PlayerID    string       `bson:"playerId" json:"playerId"`
-	State       RunState     `bson:"state" json:"state"`
-	CurrentStep int          `bson:"currentStep" json:"currentStep"`
-	KilledSteps []KilledStep `bson:"killedSteps" json:"killedSteps"`
-	StartedAt   time.Time    `bson:"startedAt" json:"startedAt"`
-	EndedAt     *time.Time   `bson:"endedAt,omitempty" json:"endedAt,omitempty"`
-	UpdatedAt   time.Time    `bson:"updatedAt" json:"updatedAt"`
+	ID          string           `bson:"_id" json:"id"`
+	DungeonID   string           `bson:"dungeonId" json:"dungeonId"`
+	PlayerID    string           `bson:"playerId" json:"playerId"`
+	State       RunState         `bson:"state" json:"state"`
+	CurrentStep int              `bson:"currentStep" json:"currentStep"`
+	KilledSteps []KilledStep     `bson:"killedSteps" json:"killedSteps"`
+	StartedAt   time.Time        `bson:"startedAt" json:"startedAt"`
+	EndedAt     *time.Time       `bson:"endedAt,omitempty" json:"endedAt,omitempty"`
+	UpdatedAt   time.Time        `bson:"updatedAt" json:"updatedAt"`
+	DryRun      bool             `bson:"dryRun,omitempty" json:"dryRun,omitempty"`
+	Tries       map[string]int   `bson:"tries,omitempty" json:"tries,omitempty"`
+	Hints       map[string][]int `bson:"hints,omitempty" json:"hints,omitempty"`
+	Pending     *PendingProof    `bson:"pending,omitempty" json:"pending,omitempty"`
 }
 
 type StartRunRequest struct {
@@ -33,20 +43,31 @@ type StartRunRequest struct {
 }
 
 type AttemptRequest struct {
-	Lat            *float64 `json:"lat" validate:"required"`
-	Lon            *float64 `json:"lon" validate:"required"`
+	Lat            *float64 `json:"lat" validate:"required_with=Lon"`
+	Lon            *float64 `json:"lon" validate:"required_with=Lat"`
+	Code           string   `json:"code" validate:"omitempty,max=64"`
 	DeviceTime     string   `json:"deviceTime" validate:"omitempty,max=64"`
 	GPSAccuracyM   *float64 `json:"gpsAccuracyMeters" validate:"omitempty,gte=0"`
+	Answer         string   `json:"answer" validate:"omitempty,max=200"`
 	IdempotencyKey string   `json:"idempotencyKey" validate:"required,min=8,max=128"`
 }
 
+type DryRunAttemptRequest struct {
+	Lat    *float64 `json:"lat" validate:"required_with=Lon"`
+	Lon    *float64 `json:"lon" validate:"required_with=Lat"`
+	Answer string   `json:"answer" validate:"omitempty,max=200"`
+	Code   string   `json:"code" validate:"omitempty,max=64"`
+}
+
 type AttemptRecord struct {
 	ID             string    `bson:"_id" json:"id"`
 	RunID          string    `bson:"runId" json:"runId"`
+	DungeonID      string    `bson:"dungeonId,omitempty" json:"dungeonId,omitempty"`
 	StepID         string    `bson:"stepId" json:"stepId"`
 	PlayerID       string    `bson:"playerId" json:"playerId"`
 	IdempotencyKey string    `bson:"idempotencyKey" json:"idempotencyKey"`
 	RewardApplied  bool      `bson:"rewardApplied" json:"rewardApplied"`
+	Rewards        Rewards   `bson:"rewards" json:"rewards"`
 	Response       any       `bson:"response" json:"response"`
 	CreatedAt      time.Time `bson:"createdAt" json:"createdAt"`
 }
@@ -60,4 +81,70 @@ type AttemptResponse struct {
 	Player      Player      `json:"player"`
 	Idempotency bool        `json:"idempotentReplay"`
 	Proof       interface{} `json:"proof,omitempty"`
+	// Campaigns lists the campaigns this attempt finished.
+	Campaigns []CampaignCompletion `json:"campaignsCompleted,omitempty"`
+}
+
+type PhotoStatus string
+
+const (
+	PhotoStatusPending  PhotoStatus = "pending"
+	PhotoStatusApproved PhotoStatus = "approved"
+	PhotoStatusRejected PhotoStatus = "rejected"
+)
+
+type PhotoSubmission struct {
+	ID          string      `bson:"_id" json:"id"`
+	RunID       string      `bson:"runId" json:"runId"`
+	DungeonID   string      `bson:"dungeonId" json:"dungeonId"`
+	StepID      string      `bson:"stepId" json:"stepId"`
+	PlayerID    string      `bson:"playerId" json:"playerId"`
+	BlobKey     string      `bson:"blobKey" json:"-"`
+	ContentType string      `bson:"contentType" json:"contentType"`
+	Size        int64       `bson:"size" json:"size"`
+	Lat         *float64    `bson:"lat,omitempty" json:"lat,omitempty"`
+	Lon         *float64    `bson:"lon,omitempty" json:"lon,omitempty"`
+	DistanceM   float64     `bson:"distanceMeters" json:"distanceMeters"`
+	Status      PhotoStatus `bson:"status" json:"status"`
+	ReviewedBy  string      `bson:"reviewedBy,omitempty" json:"reviewedBy,omitempty"`
+	ReviewedAt  *time.Time  `bson:"reviewedAt,omitempty" json:"reviewedAt,omitempty"`
+	Reason      string      `bson:"reason,omitempty" json:"reason,omitempty"`
+	AttemptID   string      `bson:"attemptId,omitempty" json:"attemptId,omitempty"`
+	CreatedAt   time.Time   `bson:"createdAt" json:"createdAt"`
+}
+
+type PhotoAttemptRequest struct {
+	Lat  *float64 `form:"lat" validate:"required_with=Lon"`
+	Lon  *float64 `form:"lon" validate:"required_with=Lat"`
+	Code string   `form:"code" validate:"omitempty,max=64"`
+}
+
+type RejectPhotoRequest struct {
+	Reason string `json:"reason" validate:"required,min=2,max=500"`
+}
+
+type PhotoReviewResponse struct {
+	Submission PhotoSubmission  `json:"submission"`
+	Attempt    *AttemptResponse `json:"attempt,omitempty"`
+}
+
+type HintResponse struct {
+	RunID   string `json:"runId"`
+	StepID  string `json:"stepId"`
+	Index   int    `json:"index"`
+	Text    string `json:"text"`
+	Cost    int64  `json:"cost"`
+	Charged bool   `json:"charged"`
+	Player  Player `json:"player"`
+}
+
+type DryRunAttemptResponse struct {
+	RunID      string  `json:"runId"`
+	StepID     string  `json:"stepId"`
+	Lat        float64 `json:"lat"`
+	Lon        float64 `json:"lon"`
+	DistanceM  float64 `json:"distanceMeters"`
+	WouldAward Rewards `json:"wouldAward"`
+	Run        Run     `json:"run"`
+	DryRun     bool    `json:"dryRun"`
 }
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+type VendorSide string
+
+const (
+	VendorSideBuy  VendorSide = "buy"
+	VendorSideSell VendorSide = "sell"
+)
+
+// VendorEntry is an item the vendor may stock. Price overrides the
+// BaseValue-derived buy price; DailyLimit caps what one player can buy per
+// UTC day, 0 meaning no cap.
+type VendorEntry struct {
+	ItemID     string `bson:"itemId" json:"itemId" validate:"required,min=1,max=64"`
+	Price      int64  `bson:"price,omitempty" json:"price,omitempty" validate:"min=0"`
+	DailyLimit int64  `bson:"dailyLimit" json:"dailyLimit" validate:"min=0"`
+}
+
+// VendorConfig prices the vendor off ItemDef.BaseValue: it buys from players
+// at SellRate and sells at BuyRate. Every RotationHours it shows Slots
+// entries of Pool, or all of them when Slots is 0.
+type VendorConfig struct {
+	SellRate      float64       `bson:"sellRate" json:"sellRate" validate:"min=0,max=1"`
+	BuyRate       float64       `bson:"buyRate" json:"buyRate" validate:"gtfield=SellRate,max=100"`
+	RotationHours int64         `bson:"rotationHours" json:"rotationHours" validate:"min=1,max=720"`
+	Slots         int           `bson:"slots" json:"slots" validate:"min=0,max=100"`
+	Pool          []VendorEntry `bson:"pool" json:"pool" validate:"max=500,unique=ItemID,dive"`
+	UpdatedBy     string        `bson:"updatedBy,omitempty" json:"updatedBy,omitempty"`
+	UpdatedAt     time.Time     `bson:"updatedAt" json:"updatedAt"`
+}
+
+type VendorStockItem struct {
+	ItemID      string `json:"itemId"`
+	Name        string `json:"name"`
+	Rarity      string `json:"rarity"`
+	Price       int64  `json:"price"`
+	DailyLimit  int64  `json:"dailyLimit"`
+	BoughtToday int64  `json:"boughtToday"`
+}
+
+type VendorShop struct {
+	Stock     []VendorStockItem `json:"stock"`
+	SellRate  float64           `json:"sellRate"`
+	RotatesAt time.Time         `json:"rotatesAt"`
+}
+
+type VendorTrade struct {
+	ID        string     `bson:"_id" json:"id"`
+	PlayerID  string     `bson:"playerId" json:"playerId"`
+	Side      VendorSide `bson:"side" json:"side"`
+	ItemID    string     `bson:"itemId" json:"itemId"`
+	Qty       int64      `bson:"qty" json:"qty"`
+	UnitPrice int64      `bson:"unitPrice" json:"unitPrice"`
+	Total     int64      `bson:"total" json:"total"`
+	CreatedAt time.Time  `bson:"createdAt" json:"createdAt"`
+}
+
+type VendorTradeRequest struct {
+	ItemID string `json:"itemId" validate:"required,min=1,max=64"`
+	Qty    int64  `json:"qty" validate:"required,min=1,max=1000"`
+}
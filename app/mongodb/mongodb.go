@@ -56,3 +56,12 @@ func WithTransaction(ctx context.Context, client *mongo.Client, fn func(context.
 
 	return nil
 }
+
+// InTransaction runs fn inside the transaction ctx already carries, or in a
+// new one when it carries none.
+func InTransaction(ctx context.Context, client *mongo.Client, fn func(context.Context) error) error {
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+	return WithTransaction(ctx, client, fn)
+}
@@ -0,0 +1,45 @@
+package otp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestHOTPMatchesRFC4226(t *testing.T) {
+	want := []string{"755224", "287082", "359152", "969429", "338314"}
+	for counter, code := range want {
+		got, err := HOTP(rfcSecret, uint64(counter), 6)
+		if err != nil {
+			t.Fatalf("counter %d: %v", counter, err)
+		}
+		if got != code {
+			t.Fatalf("counter %d: got %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestTOTPMatchesRFC6238(t *testing.T) {
+	got, err := HOTP(rfcSecret, counterAt(time.Unix(59, 0), 30*time.Second), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "94287082" {
+		t.Fatalf("got %s, want 94287082", got)
+	}
+}
+
+func TestVerifyTOTPAllowsSkew(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	period := 30 * time.Second
+	previous, _ := TOTP(rfcSecret, now.Add(-period), period)
+	if !VerifyTOTP(rfcSecret, previous, now, period, DefaultSkew) {
+		t.Fatal("expected previous period code to verify")
+	}
+	stale, _ := TOTP(rfcSecret, now.Add(-3*period), period)
+	if VerifyTOTP(rfcSecret, stale, now, period, DefaultSkew) {
+		t.Fatal("expected stale code to be rejected")
+	}
+}
@@ -0,0 +1,169 @@
+package analytics
+
+import (
+	"context"
+	"dungeons/app/models"
+	"dungeons/app/mongodb"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	runsCollection     = "runs"
+	attemptsCollection = "attempts"
+)
+
+type MongoRepository struct {
+	db      *mongo.Database
+	timeout time.Duration
+}
+
+func NewMongoRepository(db *mongo.Database, timeout time.Duration) *MongoRepository {
+	return &MongoRepository{db: db, timeout: timeout}
+}
+
+func runMatch(dungeonID string, from, to *time.Time) bson.D {
+	match := bson.M{"dungeonId": dungeonID}
+	startedAt := bson.M{}
+	if from != nil {
+		startedAt["$gte"] = *from
+	}
+	if to != nil {
+		startedAt["$lt"] = *to
+	}
+	if len(startedAt) > 0 {
+		match["startedAt"] = startedAt
+	}
+	return bson.D{{Key: "$match", Value: match}}
+}
+
+func (r *MongoRepository) RunStateCounts(ctx context.Context, dungeonID string, from, to *time.Time) (map[models.RunState]int64, error) {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	cursor, err := r.db.Collection(runsCollection).Aggregate(cctx, mongo.Pipeline{
+		runMatch(dungeonID, from, to),
+		{{Key: "$group", Value: bson.M{"_id": "$state", "n": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("aggregate run states: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	var rows []struct {
+		State models.RunState `bson:"_id"`
+		N     int64           `bson:"n"`
+	}
+	if err := cursor.All(cctx, &rows); err != nil {
+		return nil, fmt.Errorf("decode run states: %w", err)
+	}
+	out := make(map[models.RunState]int64, len(rows))
+	for _, row := range rows {
+		out[row.State] = row.N
+	}
+	return out, nil
+}
+
+// StepKillDurations returns, per boss step, the seconds each kill took since
+// the previous kill of the same run (or since the run started).
+func (r *MongoRepository) StepKillDurations(ctx context.Context, dungeonID string, from, to *time.Time) (map[string][]float64, error) {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	kills := bson.M{"$ifNull": bson.A{"$killedSteps", bson.A{}}}
+	cursor, err := r.db.Collection(runsCollection).Aggregate(cctx, mongo.Pipeline{
+		runMatch(dungeonID, from, to),
+		{{Key: "$project", Value: bson.M{
+			"pairs": bson.M{"$map": bson.M{
+				"input": bson.M{"$range": bson.A{0, bson.M{"$size": kills}}},
+				"as":    "i",
+				"in": bson.M{
+					"stepId": bson.M{"$arrayElemAt": bson.A{"$killedSteps.bossStepId", "$$i"}},
+					"seconds": bson.M{"$divide": bson.A{
+						bson.M{"$subtract": bson.A{
+							bson.M{"$arrayElemAt": bson.A{"$killedSteps.killedAt", "$$i"}},
+							bson.M{"$cond": bson.A{
+								bson.M{"$eq": bson.A{"$$i", 0}},
+								"$startedAt",
+								bson.M{"$arrayElemAt": bson.A{"$killedSteps.killedAt", bson.M{"$subtract": bson.A{"$$i", 1}}}},
+							}},
+						}},
+						1000,
+					}},
+				},
+			}},
+		}}},
+		{{Key: "$unwind", Value: "$pairs"}},
+		{{Key: "$group", Value: bson.M{"_id": "$pairs.stepId", "seconds": bson.M{"$push": "$pairs.seconds"}}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("aggregate kill durations: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	var rows []struct {
+		StepID  string    `bson:"_id"`
+		Seconds []float64 `bson:"seconds"`
+	}
+	if err := cursor.All(cctx, &rows); err != nil {
+		return nil, fmt.Errorf("decode kill durations: %w", err)
+	}
+	out := make(map[string][]float64, len(rows))
+	for _, row := range rows {
+		out[row.StepID] = row.Seconds
+	}
+	return out, nil
+}
+
+func (r *MongoRepository) Payout(ctx context.Context, dungeonID string, from, to *time.Time) (models.Payout, error) {
+	out := models.Payout{Items: make([]models.RewardItem, 0)}
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	// Older attempt records only carry the rewards inside the replay response.
+	cursor, err := r.db.Collection(runsCollection).Aggregate(cctx, mongo.Pipeline{
+		runMatch(dungeonID, from, to),
+		{{Key: "$lookup", Value: bson.M{"from": attemptsCollection, "localField": "_id", "foreignField": "runId", "as": "attempt"}}},
+		{{Key: "$unwind", Value: "$attempt"}},
+		{{Key: "$match", Value: bson.M{"attempt.rewardApplied": true}}},
+		{{Key: "$project", Value: bson.M{
+			"gold":  bson.M{"$ifNull": bson.A{"$attempt.rewards.gold", "$attempt.response.rewards.gold", 0}},
+			"items": bson.M{"$ifNull": bson.A{"$attempt.rewards.items", "$attempt.response.rewards.items", bson.A{}}},
+		}}},
+		{{Key: "$facet", Value: bson.M{
+			"gold": bson.A{bson.M{"$group": bson.M{"_id": nil, "total": bson.M{"$sum": "$gold"}}}},
+			"items": bson.A{
+				bson.M{"$unwind": "$items"},
+				bson.M{"$group": bson.M{"_id": "$items.itemId", "qty": bson.M{"$sum": "$items.qty"}}},
+				bson.M{"$sort": bson.M{"_id": 1}},
+			},
+		}}},
+	})
+	if err != nil {
+		return out, fmt.Errorf("aggregate payout: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	var rows []struct {
+		Gold []struct {
+			Total int64 `bson:"total"`
+		} `bson:"gold"`
+		Items []struct {
+			ItemID string `bson:"_id"`
+			Qty    int64  `bson:"qty"`
+		} `bson:"items"`
+	}
+	if err := cursor.All(cctx, &rows); err != nil {
+		return out, fmt.Errorf("decode payout: %w", err)
+	}
+	if len(rows) == 0 {
+		return out, nil
+	}
+	if len(rows[0].Gold) > 0 {
+		out.Gold = rows[0].Gold[0].Total
+	}
+	for _, item := range rows[0].Items {
+		out.Items = append(out.Items, models.RewardItem{ItemID: item.ItemID, Qty: item.Qty})
+	}
+	return out, nil
+}
@@ -16,6 +16,12 @@ import (
 const (
 	listingsCollection = "auction_listings"
 	tradesCollection   = "auction_trades"
+	auctionsCollection = "auctions"
+	bidsCollection     = "auction_bids"
+	ordersCollection   = "auction_orders"
+	treasuryCollection = "auction_treasury"
+	treasuryID         = "auction"
+	itemDefsCollection = "item_defs"
 )
 
 type MongoRepository struct {
@@ -32,15 +38,37 @@ func (r *MongoRepository) EnsureIndexes(ctx context.Context) error {
 	defer cancel()
 	if _, err := r.db.Collection(listingsCollection).Indexes().CreateMany(cctx, []mongo.IndexModel{
 		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}}},
-		{Keys: bson.D{{Key: "sellerId", Value: 1}}},
+		{Keys: bson.D{{Key: "sellerId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "expiresAt", Value: 1}}},
+		{Keys: bson.D{{Key: "itemId", Value: 1}, {Key: "status", Value: 1}, {Key: "pricePerUnit", Value: 1}, {Key: "createdAt", Value: 1}}},
 	}); err != nil {
 		return fmt.Errorf("listing indexes: %w", err)
 	}
 	if _, err := r.db.Collection(tradesCollection).Indexes().CreateMany(cctx, []mongo.IndexModel{
 		{Keys: bson.D{{Key: "listingId", Value: 1}}},
+		{Keys: bson.D{{Key: "itemId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "buyerId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "sellerId", Value: 1}, {Key: "createdAt", Value: -1}}},
 	}); err != nil {
 		return fmt.Errorf("trade indexes: %w", err)
 	}
+	if _, err := r.db.Collection(auctionsCollection).Indexes().CreateMany(cctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "endsAt", Value: 1}}},
+		{Keys: bson.D{{Key: "bidderIds", Value: 1}, {Key: "endsAt", Value: -1}}},
+	}); err != nil {
+		return fmt.Errorf("auction indexes: %w", err)
+	}
+	if _, err := r.db.Collection(bidsCollection).Indexes().CreateMany(cctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "auctionId", Value: 1}, {Key: "createdAt", Value: -1}}},
+	}); err != nil {
+		return fmt.Errorf("bid indexes: %w", err)
+	}
+	if _, err := r.db.Collection(ordersCollection).Indexes().CreateMany(cctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "itemId", Value: 1}, {Key: "status", Value: 1}, {Key: "maxUnitPrice", Value: -1}, {Key: "createdAt", Value: 1}}},
+		{Keys: bson.D{{Key: "buyerId", Value: 1}, {Key: "createdAt", Value: -1}}},
+	}); err != nil {
+		return fmt.Errorf("order indexes: %w", err)
+	}
 	return nil
 }
 
@@ -54,39 +82,140 @@ func (r *MongoRepository) CreateListing(ctx context.Context, listing models.List
 	return nil
 }
 
-func (r *MongoRepository) ListActive(ctx context.Context, params models.QueryParams) ([]models.Listing, error) {
+// Listings without an expiry sort last under endingSoon.
+var listingSorts = map[string]bson.D{
+	"createdAt":  {{Key: "createdAt", Value: 1}},
+	"-createdAt": {{Key: "createdAt", Value: -1}},
+	"price":      {{Key: "pricePerUnit", Value: 1}, {Key: "createdAt", Value: 1}},
+	"-price":     {{Key: "pricePerUnit", Value: -1}, {Key: "createdAt", Value: 1}},
+	"total":      {{Key: "totalPrice", Value: 1}, {Key: "createdAt", Value: 1}},
+	"-total":     {{Key: "totalPrice", Value: -1}, {Key: "createdAt", Value: 1}},
+	"endingSoon": {{Key: "endsAt", Value: 1}, {Key: "createdAt", Value: 1}},
+}
+
+var noExpiry = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+func (r *MongoRepository) SearchListings(ctx context.Context, query models.ListingSearchQuery, now time.Time, params models.QueryParams) ([]models.Listing, error) {
 	q := params.Normalize()
 	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
 	defer cancel()
-	now := time.Now().UTC()
-	filter := bson.M{
+
+	match := bson.M{
 		"status": models.ListingStatusActive,
-		"$or": []bson.M{
-			{"expiresAt": bson.M{"$exists": false}},
-			{"expiresAt": nil},
-			{"expiresAt": bson.M{"$gt": now}},
-		},
+		"$or":    notExpired(now),
+	}
+	if query.ItemID != "" {
+		match["itemId"] = query.ItemID
+	}
+	if query.SellerID != "" {
+		match["sellerId"] = query.SellerID
+	}
+	price := bson.M{}
+	if query.MinPrice != nil {
+		price["$gte"] = *query.MinPrice
 	}
-	cursor, err := r.db.Collection(listingsCollection).Find(cctx, filter, options.Find().SetSkip(q.Skip()).SetLimit(q.Limit).SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if query.MaxPrice != nil {
+		price["$lte"] = *query.MaxPrice
+	}
+	if len(price) > 0 {
+		match["pricePerUnit"] = price
+	}
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: match}}}
+
+	if query.Type != "" || query.Rarity != "" {
+		item := bson.M{}
+		if query.Type != "" {
+			item["item.type"] = query.Type
+		}
+		if query.Rarity != "" {
+			item["item.rarity"] = query.Rarity
+		}
+		pipeline = append(pipeline,
+			bson.D{{Key: "$lookup", Value: bson.M{"from": itemDefsCollection, "localField": "itemId", "foreignField": "_id", "as": "item"}}},
+			bson.D{{Key: "$match", Value: item}},
+		)
+	}
+
+	sort, ok := listingSorts[query.Sort]
+	if !ok {
+		sort = listingSorts["-createdAt"]
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$addFields", Value: bson.M{
+			"totalPrice": bson.M{"$multiply": bson.A{"$qty", "$pricePerUnit"}},
+			"endsAt":     bson.M{"$ifNull": bson.A{"$expiresAt", noExpiry}},
+		}}},
+		bson.D{{Key: "$sort", Value: sort}},
+		bson.D{{Key: "$skip", Value: q.Skip()}},
+		bson.D{{Key: "$limit", Value: q.Limit}},
+		bson.D{{Key: "$project", Value: bson.M{"item": 0, "totalPrice": 0, "endsAt": 0}}},
+	)
+	cursor, err := r.db.Collection(listingsCollection).Aggregate(cctx, pipeline)
 	if err != nil {
-		return nil, fmt.Errorf("list listings: %w", err)
+		return nil, fmt.Errorf("search listings: %w", err)
 	}
 	defer cursor.Close(cctx)
 
 	listings := make([]models.Listing, 0)
-	for cursor.Next(cctx) {
-		var l models.Listing
-		if err := cursor.Decode(&l); err != nil {
-			return nil, fmt.Errorf("decode listing: %w", err)
-		}
-		listings = append(listings, l)
+	if err := cursor.All(cctx, &listings); err != nil {
+		return nil, fmt.Errorf("decode listings: %w", err)
 	}
-	if err := cursor.Err(); err != nil {
-		return nil, fmt.Errorf("listing cursor: %w", err)
+	return listings, nil
+}
+
+func (r *MongoRepository) ListListingsBySeller(ctx context.Context, sellerID string, params models.QueryParams) ([]models.Listing, error) {
+	q := params.Normalize()
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	cursor, err := r.db.Collection(listingsCollection).Find(cctx, bson.M{"sellerId": sellerID}, options.Find().SetSkip(q.Skip()).SetLimit(q.Limit).SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		return nil, fmt.Errorf("list seller listings: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	listings := make([]models.Listing, 0)
+	if err := cursor.All(cctx, &listings); err != nil {
+		return nil, fmt.Errorf("decode listings: %w", err)
 	}
 	return listings, nil
 }
 
+// ListTradesByPlayer returns the trades the player bought or sold in, or
+// only one side of them when side is set.
+func (r *MongoRepository) ListTradesByPlayer(ctx context.Context, playerID string, side models.TradeSide, params models.QueryParams) ([]models.Trade, error) {
+	q := params.Normalize()
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	var filter bson.M
+	switch side {
+	case models.TradeSideBuy:
+		filter = bson.M{"buyerId": playerID}
+	case models.TradeSideSell:
+		filter = bson.M{"sellerId": playerID}
+	default:
+		filter = bson.M{"$or": bson.A{bson.M{"buyerId": playerID}, bson.M{"sellerId": playerID}}}
+	}
+	cursor, err := r.db.Collection(tradesCollection).Find(cctx, filter, options.Find().SetSkip(q.Skip()).SetLimit(q.Limit).SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		return nil, fmt.Errorf("list player trades: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	trades := make([]models.Trade, 0)
+	if err := cursor.All(cctx, &trades); err != nil {
+		return nil, fmt.Errorf("decode trades: %w", err)
+	}
+	return trades, nil
+}
+
+func notExpired(now time.Time) []bson.M {
+	return []bson.M{
+		{"expiresAt": bson.M{"$exists": false}},
+		{"expiresAt": nil},
+		{"expiresAt": bson.M{"$gt": now}},
+	}
+}
+
 func (r *MongoRepository) GetByID(ctx context.Context, id string) (models.Listing, error) {
 	var listing models.Listing
 	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
@@ -104,10 +233,10 @@ func (r *MongoRepository) ReplaceListing(ctx context.Context, listing models.Lis
 	var out models.Listing
 	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
 	defer cancel()
-	err := r.db.Collection(listingsCollection).FindOneAndReplace(cctx, bson.M{"_id": listing.ID}, listing, options.FindOneAndReplace().SetReturnDocument(options.After)).Decode(&out)
+	err := r.db.Collection(listingsCollection).FindOneAndReplace(cctx, bson.M{"_id": listing.ID, "status": models.ListingStatusActive}, listing, options.FindOneAndReplace().SetReturnDocument(options.After)).Decode(&out)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return out, fmt.Errorf("listing id %s: %w", listing.ID, apperrors.ErrNotFound)
+			return out, fmt.Errorf("listing id %s is no longer active: %w", listing.ID, apperrors.ErrConflict)
 		}
 		return out, fmt.Errorf("replace listing: %w", err)
 	}
@@ -123,3 +252,373 @@ func (r *MongoRepository) InsertTrade(ctx context.Context, trade models.Trade) e
 	}
 	return nil
 }
+
+func (r *MongoRepository) ListExpired(ctx context.Context, now time.Time, limit int64) ([]models.Listing, error) {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	filter := bson.M{"status": models.ListingStatusActive, "expiresAt": bson.M{"$lte": now}}
+	cursor, err := r.db.Collection(listingsCollection).Find(cctx, filter, options.Find().SetLimit(limit).SetSort(bson.D{{Key: "expiresAt", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("list expired listings: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	listings := make([]models.Listing, 0)
+	if err := cursor.All(cctx, &listings); err != nil {
+		return nil, fmt.Errorf("decode expired listings: %w", err)
+	}
+	return listings, nil
+}
+
+// ExpireListing flips an active listing past its expiresAt to expired and
+// returns it as it was just before. ok is false when the listing was sold,
+// cancelled or expired by someone else in the meantime.
+func (r *MongoRepository) ExpireListing(ctx context.Context, id string, now time.Time) (models.Listing, bool, error) {
+	var before models.Listing
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	err := r.db.Collection(listingsCollection).FindOneAndUpdate(cctx,
+		bson.M{"_id": id, "status": models.ListingStatusActive, "expiresAt": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{"status": models.ListingStatusExpired}},
+	).Decode(&before)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return before, false, nil
+		}
+		return before, false, fmt.Errorf("expire listing: %w", err)
+	}
+	return before, true, nil
+}
+
+func (r *MongoRepository) CreateAuction(ctx context.Context, auction models.Auction) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if _, err := r.db.Collection(auctionsCollection).InsertOne(cctx, auction); err != nil {
+		return fmt.Errorf("insert auction: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) GetAuction(ctx context.Context, id string) (models.Auction, error) {
+	var auction models.Auction
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if err := r.db.Collection(auctionsCollection).FindOne(cctx, bson.M{"_id": id}).Decode(&auction); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return auction, fmt.Errorf("auction id %s: %w", id, apperrors.ErrNotFound)
+		}
+		return auction, fmt.Errorf("find auction: %w", err)
+	}
+	return auction, nil
+}
+
+// ReplaceOpenAuction only overwrites an auction that is still active, so a
+// bid cannot land on an auction another request has just settled.
+func (r *MongoRepository) ReplaceOpenAuction(ctx context.Context, auction models.Auction) (models.Auction, error) {
+	var out models.Auction
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	err := r.db.Collection(auctionsCollection).FindOneAndReplace(cctx, bson.M{"_id": auction.ID, "status": models.ListingStatusActive}, auction, options.FindOneAndReplace().SetReturnDocument(options.After)).Decode(&out)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return out, fmt.Errorf("auction id %s is no longer active: %w", auction.ID, apperrors.ErrConflict)
+		}
+		return out, fmt.Errorf("replace auction: %w", err)
+	}
+	return out, nil
+}
+
+func (r *MongoRepository) ListOpenAuctions(ctx context.Context, now time.Time, params models.QueryParams) ([]models.Auction, error) {
+	q := params.Normalize()
+	filter := bson.M{"status": models.ListingStatusActive, "endsAt": bson.M{"$gt": now}}
+	return r.findAuctions(ctx, filter, options.Find().SetSkip(q.Skip()).SetLimit(q.Limit).SetSort(bson.D{{Key: "endsAt", Value: 1}}))
+}
+
+func (r *MongoRepository) ListAuctionsByBidder(ctx context.Context, bidderID string, params models.QueryParams) ([]models.Auction, error) {
+	q := params.Normalize()
+	return r.findAuctions(ctx, bson.M{"bidderIds": bidderID}, options.Find().SetSkip(q.Skip()).SetLimit(q.Limit).SetSort(bson.D{{Key: "endsAt", Value: -1}}))
+}
+
+func (r *MongoRepository) ListDueAuctions(ctx context.Context, now time.Time, limit int64) ([]models.Auction, error) {
+	filter := bson.M{"status": models.ListingStatusActive, "endsAt": bson.M{"$lte": now}}
+	return r.findAuctions(ctx, filter, options.Find().SetLimit(limit).SetSort(bson.D{{Key: "endsAt", Value: 1}}))
+}
+
+func (r *MongoRepository) findAuctions(ctx context.Context, filter bson.M, opts *options.FindOptionsBuilder) ([]models.Auction, error) {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	cursor, err := r.db.Collection(auctionsCollection).Find(cctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("list auctions: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	auctions := make([]models.Auction, 0)
+	if err := cursor.All(cctx, &auctions); err != nil {
+		return nil, fmt.Errorf("decode auctions: %w", err)
+	}
+	return auctions, nil
+}
+
+func (r *MongoRepository) InsertBid(ctx context.Context, bid models.Bid) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if _, err := r.db.Collection(bidsCollection).InsertOne(cctx, bid); err != nil {
+		return fmt.Errorf("insert bid: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) ListBids(ctx context.Context, auctionID string, params models.QueryParams) ([]models.Bid, error) {
+	q := params.Normalize()
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	cursor, err := r.db.Collection(bidsCollection).Find(cctx, bson.M{"auctionId": auctionID}, options.Find().SetSkip(q.Skip()).SetLimit(q.Limit).SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		return nil, fmt.Errorf("list bids: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	bids := make([]models.Bid, 0)
+	if err := cursor.All(cctx, &bids); err != nil {
+		return nil, fmt.Errorf("decode bids: %w", err)
+	}
+	return bids, nil
+}
+
+func (r *MongoRepository) CreateBuyOrder(ctx context.Context, order models.BuyOrder) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if _, err := r.db.Collection(ordersCollection).InsertOne(cctx, order); err != nil {
+		return fmt.Errorf("insert buy order: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) GetBuyOrder(ctx context.Context, id string) (models.BuyOrder, error) {
+	var order models.BuyOrder
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if err := r.db.Collection(ordersCollection).FindOne(cctx, bson.M{"_id": id}).Decode(&order); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return order, fmt.Errorf("buy order id %s: %w", id, apperrors.ErrNotFound)
+		}
+		return order, fmt.Errorf("find buy order: %w", err)
+	}
+	return order, nil
+}
+
+func (r *MongoRepository) ReplaceOpenBuyOrder(ctx context.Context, order models.BuyOrder) (models.BuyOrder, error) {
+	var out models.BuyOrder
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	err := r.db.Collection(ordersCollection).FindOneAndReplace(cctx, bson.M{"_id": order.ID, "status": models.BuyOrderStatusOpen}, order, options.FindOneAndReplace().SetReturnDocument(options.After)).Decode(&out)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return out, fmt.Errorf("buy order id %s is no longer open: %w", order.ID, apperrors.ErrConflict)
+		}
+		return out, fmt.Errorf("replace buy order: %w", err)
+	}
+	return out, nil
+}
+
+func (r *MongoRepository) ListBuyOrdersByBuyer(ctx context.Context, buyerID string, params models.QueryParams) ([]models.BuyOrder, error) {
+	q := params.Normalize()
+	return r.findBuyOrders(ctx, bson.M{"buyerId": buyerID}, options.Find().SetSkip(q.Skip()).SetLimit(q.Limit).SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+}
+
+// ListMatchingOrders returns open buy orders a sell at minPrice can fill,
+// best price first and oldest first within a price.
+func (r *MongoRepository) ListMatchingOrders(ctx context.Context, itemID string, minPrice int64, excludeBuyer string, limit int64) ([]models.BuyOrder, error) {
+	filter := bson.M{
+		"itemId":       itemID,
+		"status":       models.BuyOrderStatusOpen,
+		"maxUnitPrice": bson.M{"$gte": minPrice},
+		"buyerId":      bson.M{"$ne": excludeBuyer},
+	}
+	return r.findBuyOrders(ctx, filter, options.Find().SetLimit(limit).SetSort(bson.D{{Key: "maxUnitPrice", Value: -1}, {Key: "createdAt", Value: 1}}))
+}
+
+func (r *MongoRepository) findBuyOrders(ctx context.Context, filter bson.M, opts *options.FindOptionsBuilder) ([]models.BuyOrder, error) {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	cursor, err := r.db.Collection(ordersCollection).Find(cctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("list buy orders: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	orders := make([]models.BuyOrder, 0)
+	if err := cursor.All(cctx, &orders); err != nil {
+		return nil, fmt.Errorf("decode buy orders: %w", err)
+	}
+	return orders, nil
+}
+
+// ListMatchingListings returns live listings a buy at maxPrice can take,
+// cheapest first and oldest first within a price.
+func (r *MongoRepository) ListMatchingListings(ctx context.Context, itemID string, maxPrice int64, excludeSeller string, now time.Time, limit int64) ([]models.Listing, error) {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	filter := bson.M{
+		"itemId":       itemID,
+		"status":       models.ListingStatusActive,
+		"pricePerUnit": bson.M{"$lte": maxPrice},
+		"sellerId":     bson.M{"$ne": excludeSeller},
+		"$or":          notExpired(now),
+	}
+	cursor, err := r.db.Collection(listingsCollection).Find(cctx, filter, options.Find().SetLimit(limit).SetSort(bson.D{{Key: "pricePerUnit", Value: 1}, {Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("list matching listings: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	listings := make([]models.Listing, 0)
+	if err := cursor.All(cctx, &listings); err != nil {
+		return nil, fmt.Errorf("decode matching listings: %w", err)
+	}
+	return listings, nil
+}
+
+// OrderBook aggregates open buy orders and live listings of an item by unit
+// price, keeping the depth best levels of each side.
+func (r *MongoRepository) OrderBook(ctx context.Context, itemID string, now time.Time, depth int64) (bids, asks []models.BookLevel, err error) {
+	bids, err = r.bookSide(ctx, ordersCollection,
+		bson.M{"itemId": itemID, "status": models.BuyOrderStatusOpen},
+		"$maxUnitPrice", "$remaining", -1, depth)
+	if err != nil {
+		return nil, nil, err
+	}
+	asks, err = r.bookSide(ctx, listingsCollection,
+		bson.M{"itemId": itemID, "status": models.ListingStatusActive, "$or": notExpired(now)},
+		"$pricePerUnit", "$qty", 1, depth)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bids, asks, nil
+}
+
+func (r *MongoRepository) bookSide(ctx context.Context, collection string, match bson.M, price, qty string, order int, depth int64) ([]models.BookLevel, error) {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{"_id": price, "qty": bson.M{"$sum": qty}, "orders": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: order}}}},
+		{{Key: "$limit", Value: depth}},
+	}
+	cursor, err := r.db.Collection(collection).Aggregate(cctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate order book: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	levels := make([]models.BookLevel, 0)
+	if err := cursor.All(cctx, &levels); err != nil {
+		return nil, fmt.Errorf("decode order book: %w", err)
+	}
+	return levels, nil
+}
+
+func (r *MongoRepository) CreditTreasury(ctx context.Context, deposits, commissions int64, updatedAt time.Time) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	_, err := r.db.Collection(treasuryCollection).UpdateOne(cctx,
+		bson.M{"_id": treasuryID},
+		bson.M{
+			"$inc": bson.M{"deposits": deposits, "commissions": commissions, "total": deposits + commissions},
+			"$set": bson.M{"updatedAt": updatedAt},
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("credit treasury: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) GetTreasury(ctx context.Context) (models.Treasury, error) {
+	treasury := models.Treasury{ID: treasuryID}
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if err := r.db.Collection(treasuryCollection).FindOne(cctx, bson.M{"_id": treasuryID}).Decode(&treasury); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return treasury, nil
+		}
+		return treasury, fmt.Errorf("find treasury: %w", err)
+	}
+	return treasury, nil
+}
+
+// PriceHistory buckets the trades of an item since a time by unit ("hour",
+// "day" or "week", weeks starting on Monday) into OHLC unit prices.
+func (r *MongoRepository) PriceHistory(ctx context.Context, itemID, unit string, since time.Time) ([]models.PriceBucket, error) {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	trunc := bson.M{"date": "$createdAt", "unit": unit}
+	if unit == "week" {
+		trunc["startOfWeek"] = "monday"
+	}
+	cursor, err := r.db.Collection(tradesCollection).Aggregate(cctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"itemId": itemID, "createdAt": bson.M{"$gte": since}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "createdAt", Value: 1}}}},
+		{{Key: "$set", Value: bson.M{"unitPrice": bson.M{"$divide": bson.A{"$totalPrice", "$qty"}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    bson.M{"$dateTrunc": trunc},
+			"open":   bson.M{"$first": "$unitPrice"},
+			"high":   bson.M{"$max": "$unitPrice"},
+			"low":    bson.M{"$min": "$unitPrice"},
+			"close":  bson.M{"$last": "$unitPrice"},
+			"volume": bson.M{"$sum": "$qty"},
+			"value":  bson.M{"$sum": "$totalPrice"},
+			"trades": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("aggregate price history: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	buckets := make([]models.PriceBucket, 0)
+	if err := cursor.All(cctx, &buckets); err != nil {
+		return nil, fmt.Errorf("decode price history: %w", err)
+	}
+	return buckets, nil
+}
+
+func (r *MongoRepository) LastTrade(ctx context.Context, itemID string) (models.Trade, error) {
+	var trade models.Trade
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	err := r.db.Collection(tradesCollection).FindOne(cctx, bson.M{"itemId": itemID}, options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: -1}})).Decode(&trade)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return trade, fmt.Errorf("no trade for item %s: %w", itemID, apperrors.ErrNotFound)
+		}
+		return trade, fmt.Errorf("find last trade: %w", err)
+	}
+	return trade, nil
+}
+
+func (r *MongoRepository) MarketSummary(ctx context.Context, itemID string, since time.Time) (models.MarketSummary, error) {
+	var summary models.MarketSummary
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	cursor, err := r.db.Collection(tradesCollection).Aggregate(cctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"itemId": itemID, "createdAt": bson.M{"$gte": since}}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "value": bson.M{"$sum": "$totalPrice"}, "volume": bson.M{"$sum": "$qty"}, "trades": bson.M{"$sum": 1}}}},
+		{{Key: "$project", Value: bson.M{"volume": 1, "trades": 1, "avgUnitPrice": bson.M{"$divide": bson.A{"$value", "$volume"}}}}},
+	})
+	if err != nil {
+		return summary, fmt.Errorf("aggregate market summary: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	if cursor.Next(cctx) {
+		if err := cursor.Decode(&summary); err != nil {
+			return summary, fmt.Errorf("decode market summary: %w", err)
+		}
+	}
+	return summary, cursor.Err()
+}
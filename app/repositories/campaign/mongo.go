@@ -0,0 +1,144 @@
+package campaign
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/models"
+	"dungeons/app/mongodb"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	campaignsCollection   = "campaigns"
+	completionsCollection = "campaign_completions"
+)
+
+type MongoRepository struct {
+	db      *mongo.Database
+	timeout time.Duration
+}
+
+func NewMongoRepository(db *mongo.Database, timeout time.Duration) *MongoRepository {
+	return &MongoRepository{db: db, timeout: timeout}
+}
+
+func (r *MongoRepository) EnsureIndexes(ctx context.Context) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if _, err := r.db.Collection(campaignsCollection).Indexes().CreateMany(cctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "entries.dungeonId", Value: 1}, {Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "createdBy", Value: 1}, {Key: "updatedAt", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}}},
+	}); err != nil {
+		return fmt.Errorf("campaign indexes: %w", err)
+	}
+	if _, err := r.db.Collection(completionsCollection).Indexes().CreateOne(cctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "campaignId", Value: 1}, {Key: "playerId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return fmt.Errorf("campaign completion indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) CreateCampaign(ctx context.Context, c models.Campaign) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if _, err := r.db.Collection(campaignsCollection).InsertOne(cctx, c); err != nil {
+		return fmt.Errorf("insert campaign: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) ReplaceCampaign(ctx context.Context, c models.Campaign) (models.Campaign, error) {
+	var out models.Campaign
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	err := r.db.Collection(campaignsCollection).FindOneAndReplace(cctx, bson.M{"_id": c.ID}, c, options.FindOneAndReplace().SetReturnDocument(options.After)).Decode(&out)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return out, fmt.Errorf("campaign id %s: %w", c.ID, apperrors.ErrNotFound)
+		}
+		return out, fmt.Errorf("replace campaign: %w", err)
+	}
+	return out, nil
+}
+
+func (r *MongoRepository) GetCampaignByID(ctx context.Context, id string) (models.Campaign, error) {
+	var c models.Campaign
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if err := r.db.Collection(campaignsCollection).FindOne(cctx, bson.M{"_id": id}).Decode(&c); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c, fmt.Errorf("campaign id %s: %w", id, apperrors.ErrNotFound)
+		}
+		return c, fmt.Errorf("find campaign: %w", err)
+	}
+	return c, nil
+}
+
+func (r *MongoRepository) ListCampaigns(ctx context.Context, filter bson.M, params models.QueryParams) ([]models.Campaign, error) {
+	q := params.Normalize()
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	cursor, err := r.db.Collection(campaignsCollection).Find(cctx, filter,
+		options.Find().SetSkip(q.Skip()).SetLimit(q.Limit).SetSort(bson.D{{Key: "createdAt", Value: -1}}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list campaigns: %w", err)
+	}
+	defer cursor.Close(cctx)
+	out := make([]models.Campaign, 0)
+	if err := cursor.All(cctx, &out); err != nil {
+		return nil, fmt.Errorf("decode campaigns: %w", err)
+	}
+	return out, nil
+}
+
+func (r *MongoRepository) ListPublishedByDungeon(ctx context.Context, dungeonID string) ([]models.Campaign, error) {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	cursor, err := r.db.Collection(campaignsCollection).Find(cctx, bson.M{
+		"entries.dungeonId": dungeonID,
+		"status":            models.CampaignStatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list campaigns by dungeon: %w", err)
+	}
+	defer cursor.Close(cctx)
+	out := make([]models.Campaign, 0)
+	if err := cursor.All(cctx, &out); err != nil {
+		return nil, fmt.Errorf("decode campaigns: %w", err)
+	}
+	return out, nil
+}
+
+func (r *MongoRepository) GetCompletion(ctx context.Context, campaignID, playerID string) (models.CampaignCompletion, error) {
+	var c models.CampaignCompletion
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if err := r.db.Collection(completionsCollection).FindOne(cctx, bson.M{"campaignId": campaignID, "playerId": playerID}).Decode(&c); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return c, fmt.Errorf("campaign completion: %w", apperrors.ErrNotFound)
+		}
+		return c, fmt.Errorf("find campaign completion: %w", err)
+	}
+	return c, nil
+}
+
+func (r *MongoRepository) CreateCompletion(ctx context.Context, c models.CampaignCompletion) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if _, err := r.db.Collection(completionsCollection).InsertOne(cctx, c); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("campaign already completed: %w", apperrors.ErrConflict)
+		}
+		return fmt.Errorf("insert campaign completion: %w", err)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"dungeons/app/models"
 	"dungeons/app/mongodb"
 	"fmt"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -33,7 +34,19 @@ func (r *MongoRepository) EnsureIndexes(ctx context.Context) error {
 
 	if _, err := r.db.Collection(dungeonsCollection).Indexes().CreateMany(cctx, []mongo.IndexModel{
 		{Keys: bson.D{{Key: "createdBy", Value: 1}}},
+		{Keys: bson.D{{Key: "collaborators.playerId", Value: 1}}},
 		{Keys: bson.D{{Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "isTemplate", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "publishAt", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "unpublishAt", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "areaName", Value: 1}, {Key: "stats.avgDifficulty", Value: 1}}},
+		{
+			Keys: bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}, {Key: "areaName", Value: "text"}},
+			Options: options.Index().
+				SetName("dungeon_search").
+				SetDefaultLanguage("none").
+				SetWeights(bson.D{{Key: "title", Value: 10}, {Key: "areaName", Value: 5}, {Key: "description", Value: 1}}),
+		},
 	}); err != nil {
 		return fmt.Errorf("dungeon indexes: %w", err)
 	}
@@ -72,6 +85,23 @@ func (r *MongoRepository) UpdateDungeon(ctx context.Context, d models.Dungeon) (
 	return out, nil
 }
 
+// SetCover sets or, when cover is nil, removes the dungeon cover without
+// touching the rest of the document.
+func (r *MongoRepository) SetCover(ctx context.Context, id string, cover *models.Image, updatedAt time.Time) (models.Dungeon, error) {
+	var out models.Dungeon
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	err := r.db.Collection(dungeonsCollection).FindOneAndUpdate(cctx, bson.M{"_id": id}, imageUpdate("cover", cover, updatedAt), options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&out)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return out, fmt.Errorf("dungeon id %s: %w", id, apperrors.ErrNotFound)
+		}
+		return out, fmt.Errorf("set dungeon cover: %w", err)
+	}
+	return out, nil
+}
+
 func (r *MongoRepository) GetDungeonByID(ctx context.Context, id string) (models.Dungeon, error) {
 	var d models.Dungeon
 	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
@@ -110,6 +140,196 @@ func (r *MongoRepository) ListDungeonsByFilter(ctx context.Context, filter bson.
 	return out, nil
 }
 
+var searchSorts = map[string]bson.D{
+	"createdAt":   {{Key: "createdAt", Value: 1}},
+	"-createdAt":  {{Key: "createdAt", Value: -1}},
+	"difficulty":  {{Key: "stats.avgDifficulty", Value: 1}, {Key: "createdAt", Value: -1}},
+	"-difficulty": {{Key: "stats.avgDifficulty", Value: -1}, {Key: "createdAt", Value: -1}},
+	"reward":      {{Key: "stats.totalRewardGold", Value: 1}, {Key: "createdAt", Value: -1}},
+	"-reward":     {{Key: "stats.totalRewardGold", Value: -1}, {Key: "createdAt", Value: -1}},
+	"steps":       {{Key: "stats.stepCount", Value: 1}, {Key: "createdAt", Value: -1}},
+	"-steps":      {{Key: "stats.stepCount", Value: -1}, {Key: "createdAt", Value: -1}},
+	"rating":      {{Key: "rating.average", Value: 1}, {Key: "createdAt", Value: -1}},
+	"-rating":     {{Key: "rating.average", Value: -1}, {Key: "rating.count", Value: -1}},
+}
+
+func (r *MongoRepository) SearchDungeons(ctx context.Context, filter bson.M, query models.DungeonSearchQuery, params models.QueryParams) (models.DungeonSearchResult, error) {
+	var out models.DungeonSearchResult
+	q := params.Normalize()
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	match := bson.M{}
+	for k, v := range filter {
+		match[k] = v
+	}
+	if query.Q != "" {
+		match["$text"] = bson.M{"$search": query.Q}
+	}
+	if query.Area != "" {
+		match["areaName"] = bson.M{"$regex": "^" + regexp.QuoteMeta(query.Area) + "$", "$options": "i"}
+	}
+	difficulty := bson.M{}
+	if query.MinDifficulty != nil {
+		difficulty["$gte"] = *query.MinDifficulty
+	}
+	if query.MaxDifficulty != nil {
+		difficulty["$lte"] = *query.MaxDifficulty
+	}
+	if len(difficulty) > 0 {
+		match["stats.avgDifficulty"] = difficulty
+	}
+
+	sort, ok := searchSorts[query.Sort]
+	if !ok {
+		sort = searchSorts["-createdAt"]
+	}
+	if query.Q != "" && (query.Sort == "" || query.Sort == "relevance") {
+		sort = bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}, {Key: "createdAt", Value: -1}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$facet", Value: bson.M{
+			"data": bson.A{
+				bson.M{"$sort": sort},
+				bson.M{"$skip": q.Skip()},
+				bson.M{"$limit": q.Limit},
+			},
+			"total": bson.A{bson.M{"$count": "n"}},
+			"areas": bson.A{
+				bson.M{"$group": bson.M{"_id": "$areaName", "count": bson.M{"$sum": 1}}},
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+			},
+			"difficulty": bson.A{
+				bson.M{"$group": bson.M{"_id": bson.M{"$round": bson.A{bson.M{"$ifNull": bson.A{"$stats.avgDifficulty", 0}}, 0}}, "count": bson.M{"$sum": 1}}},
+				bson.M{"$sort": bson.M{"_id": 1}},
+			},
+		}}},
+	}
+	cursor, err := r.db.Collection(dungeonsCollection).Aggregate(cctx, pipeline)
+	if err != nil {
+		return out, fmt.Errorf("search dungeons: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	var facets []struct {
+		Data  []models.Dungeon `bson:"data"`
+		Total []struct {
+			N int64 `bson:"n"`
+		} `bson:"total"`
+		Areas      []facetBucket `bson:"areas"`
+		Difficulty []facetBucket `bson:"difficulty"`
+	}
+	if err := cursor.All(cctx, &facets); err != nil {
+		return out, fmt.Errorf("decode dungeon search: %w", err)
+	}
+	out.Dungeons = make([]models.Dungeon, 0)
+	out.Facets = models.DungeonFacets{Areas: make([]models.FacetCount, 0), Difficulty: make([]models.FacetCount, 0)}
+	if len(facets) == 0 {
+		return out, nil
+	}
+	f := facets[0]
+	if f.Data != nil {
+		out.Dungeons = f.Data
+	}
+	if len(f.Total) > 0 {
+		out.Total = f.Total[0].N
+	}
+	for _, b := range f.Areas {
+		out.Facets.Areas = append(out.Facets.Areas, b.toFacet())
+	}
+	for _, b := range f.Difficulty {
+		out.Facets.Difficulty = append(out.Facets.Difficulty, b.toFacet())
+	}
+	return out, nil
+}
+
+type facetBucket struct {
+	ID    any   `bson:"_id"`
+	Count int64 `bson:"count"`
+}
+
+func (b facetBucket) toFacet() models.FacetCount {
+	return models.FacetCount{Value: fmt.Sprint(b.ID), Count: b.Count}
+}
+
+func (r *MongoRepository) UpdateDungeonStats(ctx context.Context, id string, stats models.DungeonStats) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	res, err := r.db.Collection(dungeonsCollection).UpdateOne(cctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"stats": stats}})
+	if err != nil {
+		return fmt.Errorf("update dungeon stats: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("dungeon id %s: %w", id, apperrors.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *MongoRepository) ListByScheduleField(ctx context.Context, filter bson.M, field string, params models.QueryParams) ([]models.Dungeon, error) {
+	q := params.Normalize()
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	cursor, err := r.db.Collection(dungeonsCollection).Find(cctx, filter, options.Find().SetSkip(q.Skip()).SetLimit(q.Limit).SetSort(bson.D{{Key: field, Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("list scheduled dungeons: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	out := make([]models.Dungeon, 0)
+	if err := cursor.All(cctx, &out); err != nil {
+		return nil, fmt.Errorf("decode scheduled dungeons: %w", err)
+	}
+	return out, nil
+}
+
+// TransitionStatus only moves the dungeon if it is still in the from status,
+// so a scheduler tick racing a manual change is a no-op.
+func (r *MongoRepository) TransitionStatus(ctx context.Context, id string, from, to models.DungeonStatus, set bson.M, updatedAt time.Time) (bool, error) {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	fields := bson.M{"status": to, "updatedAt": updatedAt}
+	for k, v := range set {
+		fields[k] = v
+	}
+	res, err := r.db.Collection(dungeonsCollection).UpdateOne(cctx,
+		bson.M{"_id": id, "status": from},
+		bson.M{"$set": fields, "$unset": bson.M{"scheduleError": ""}},
+	)
+	if err != nil {
+		return false, fmt.Errorf("transition dungeon status: %w", err)
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (r *MongoRepository) FailSchedule(ctx context.Context, id, reason string, updatedAt time.Time) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	_, err := r.db.Collection(dungeonsCollection).UpdateOne(cctx,
+		bson.M{"_id": id, "status": models.DungeonStatusDraft},
+		bson.M{"$set": bson.M{"scheduleError": reason, "updatedAt": updatedAt}, "$unset": bson.M{"publishAt": ""}},
+	)
+	if err != nil {
+		return fmt.Errorf("record schedule failure: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) UpdateDungeonRating(ctx context.Context, id string, rating models.DungeonRating) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	res, err := r.db.Collection(dungeonsCollection).UpdateOne(cctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"rating": rating}})
+	if err != nil {
+		return fmt.Errorf("update dungeon rating: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("dungeon id %s: %w", id, apperrors.ErrNotFound)
+	}
+	return nil
+}
+
 func (r *MongoRepository) CreateStep(ctx context.Context, step models.BossStep) error {
 	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
 	defer cancel()
@@ -123,6 +343,25 @@ func (r *MongoRepository) CreateStep(ctx context.Context, step models.BossStep)
 	return nil
 }
 
+func (r *MongoRepository) CreateSteps(ctx context.Context, steps []models.BossStep) error {
+	if len(steps) == 0 {
+		return nil
+	}
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	docs := make([]any, 0, len(steps))
+	for _, step := range steps {
+		docs = append(docs, step)
+	}
+	if _, err := r.db.Collection(stepsCollection).InsertMany(cctx, docs); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("duplicate step order: %w", apperrors.ErrConflict)
+		}
+		return fmt.Errorf("insert steps: %w", err)
+	}
+	return nil
+}
+
 func (r *MongoRepository) UpdateStep(ctx context.Context, step models.BossStep) (models.BossStep, error) {
 	var out models.BossStep
 	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
@@ -138,6 +377,30 @@ func (r *MongoRepository) UpdateStep(ctx context.Context, step models.BossStep)
 	return out, nil
 }
 
+// SetStepArt sets or, when art is nil, removes the step art without touching
+// the rest of the document.
+func (r *MongoRepository) SetStepArt(ctx context.Context, dungeonID, stepID string, art *models.Image, updatedAt time.Time) (models.BossStep, error) {
+	var out models.BossStep
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	err := r.db.Collection(stepsCollection).FindOneAndUpdate(cctx, bson.M{"_id": stepID, "dungeonId": dungeonID}, imageUpdate("art", art, updatedAt), options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&out)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return out, fmt.Errorf("step id %s: %w", stepID, apperrors.ErrNotFound)
+		}
+		return out, fmt.Errorf("set step art: %w", err)
+	}
+	return out, nil
+}
+
+func imageUpdate(field string, img *models.Image, updatedAt time.Time) bson.M {
+	if img == nil {
+		return bson.M{"$unset": bson.M{field: ""}, "$set": bson.M{"updatedAt": updatedAt}}
+	}
+	return bson.M{"$set": bson.M{field: img, "updatedAt": updatedAt}}
+}
+
 func (r *MongoRepository) GetStep(ctx context.Context, dungeonID, stepID string) (models.BossStep, error) {
 	var step models.BossStep
 	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
@@ -194,3 +457,20 @@ func (r *MongoRepository) ReorderSteps(ctx context.Context, dungeonID string, or
 	}
 	return nil
 }
+
+func (r *MongoRepository) UnsetTranslation(ctx context.Context, dungeonID, lang string, updatedAt time.Time) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	update := bson.M{"$unset": bson.M{"translations." + lang: ""}, "$set": bson.M{"updatedAt": updatedAt}}
+	res, err := r.db.Collection(dungeonsCollection).UpdateOne(cctx, bson.M{"_id": dungeonID}, update)
+	if err != nil {
+		return fmt.Errorf("unset dungeon translation: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("dungeon id %s: %w", dungeonID, apperrors.ErrNotFound)
+	}
+	if _, err := r.db.Collection(stepsCollection).UpdateMany(cctx, bson.M{"dungeonId": dungeonID}, update); err != nil {
+		return fmt.Errorf("unset step translations: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package mail
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/models"
+	"dungeons/app/mongodb"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const mailCollection = "mail"
+
+type MongoRepository struct {
+	db      *mongo.Database
+	timeout time.Duration
+}
+
+func NewMongoRepository(db *mongo.Database, timeout time.Duration) *MongoRepository {
+	return &MongoRepository{db: db, timeout: timeout}
+}
+
+func (r *MongoRepository) EnsureIndexes(ctx context.Context) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if _, err := r.db.Collection(mailCollection).Indexes().CreateMany(cctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "toId", Value: 1}, {Key: "status", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "fromId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "expiresAt", Value: 1}}},
+	}); err != nil {
+		return fmt.Errorf("mail indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) CreateMail(ctx context.Context, mail ...models.Mail) error {
+	if len(mail) == 0 {
+		return nil
+	}
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	docs := make([]any, 0, len(mail))
+	for _, m := range mail {
+		docs = append(docs, m)
+	}
+	if _, err := r.db.Collection(mailCollection).InsertMany(cctx, docs); err != nil {
+		return fmt.Errorf("insert mail: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) GetMail(ctx context.Context, id string) (models.Mail, error) {
+	var mail models.Mail
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if err := r.db.Collection(mailCollection).FindOne(cctx, bson.M{"_id": id}).Decode(&mail); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return mail, fmt.Errorf("mail id %s: %w", id, apperrors.ErrNotFound)
+		}
+		return mail, fmt.Errorf("find mail: %w", err)
+	}
+	return mail, nil
+}
+
+func (r *MongoRepository) MarkRead(ctx context.Context, id string, at time.Time) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if _, err := r.db.Collection(mailCollection).UpdateOne(cctx, bson.M{"_id": id, "readAt": nil}, bson.M{"$set": bson.M{"readAt": at}}); err != nil {
+		return fmt.Errorf("mark mail read: %w", err)
+	}
+	return nil
+}
+
+// CloseMail moves a mail out of sent. Only one of a claim and an expiry can
+// match, so the attachments move once.
+func (r *MongoRepository) CloseMail(ctx context.Context, id string, status models.MailStatus, at time.Time) (models.Mail, error) {
+	var out models.Mail
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	err := r.db.Collection(mailCollection).FindOneAndUpdate(cctx,
+		bson.M{"_id": id, "status": models.MailStatusSent},
+		bson.M{"$set": bson.M{"status": status, "closedAt": at}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&out)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return out, fmt.Errorf("mail id %s is already closed: %w", id, apperrors.ErrConflict)
+		}
+		return out, fmt.Errorf("close mail: %w", err)
+	}
+	return out, nil
+}
+
+// ListInbox returns the player's mail that has not expired or gone back to
+// its sender.
+func (r *MongoRepository) ListInbox(ctx context.Context, playerID string, now time.Time, params models.QueryParams) ([]models.Mail, error) {
+	q := params.Normalize()
+	filter := bson.M{
+		"toId":      playerID,
+		"status":    bson.M{"$in": bson.A{models.MailStatusSent, models.MailStatusClaimed}},
+		"expiresAt": bson.M{"$gt": now},
+	}
+	return r.findMail(ctx, filter, options.Find().SetSkip(q.Skip()).SetLimit(q.Limit).SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+}
+
+func (r *MongoRepository) ListSent(ctx context.Context, playerID string, params models.QueryParams) ([]models.Mail, error) {
+	q := params.Normalize()
+	return r.findMail(ctx, bson.M{"fromId": playerID}, options.Find().SetSkip(q.Skip()).SetLimit(q.Limit).SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+}
+
+func (r *MongoRepository) ListDueMail(ctx context.Context, now time.Time, limit int64) ([]models.Mail, error) {
+	filter := bson.M{"status": models.MailStatusSent, "expiresAt": bson.M{"$lte": now}}
+	return r.findMail(ctx, filter, options.Find().SetLimit(limit).SetSort(bson.D{{Key: "expiresAt", Value: 1}}))
+}
+
+func (r *MongoRepository) findMail(ctx context.Context, filter bson.M, opts *options.FindOptionsBuilder) ([]models.Mail, error) {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	cursor, err := r.db.Collection(mailCollection).Find(cctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("find mail: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	mail := make([]models.Mail, 0)
+	if err := cursor.All(cctx, &mail); err != nil {
+		return nil, fmt.Errorf("decode mail: %w", err)
+	}
+	return mail, nil
+}
@@ -0,0 +1,108 @@
+package offer
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/models"
+	"dungeons/app/mongodb"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const offersCollection = "trade_offers"
+
+type MongoRepository struct {
+	db      *mongo.Database
+	timeout time.Duration
+}
+
+func NewMongoRepository(db *mongo.Database, timeout time.Duration) *MongoRepository {
+	return &MongoRepository{db: db, timeout: timeout}
+}
+
+func (r *MongoRepository) EnsureIndexes(ctx context.Context) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if _, err := r.db.Collection(offersCollection).Indexes().CreateMany(cctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "toId", Value: 1}, {Key: "status", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "fromId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "expiresAt", Value: 1}}},
+	}); err != nil {
+		return fmt.Errorf("offer indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) CreateOffer(ctx context.Context, offer models.TradeOffer) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if _, err := r.db.Collection(offersCollection).InsertOne(cctx, offer); err != nil {
+		return fmt.Errorf("insert offer: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) GetOffer(ctx context.Context, id string) (models.TradeOffer, error) {
+	var offer models.TradeOffer
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if err := r.db.Collection(offersCollection).FindOne(cctx, bson.M{"_id": id}).Decode(&offer); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return offer, fmt.Errorf("offer id %s: %w", id, apperrors.ErrNotFound)
+		}
+		return offer, fmt.Errorf("find offer: %w", err)
+	}
+	return offer, nil
+}
+
+// ReplacePendingOffer only matches a pending offer, so two concurrent
+// answers to the same offer cannot both go through.
+func (r *MongoRepository) ReplacePendingOffer(ctx context.Context, offer models.TradeOffer) (models.TradeOffer, error) {
+	var out models.TradeOffer
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	err := r.db.Collection(offersCollection).FindOneAndReplace(cctx, bson.M{"_id": offer.ID, "status": models.OfferStatusPending}, offer, options.FindOneAndReplace().SetReturnDocument(options.After)).Decode(&out)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return out, fmt.Errorf("offer id %s is no longer pending: %w", offer.ID, apperrors.ErrConflict)
+		}
+		return out, fmt.Errorf("replace offer: %w", err)
+	}
+	return out, nil
+}
+
+func (r *MongoRepository) ListInbox(ctx context.Context, playerID string, now time.Time, params models.QueryParams) ([]models.TradeOffer, error) {
+	q := params.Normalize()
+	filter := bson.M{"toId": playerID, "status": models.OfferStatusPending, "expiresAt": bson.M{"$gt": now}}
+	return r.findOffers(ctx, filter, options.Find().SetSkip(q.Skip()).SetLimit(q.Limit).SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+}
+
+func (r *MongoRepository) ListSent(ctx context.Context, playerID string, params models.QueryParams) ([]models.TradeOffer, error) {
+	q := params.Normalize()
+	return r.findOffers(ctx, bson.M{"fromId": playerID}, options.Find().SetSkip(q.Skip()).SetLimit(q.Limit).SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+}
+
+func (r *MongoRepository) ListDueOffers(ctx context.Context, now time.Time, limit int64) ([]models.TradeOffer, error) {
+	filter := bson.M{"status": models.OfferStatusPending, "expiresAt": bson.M{"$lte": now}}
+	return r.findOffers(ctx, filter, options.Find().SetLimit(limit).SetSort(bson.D{{Key: "expiresAt", Value: 1}}))
+}
+
+func (r *MongoRepository) findOffers(ctx context.Context, filter bson.M, opts *options.FindOptionsBuilder) ([]models.TradeOffer, error) {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	cursor, err := r.db.Collection(offersCollection).Find(cctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("find offers: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	offers := make([]models.TradeOffer, 0)
+	if err := cursor.All(cctx, &offers); err != nil {
+		return nil, fmt.Errorf("decode offers: %w", err)
+	}
+	return offers, nil
+}
@@ -3,8 +3,10 @@ package player
 import (
 	"context"
 	apperrors "dungeons/app/errors"
+	"dungeons/app/functions"
 	"dungeons/app/models"
 	"dungeons/app/mongodb"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,7 +15,10 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
-const collectionName = "players"
+const (
+	collectionName   = "players"
+	ledgerCollection = "gold_ledger"
+)
 
 type MongoRepository struct {
 	db      *mongo.Database
@@ -35,6 +40,14 @@ func (r *MongoRepository) EnsureIndexes(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("ensure player indexes: %w", err)
 	}
+	_, err = r.db.Collection(ledgerCollection).Indexes().CreateMany(cctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "account", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "transferId", Value: 1}}},
+		{Keys: bson.D{{Key: "ref", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("ensure ledger indexes: %w", err)
+	}
 	return nil
 }
 
@@ -122,42 +135,121 @@ func (r *MongoRepository) UpdateDisplayName(ctx context.Context, id, displayName
 	return updated, nil
 }
 
-func (r *MongoRepository) IncrementGold(ctx context.Context, id string, delta int64, updatedAt time.Time) (models.Player, error) {
-	var updated models.Player
+// Transfer moves gold between two ledger accounts and records both sides
+// in the ledger. Player balances change through conditional $inc only: a
+// debit never takes a player below zero (ErrInsufficient). It joins the
+// transaction carried by ctx, or runs in its own. A zero amount is a no-op
+// and a negative one is rejected.
+func (r *MongoRepository) Transfer(ctx context.Context, t models.GoldTransfer, at time.Time) error {
+	if t.Amount < 0 {
+		return fmt.Errorf("transfer amount %d is negative: %w", t.Amount, apperrors.ErrValidation)
+	}
+	if t.Amount == 0 {
+		return nil
+	}
+	return mongodb.InTransaction(ctx, r.db.Client(), func(txCtx context.Context) error {
+		if playerID, ok := models.AccountPlayer(t.From); ok {
+			if err := r.moveGold(txCtx, bson.M{"customID": playerID, "gold": bson.M{"$gte": t.Amount}}, -t.Amount, at); err != nil {
+				if errors.Is(err, apperrors.ErrNotFound) {
+					return fmt.Errorf("player id %s cannot pay %d: %w", playerID, t.Amount, apperrors.ErrInsufficient)
+				}
+				return err
+			}
+		}
+		if playerID, ok := models.AccountPlayer(t.To); ok {
+			if err := r.moveGold(txCtx, bson.M{"customID": playerID}, t.Amount, at); err != nil {
+				return fmt.Errorf("player id %s: %w", playerID, err)
+			}
+		}
+		return r.writeEntries(txCtx, functions.NewUUID(), t, at)
+	})
+}
+
+func (r *MongoRepository) moveGold(ctx context.Context, filter bson.M, delta int64, at time.Time) error {
 	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
 	defer cancel()
-
-	err := r.db.Collection(collectionName).FindOneAndUpdate(
-		cctx,
-		bson.M{"customID": id},
-		bson.M{"$inc": bson.M{"gold": delta}, "$set": bson.M{"updated_at": updatedAt}},
-		options.FindOneAndUpdate().SetReturnDocument(options.After),
-	).Decode(&updated)
+	res, err := r.db.Collection(collectionName).UpdateOne(cctx, filter, bson.M{"$inc": bson.M{"gold": delta}, "$set": bson.M{"updated_at": at}})
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return updated, fmt.Errorf("player id %s: %w", id, apperrors.ErrNotFound)
+		return fmt.Errorf("update gold: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoRepository) writeEntries(ctx context.Context, transferID string, t models.GoldTransfer, at time.Time) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	entries := []models.LedgerEntry{
+		{ID: transferID + ":from", TransferID: transferID, Account: t.From, Amount: -t.Amount, Reason: t.Reason, Ref: t.Ref, Memo: t.Memo, CreatedAt: at},
+		{ID: transferID + ":to", TransferID: transferID, Account: t.To, Amount: t.Amount, Reason: t.Reason, Ref: t.Ref, Memo: t.Memo, CreatedAt: at},
+	}
+	if _, err := r.db.Collection(ledgerCollection).InsertMany(cctx, entries); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("ledger transfer %s: %w", transferID, apperrors.ErrConflict)
 		}
-		return updated, fmt.Errorf("increment gold: %w", err)
+		return fmt.Errorf("insert ledger entries: %w", err)
 	}
-	return updated, nil
+	return nil
 }
 
-func (r *MongoRepository) SetGold(ctx context.Context, id string, gold int64, updatedAt time.Time) (models.Player, error) {
-	var updated models.Player
+// RecordOpening books a balance that predates the ledger as minted gold,
+// without touching the balance itself. It is recorded at most once per
+// player.
+func (r *MongoRepository) RecordOpening(ctx context.Context, playerID string, amount int64, at time.Time) error {
+	t := models.GoldTransfer{From: models.AccountMint, To: models.PlayerAccount(playerID), Amount: amount, Reason: models.LedgerReasonOpening}
+	return r.writeEntries(ctx, "opening:"+playerID, t, at)
+}
+
+// LedgerBalances sums the ledger by account.
+func (r *MongoRepository) LedgerBalances(ctx context.Context) (map[string]int64, error) {
 	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
 	defer cancel()
+	cursor, err := r.db.Collection(ledgerCollection).Aggregate(cctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": "$account", "balance": bson.M{"$sum": "$amount"}}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("aggregate ledger balances: %w", err)
+	}
+	defer cursor.Close(cctx)
 
-	err := r.db.Collection(collectionName).FindOneAndUpdate(
-		cctx,
-		bson.M{"customID": id},
-		bson.M{"$set": bson.M{"gold": gold, "updated_at": updatedAt}},
-		options.FindOneAndUpdate().SetReturnDocument(options.After),
-	).Decode(&updated)
+	var rows []struct {
+		Account string `bson:"_id"`
+		Balance int64  `bson:"balance"`
+	}
+	if err := cursor.All(cctx, &rows); err != nil {
+		return nil, fmt.Errorf("decode ledger balances: %w", err)
+	}
+	balances := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		balances[row.Account] = row.Balance
+	}
+	return balances, nil
+}
+
+// UnbalancedTransfers lists transfers whose entries do not sum to zero.
+func (r *MongoRepository) UnbalancedTransfers(ctx context.Context) ([]string, error) {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	cursor, err := r.db.Collection(ledgerCollection).Aggregate(cctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": "$transferId", "sum": bson.M{"$sum": "$amount"}}}},
+		{{Key: "$match", Value: bson.M{"sum": bson.M{"$ne": 0}}}},
+	})
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return updated, fmt.Errorf("player id %s: %w", id, apperrors.ErrNotFound)
-		}
-		return updated, fmt.Errorf("set gold: %w", err)
+		return nil, fmt.Errorf("aggregate ledger transfers: %w", err)
 	}
-	return updated, nil
+	defer cursor.Close(cctx)
+
+	var rows []struct {
+		TransferID string `bson:"_id"`
+	}
+	if err := cursor.All(cctx, &rows); err != nil {
+		return nil, fmt.Errorf("decode ledger transfers: %w", err)
+	}
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.TransferID)
+	}
+	return ids, nil
 }
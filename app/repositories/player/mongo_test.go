@@ -0,0 +1,26 @@
+package player
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/models"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTransferRejectsNegativeAmountAndSkipsZero(t *testing.T) {
+	// No database: both cases must return before touching it.
+	r := &MongoRepository{}
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	escrow := models.GoldTransfer{From: models.PlayerAccount("p1"), To: models.AccountEscrow, Reason: models.LedgerReasonEscrow}
+
+	escrow.Amount = -5
+	if err := r.Transfer(context.Background(), escrow, t0); !errors.Is(err, apperrors.ErrValidation) {
+		t.Fatalf("Transfer(-5) = %v, want validation error", err)
+	}
+	escrow.Amount = 0
+	if err := r.Transfer(context.Background(), escrow, t0); err != nil {
+		t.Fatalf("Transfer(0) = %v, want no-op", err)
+	}
+}
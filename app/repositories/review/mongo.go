@@ -0,0 +1,138 @@
+package review
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/models"
+	"dungeons/app/mongodb"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const reviewsCollection = "reviews"
+
+type MongoRepository struct {
+	db      *mongo.Database
+	timeout time.Duration
+}
+
+func NewMongoRepository(db *mongo.Database, timeout time.Duration) *MongoRepository {
+	return &MongoRepository{db: db, timeout: timeout}
+}
+
+func (r *MongoRepository) EnsureIndexes(ctx context.Context) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if _, err := r.db.Collection(reviewsCollection).Indexes().CreateMany(cctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "dungeonId", Value: 1}, {Key: "playerId", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "dungeonId", Value: 1}, {Key: "hidden", Value: 1}, {Key: "updatedAt", Value: -1}}},
+	}); err != nil {
+		return fmt.Errorf("review indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) CreateReview(ctx context.Context, review models.Review) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if _, err := r.db.Collection(reviewsCollection).InsertOne(cctx, review); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("review already exists: %w", apperrors.ErrConflict)
+		}
+		return fmt.Errorf("insert review: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) GetReviewByID(ctx context.Context, id string) (models.Review, error) {
+	var review models.Review
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if err := r.db.Collection(reviewsCollection).FindOne(cctx, bson.M{"_id": id}).Decode(&review); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return review, fmt.Errorf("review id %s: %w", id, apperrors.ErrNotFound)
+		}
+		return review, fmt.Errorf("find review: %w", err)
+	}
+	return review, nil
+}
+
+func (r *MongoRepository) GetReviewByPlayer(ctx context.Context, dungeonID, playerID string) (models.Review, error) {
+	var review models.Review
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if err := r.db.Collection(reviewsCollection).FindOne(cctx, bson.M{"dungeonId": dungeonID, "playerId": playerID}).Decode(&review); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return review, fmt.Errorf("review for player %s: %w", playerID, apperrors.ErrNotFound)
+		}
+		return review, fmt.Errorf("find player review: %w", err)
+	}
+	return review, nil
+}
+
+func (r *MongoRepository) ReplaceReview(ctx context.Context, review models.Review) (models.Review, error) {
+	var out models.Review
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	err := r.db.Collection(reviewsCollection).FindOneAndReplace(cctx, bson.M{"_id": review.ID}, review, options.FindOneAndReplace().SetReturnDocument(options.After)).Decode(&out)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return out, fmt.Errorf("review id %s: %w", review.ID, apperrors.ErrNotFound)
+		}
+		return out, fmt.Errorf("replace review: %w", err)
+	}
+	return out, nil
+}
+
+func (r *MongoRepository) ListByDungeon(ctx context.Context, dungeonID string, params models.QueryParams) ([]models.Review, error) {
+	q := params.Normalize()
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	cursor, err := r.db.Collection(reviewsCollection).Find(cctx, bson.M{"dungeonId": dungeonID, "hidden": false}, options.Find().SetSkip(q.Skip()).SetLimit(q.Limit).SetSort(bson.D{{Key: "updatedAt", Value: -1}}))
+	if err != nil {
+		return nil, fmt.Errorf("list reviews: %w", err)
+	}
+	defer cursor.Close(cctx)
+
+	reviews := make([]models.Review, 0)
+	for cursor.Next(cctx) {
+		var review models.Review
+		if err := cursor.Decode(&review); err != nil {
+			return nil, fmt.Errorf("decode review: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("reviews cursor: %w", err)
+	}
+	return reviews, nil
+}
+
+func (r *MongoRepository) RatingSummary(ctx context.Context, dungeonID string) (models.DungeonRating, error) {
+	var out models.DungeonRating
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	cursor, err := r.db.Collection(reviewsCollection).Aggregate(cctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"dungeonId": dungeonID, "hidden": false}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "average": bson.M{"$avg": "$rating"}, "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return out, fmt.Errorf("aggregate rating: %w", err)
+	}
+	defer cursor.Close(cctx)
+	if cursor.Next(cctx) {
+		if err := cursor.Decode(&out); err != nil {
+			return out, fmt.Errorf("decode rating: %w", err)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return out, fmt.Errorf("rating cursor: %w", err)
+	}
+	return out, nil
+}
@@ -14,8 +14,10 @@ import (
 )
 
 const (
-	runsCollection     = "runs"
-	attemptsCollection = "attempts"
+	runsCollection        = "runs"
+	attemptsCollection    = "attempts"
+	sandboxRunsCollection = "sandbox_runs"
+	photosCollection      = "photo_submissions"
 )
 
 type MongoRepository struct {
@@ -50,6 +52,24 @@ func (r *MongoRepository) EnsureIndexes(ctx context.Context) error {
 	}); err != nil {
 		return fmt.Errorf("attempt indexes: %w", err)
 	}
+
+	if _, err := r.db.Collection(sandboxRunsCollection).Indexes().CreateMany(cctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "playerId", Value: 1}, {Key: "dungeonId", Value: 1}}},
+	}); err != nil {
+		return fmt.Errorf("sandbox run indexes: %w", err)
+	}
+
+	if _, err := r.db.Collection(photosCollection).Indexes().CreateMany(cctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "runId", Value: 1}, {Key: "stepId", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": models.PhotoStatusPending}),
+		},
+		{Keys: bson.D{{Key: "dungeonId", Value: 1}, {Key: "status", Value: 1}, {Key: "createdAt", Value: 1}}},
+	}); err != nil {
+		return fmt.Errorf("photo submission indexes: %w", err)
+	}
 	return nil
 }
 
@@ -80,6 +100,20 @@ func (r *MongoRepository) HasActiveRun(ctx context.Context, playerID, dungeonID
 	return count > 0, nil
 }
 
+func (r *MongoRepository) HasCompletedRun(ctx context.Context, playerID, dungeonID string) (bool, error) {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	count, err := r.db.Collection(runsCollection).CountDocuments(cctx, bson.M{
+		"playerId":  playerID,
+		"dungeonId": dungeonID,
+		"state":     models.RunStateCompleted,
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, fmt.Errorf("count completed runs: %w", err)
+	}
+	return count > 0, nil
+}
+
 func (r *MongoRepository) GetRunByID(ctx context.Context, id string) (models.Run, error) {
 	var run models.Run
 	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
@@ -132,6 +166,44 @@ func (r *MongoRepository) ReplaceRun(ctx context.Context, run models.Run) (model
 	return out, nil
 }
 
+// IncrementStepTries reserves one try on the step. With maxTries > 0 the
+// filter refuses the increment once the budget is spent.
+func (r *MongoRepository) IncrementStepTries(ctx context.Context, runID, stepID string, maxTries int) (models.Run, error) {
+	var out models.Run
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	field := "tries." + stepID
+	filter := bson.M{"_id": runID, "state": models.RunStateActive}
+	if maxTries > 0 {
+		filter["$or"] = bson.A{
+			bson.M{field: bson.M{"$exists": false}},
+			bson.M{field: bson.M{"$lt": maxTries}},
+		}
+	}
+	err := r.db.Collection(runsCollection).FindOneAndUpdate(cctx, filter, bson.M{"$inc": bson.M{field: 1}}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&out)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return out, fmt.Errorf("run id %s step %s: %w", runID, stepID, apperrors.ErrNoTriesLeft)
+		}
+		return out, fmt.Errorf("increment step tries: %w", err)
+	}
+	return out, nil
+}
+
+func (r *MongoRepository) AddHintUsed(ctx context.Context, runID, stepID string, index int) (bool, error) {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	field := "hints." + stepID
+	res, err := r.db.Collection(runsCollection).UpdateOne(cctx,
+		bson.M{"_id": runID, field: bson.M{"$ne": index}},
+		bson.M{"$addToSet": bson.M{field: index}},
+	)
+	if err != nil {
+		return false, fmt.Errorf("add hint used: %w", err)
+	}
+	return res.ModifiedCount == 1, nil
+}
+
 func (r *MongoRepository) CreateAttemptRecord(ctx context.Context, record models.AttemptRecord) error {
 	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
 	defer cancel()
@@ -174,3 +246,112 @@ func (r *MongoRepository) UpdateAttemptRecord(ctx context.Context, id string, re
 	}
 	return nil
 }
+
+func (r *MongoRepository) CreateSandboxRun(ctx context.Context, run models.Run) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if _, err := r.db.Collection(sandboxRunsCollection).InsertOne(cctx, run); err != nil {
+		return fmt.Errorf("insert sandbox run: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) GetSandboxRunByID(ctx context.Context, id string) (models.Run, error) {
+	var run models.Run
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if err := r.db.Collection(sandboxRunsCollection).FindOne(cctx, bson.M{"_id": id}).Decode(&run); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return run, fmt.Errorf("sandbox run id %s: %w", id, apperrors.ErrNotFound)
+		}
+		return run, fmt.Errorf("find sandbox run: %w", err)
+	}
+	return run, nil
+}
+
+func (r *MongoRepository) ReplaceSandboxRun(ctx context.Context, run models.Run) (models.Run, error) {
+	var out models.Run
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	err := r.db.Collection(sandboxRunsCollection).FindOneAndReplace(cctx, bson.M{"_id": run.ID}, run, options.FindOneAndReplace().SetReturnDocument(options.After)).Decode(&out)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return out, fmt.Errorf("sandbox run id %s: %w", run.ID, apperrors.ErrNotFound)
+		}
+		return out, fmt.Errorf("replace sandbox run: %w", err)
+	}
+	return out, nil
+}
+
+func (r *MongoRepository) SetRunPending(ctx context.Context, runID string, pending *models.PendingProof, updatedAt time.Time) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	update := bson.M{"$set": bson.M{"pending": pending, "updatedAt": updatedAt}}
+	if pending == nil {
+		update = bson.M{"$unset": bson.M{"pending": ""}, "$set": bson.M{"updatedAt": updatedAt}}
+	}
+	res, err := r.db.Collection(runsCollection).UpdateOne(cctx, bson.M{"_id": runID}, update)
+	if err != nil {
+		return fmt.Errorf("set run pending: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("run id %s: %w", runID, apperrors.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *MongoRepository) CreatePhotoSubmission(ctx context.Context, sub models.PhotoSubmission) error {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if _, err := r.db.Collection(photosCollection).InsertOne(cctx, sub); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("a photo is already pending for this step: %w", apperrors.ErrConflict)
+		}
+		return fmt.Errorf("insert photo submission: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) GetPhotoSubmission(ctx context.Context, id string) (models.PhotoSubmission, error) {
+	var sub models.PhotoSubmission
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if err := r.db.Collection(photosCollection).FindOne(cctx, bson.M{"_id": id}).Decode(&sub); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return sub, fmt.Errorf("photo submission id %s: %w", id, apperrors.ErrNotFound)
+		}
+		return sub, fmt.Errorf("find photo submission: %w", err)
+	}
+	return sub, nil
+}
+
+func (r *MongoRepository) ListPhotoSubmissions(ctx context.Context, dungeonID string, status models.PhotoStatus, params models.QueryParams) ([]models.PhotoSubmission, error) {
+	q := params.Normalize()
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	cursor, err := r.db.Collection(photosCollection).Find(cctx,
+		bson.M{"dungeonId": dungeonID, "status": status},
+		options.Find().SetSkip(q.Skip()).SetLimit(q.Limit).SetSort(bson.D{{Key: "createdAt", Value: 1}}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list photo submissions: %w", err)
+	}
+	defer cursor.Close(cctx)
+	out := make([]models.PhotoSubmission, 0)
+	if err := cursor.All(cctx, &out); err != nil {
+		return nil, fmt.Errorf("decode photo submissions: %w", err)
+	}
+	return out, nil
+}
+
+// ResolvePhotoSubmission moves a pending submission to its final status. It
+// reports false when someone else already reviewed it.
+func (r *MongoRepository) ResolvePhotoSubmission(ctx context.Context, sub models.PhotoSubmission) (bool, error) {
+	cctx, cancel := mongodb.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	res, err := r.db.Collection(photosCollection).ReplaceOne(cctx, bson.M{"_id": sub.ID, "status": models.PhotoStatusPending}, sub)
+	if err != nil {
+		return false, fmt.Errorf("resolve photo submission: %w", err)
+	}
+	return res.ModifiedCount == 1, nil
+}
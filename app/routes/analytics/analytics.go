@@ -0,0 +1,16 @@
+package analytics
+
+import (
+	"dungeons/app/auth"
+	controller "dungeons/app/controllers/analytics"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRouter(v1 *gin.RouterGroup, handler *controller.Handler, authMiddleware gin.HandlerFunc) {
+	mj := v1.Group("/mj")
+	mj.Use(authMiddleware, auth.RequireRole("mj"))
+	{
+		mj.GET("/dungeons/:id/stats", handler.DungeonStats)
+	}
+}
@@ -1,6 +1,7 @@
 package auction
 
 import (
+	"dungeons/app/auth"
 	controller "dungeons/app/controllers/auction"
 
 	"github.com/gin-gonic/gin"
@@ -11,7 +12,28 @@ func SetupRouter(v1 *gin.RouterGroup, handler *controller.Handler, authMiddlewar
 	{
 		group.GET("/listings", handler.ListActive)
 		group.POST("/listings", authMiddleware, handler.CreateListing)
+		group.GET("/listings/mine", authMiddleware, handler.MyListings)
 		group.POST("/listings/:id/buy", authMiddleware, handler.Buy)
 		group.POST("/listings/:id/cancel", authMiddleware, handler.Cancel)
+		group.GET("/auctions", handler.ListAuctions)
+		group.POST("/auctions", authMiddleware, handler.CreateAuction)
+		group.GET("/auctions/:id", handler.GetAuction)
+		group.GET("/auctions/:id/bids", handler.ListBids)
+		group.POST("/auctions/:id/bids", authMiddleware, handler.PlaceBid)
+		group.GET("/bids/mine", authMiddleware, handler.MyBids)
+		group.GET("/trades/mine", authMiddleware, handler.MyTrades)
+		group.POST("/orders", authMiddleware, handler.CreateBuyOrder)
+		group.GET("/orders/mine", authMiddleware, handler.MyBuyOrders)
+		group.POST("/orders/:id/cancel", authMiddleware, handler.CancelBuyOrder)
+		group.GET("/items/:itemId/book", handler.OrderBook)
+		group.GET("/items/:itemId/history", handler.ItemHistory)
+	}
+
+	admin := v1.Group("/admin/auction")
+	admin.Use(authMiddleware, auth.RequireRole("admin"))
+	{
+		admin.POST("/expire", handler.ExpireListings)
+		admin.POST("/settle", handler.SettleAuctions)
+		admin.GET("/treasury", handler.Treasury)
 	}
 }
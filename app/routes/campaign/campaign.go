@@ -0,0 +1,27 @@
+package campaign
+
+import (
+	"dungeons/app/auth"
+	controller "dungeons/app/controllers/campaign"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRouter(v1 *gin.RouterGroup, handler *controller.Handler, authMiddleware gin.HandlerFunc) {
+	campaigns := v1.Group("/campaigns")
+	campaigns.Use(authMiddleware)
+	{
+		campaigns.GET("", handler.ListPublished)
+		campaigns.GET("/:id", handler.Progress)
+	}
+
+	mj := v1.Group("/mj/campaigns")
+	mj.Use(authMiddleware, auth.RequireRole("mj"))
+	{
+		mj.GET("", handler.ListMine)
+		mj.POST("", handler.Create)
+		mj.PUT("/:id", handler.Update)
+		mj.POST("/:id/publish", handler.Publish)
+		mj.POST("/:id/unpublish", handler.Unpublish)
+	}
+}
@@ -13,15 +13,45 @@ func SetupRouter(v1 *gin.RouterGroup, handler *controller.Handler, authMiddlewar
 	{
 		dungeons := mj.Group("/dungeons")
 		{
+			dungeons.GET("", handler.ListMine)
 			dungeons.POST("", handler.CreateDungeon)
 			dungeons.PUT("/:id", handler.UpdateDungeon)
 			dungeons.POST("/:id/publish", handler.PublishDungeon)
+			dungeons.PUT("/:id/schedule", handler.ScheduleDungeon)
+			dungeons.PUT("/:id/cover", handler.SetCover)
+			dungeons.DELETE("/:id/cover", handler.RemoveCover)
 			dungeons.POST("/:id/steps", handler.CreateStep)
 			dungeons.PUT("/:id/steps/:stepId", handler.UpdateStep)
 			dungeons.PUT("/:id/steps/reorder", handler.ReorderSteps)
+			dungeons.GET("/:id/translations", handler.TranslationReport)
+			dungeons.PUT("/:id/translations/:lang", handler.SetDungeonTranslation)
+			dungeons.DELETE("/:id/translations/:lang", handler.RemoveTranslation)
+			dungeons.PUT("/:id/steps/:stepId/translations/:lang", handler.SetStepTranslation)
+			dungeons.PUT("/:id/steps/:stepId/proof", handler.SetProofPolicy)
+			dungeons.POST("/:id/steps/:stepId/proof/rotate", handler.RotateProofCode)
+			dungeons.DELETE("/:id/steps/:stepId/proof", handler.RevokeProofCode)
+			dungeons.PUT("/:id/steps/:stepId/art", handler.SetStepArt)
+			dungeons.DELETE("/:id/steps/:stepId/art", handler.RemoveStepArt)
+			dungeons.GET("/:id/codes", handler.CodeSheet)
+			dungeons.GET("/:id/route", handler.DungeonRoute)
+			dungeons.POST("/:id/clone", handler.CloneDungeon)
+			dungeons.POST("/:id/template", handler.MarkTemplate)
+			dungeons.DELETE("/:id/template", handler.UnmarkTemplate)
+			dungeons.GET("/:id/collaborators", handler.ListCollaborators)
+			dungeons.POST("/:id/collaborators", handler.AddCollaborator)
+			dungeons.DELETE("/:id/collaborators/:playerId", handler.RemoveCollaborator)
+			dungeons.POST("/:id/transfer", handler.TransferOwnership)
+		}
+
+		templates := mj.Group("/templates")
+		{
+			templates.GET("", handler.ListTemplates)
+			templates.POST("/:id/instantiate", handler.InstantiateTemplate)
 		}
 	}
 
 	v1.GET("/dungeons", handler.ListPublished)
+	v1.GET("/dungeons/upcoming", handler.ListUpcoming)
 	v1.GET("/dungeons/:id", handler.GetPublished)
+	v1.GET("/dungeons/:id/map.geojson", handler.MapGeoJSON)
 }
@@ -0,0 +1,27 @@
+package mail
+
+import (
+	"dungeons/app/auth"
+	controller "dungeons/app/controllers/mail"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRouter(v1 *gin.RouterGroup, handler *controller.Handler, authMiddleware gin.HandlerFunc) {
+	mail := v1.Group("/mail")
+	mail.Use(authMiddleware)
+	{
+		mail.GET("", handler.Inbox)
+		mail.POST("", handler.Send)
+		mail.GET("/sent", handler.Sent)
+		mail.GET("/:id", handler.Get)
+		mail.POST("/:id/claim", handler.Claim)
+	}
+
+	admin := v1.Group("/admin/mail")
+	admin.Use(authMiddleware, auth.RequireRole("admin"))
+	{
+		admin.POST("", handler.SendSystem)
+		admin.POST("/expire", handler.ExpireMail)
+	}
+}
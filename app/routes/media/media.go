@@ -0,0 +1,11 @@
+package media
+
+import (
+	controller "dungeons/app/controllers/media"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRouter(v1 *gin.RouterGroup, handler *controller.Handler) {
+	v1.GET("/media/:name", handler.Get)
+}
@@ -0,0 +1,29 @@
+package offer
+
+import (
+	"dungeons/app/auth"
+	controller "dungeons/app/controllers/offer"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRouter(v1 *gin.RouterGroup, handler *controller.Handler, authMiddleware gin.HandlerFunc) {
+	offers := v1.Group("/offers")
+	offers.Use(authMiddleware)
+	{
+		offers.POST("", handler.Create)
+		offers.GET("/inbox", handler.Inbox)
+		offers.GET("/sent", handler.Sent)
+		offers.GET("/:id", handler.Get)
+		offers.POST("/:id/accept", handler.Accept)
+		offers.POST("/:id/decline", handler.Decline)
+		offers.POST("/:id/counter", handler.Counter)
+		offers.POST("/:id/cancel", handler.Cancel)
+	}
+
+	admin := v1.Group("/admin/offers")
+	admin.Use(authMiddleware, auth.RequireRole("admin"))
+	{
+		admin.POST("/expire", handler.ExpireOffers)
+	}
+}
@@ -23,4 +23,10 @@ func SetupRouter(v1 *gin.RouterGroup, handler *controller.Handler, authMiddlewar
 		players.GET("/:id", handler.GetByID)
 		players.PUT("/:id", handler.Update)
 	}
+
+	admin := v1.Group("/admin/players")
+	admin.Use(authMiddleware, auth.RequireRole("admin"))
+	{
+		admin.POST("/:id/gold", handler.GrantGold)
+	}
 }
@@ -0,0 +1,25 @@
+package review
+
+import (
+	"dungeons/app/auth"
+	controller "dungeons/app/controllers/review"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRouter(v1 *gin.RouterGroup, handler *controller.Handler, authMiddleware gin.HandlerFunc) {
+	v1.GET("/dungeons/:id/reviews", handler.List)
+	v1.PUT("/dungeons/:id/review", authMiddleware, handler.Upsert)
+
+	mj := v1.Group("/mj")
+	mj.Use(authMiddleware, auth.RequireRole("mj"))
+	{
+		mj.POST("/dungeons/:id/reviews/:reviewId/reply", handler.Reply)
+	}
+
+	admin := v1.Group("/admin")
+	admin.Use(authMiddleware, auth.RequireRole("admin"))
+	{
+		admin.POST("/reviews/:id/moderate", handler.Moderate)
+	}
+}
@@ -1,6 +1,7 @@
 package run
 
 import (
+	"dungeons/app/auth"
 	controller "dungeons/app/controllers/run"
 
 	"github.com/gin-gonic/gin"
@@ -13,6 +14,22 @@ func SetupRouter(v1 *gin.RouterGroup, handler *controller.Handler, authMiddlewar
 		runs.POST("", handler.Start)
 		runs.GET("", handler.List)
 		runs.GET("/:id", handler.Get)
+		runs.GET("/:id/track.geojson", handler.TrackGeoJSON)
+		runs.GET("/:id/track.gpx", handler.TrackGPX)
 		runs.POST("/:id/steps/:stepId/attempt", handler.Attempt)
+		runs.POST("/:id/steps/:stepId/hints/:index", handler.BuyHint)
+		runs.POST("/:id/steps/:stepId/photo", handler.SubmitPhoto)
+	}
+
+	mj := v1.Group("/mj")
+	mj.Use(authMiddleware, auth.RequireRole("mj"))
+	{
+		mj.POST("/dungeons/:id/dry-runs", handler.StartDryRun)
+		mj.GET("/dry-runs/:id", handler.GetDryRun)
+		mj.POST("/dry-runs/:id/steps/:stepId/attempt", handler.DryRunAttempt)
+		mj.GET("/dungeons/:id/review-queue", handler.ReviewQueue)
+		mj.GET("/photo-submissions/:id/photo", handler.SubmissionPhoto)
+		mj.POST("/photo-submissions/:id/approve", handler.ApprovePhoto)
+		mj.POST("/photo-submissions/:id/reject", handler.RejectPhoto)
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"dungeons/app/models"
 	"dungeons/app/mongodb"
+	playerrepo "dungeons/app/repositories/player"
 	"fmt"
 	"time"
 
@@ -26,6 +27,10 @@ func Run(ctx context.Context, db *mongo.Database, timeout time.Duration) error {
 	if err != nil {
 		return fmt.Errorf("hash seed player password: %w", err)
 	}
+	hashAdmin, err := bcrypt.GenerateFromPassword([]byte("Password123!"), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("hash seed admin password: %w", err)
+	}
 
 	players := []models.Player{
 		{
@@ -48,12 +53,39 @@ func Run(ctx context.Context, db *mongo.Database, timeout time.Duration) error {
 			PasswordHash: string(hashPlayer),
 			Role:         models.RolePlayer,
 		},
+		{
+			ID:           "seed-admin",
+			DisplayName:  "Seed Admin",
+			Gold:         0,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			Email:        "admin@seed.local",
+			PasswordHash: string(hashAdmin),
+			Role:         models.RoleAdmin,
+		},
 	}
+	// Gold is only set when a seed player is first created, together with
+	// its opening ledger entry, so re-seeding never rewrites balances.
+	ledger := playerrepo.NewMongoRepository(db, timeout)
 	for _, p := range players {
-		_, err := db.Collection("players").UpdateOne(cctx, bson.M{"customID": p.ID}, bson.M{"$set": p}, options.UpdateOne().SetUpsert(true))
+		raw, err := bson.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("encode seed player %s: %w", p.ID, err)
+		}
+		fields := bson.M{}
+		if err := bson.Unmarshal(raw, &fields); err != nil {
+			return fmt.Errorf("encode seed player %s: %w", p.ID, err)
+		}
+		delete(fields, "gold")
+		res, err := db.Collection("players").UpdateOne(cctx, bson.M{"customID": p.ID}, bson.M{"$set": fields, "$setOnInsert": bson.M{"gold": p.Gold}}, options.UpdateOne().SetUpsert(true))
 		if err != nil {
 			return fmt.Errorf("upsert seed player %s: %w", p.ID, err)
 		}
+		if res.UpsertedCount == 1 && p.Gold > 0 {
+			if err := ledger.RecordOpening(cctx, p.ID, p.Gold, now); err != nil {
+				return fmt.Errorf("record seed player %s opening balance: %w", p.ID, err)
+			}
+		}
 	}
 
 	items := []models.ItemDef{
@@ -104,11 +136,16 @@ func Run(ctx context.Context, db *mongo.Database, timeout time.Duration) error {
 		ID:          "seed-dungeon-1",
 		Title:       "Seed Dungeon",
 		Description: "Starter published dungeon",
-		CreatedBy:   "seed-mj",
-		AreaName:    "Paris Center",
-		Status:      models.DungeonStatusPublished,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		DefaultLang: "en",
+		Translations: map[string]models.DungeonTranslation{
+			"fr": {Title: "Donjon de départ", Description: "Premier donjon publié"},
+		},
+		CreatedBy: "seed-mj",
+		AreaName:  "Paris Center",
+		Status:    models.DungeonStatusPublished,
+		Stats:     models.DungeonStats{StepCount: 2, AvgDifficulty: 3, TotalRewardGold: 170},
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 	if _, err := db.Collection("dungeons").UpdateOne(cctx, bson.M{"_id": dungeon.ID}, bson.M{"$set": dungeon}, options.UpdateOne().SetUpsert(true)); err != nil {
 		return fmt.Errorf("upsert seed dungeon: %w", err)
@@ -122,6 +159,7 @@ func Run(ctx context.Context, db *mongo.Database, timeout time.Duration) error {
 			Name:            "Gatekeeper",
 			Location:        models.BossLocation{Lat: 48.8566, Lon: 2.3522, RadiusMeters: 80},
 			ZoneDescription: "Near city hall",
+			Translations:    map[string]models.StepTranslation{"fr": {Name: "Gardien de la porte", ZoneDescription: "Près de l'hôtel de ville"}},
 			Difficulty:      2,
 			Rewards:         models.Rewards{Gold: 50, Items: []models.RewardItem{{ItemID: "seed-item-potion", Qty: 1}}},
 			CreatedAt:       now,
@@ -134,6 +172,7 @@ func Run(ctx context.Context, db *mongo.Database, timeout time.Duration) error {
 			Name:            "Catacomb Guardian",
 			Location:        models.BossLocation{Lat: 48.8570, Lon: 2.3530, RadiusMeters: 120},
 			ZoneDescription: "Second checkpoint",
+			Translations:    map[string]models.StepTranslation{"fr": {Name: "Gardien des catacombes", ZoneDescription: "Deuxième point de passage"}},
 			Difficulty:      4,
 			Rewards:         models.Rewards{Gold: 120, Items: []models.RewardItem{{ItemID: "seed-item-sword", Qty: 1}}},
 			CreatedAt:       now,
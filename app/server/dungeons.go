@@ -1,6 +1,8 @@
 package server
 
 import (
+	"dungeons/app/models"
+	"encoding/json"
 	"net/http"
 	"os"
 	"strconv"
@@ -30,6 +32,13 @@ type Dungeons struct {
 	DBTimeout  time.Duration
 	TokenTTL   time.Duration
 	SeedOnBoot bool
+
+	ScheduleInterval time.Duration
+	ExpiryInterval   time.Duration
+	AuctionFees      models.FeeSchedule
+	StorageDir       string
+	WalkingSpeed     int
+	MaxRouteKm       int
 }
 
 func (d *Dungeons) ParseParameters() {
@@ -44,6 +53,12 @@ func (d *Dungeons) ParseParameters() {
 	d.DBTimeout = time.Duration(getenvInt("DB_TIMEOUT_SECONDS", 5)) * time.Second
 	d.TokenTTL = time.Duration(getenvInt("TOKEN_TTL_HOURS", 24)) * time.Hour
 	d.SeedOnBoot = strings.EqualFold(getenv("SEED_ON_BOOT", "false"), "true")
+	d.ScheduleInterval = time.Duration(getenvInt("SCHEDULE_INTERVAL_SECONDS", 15)) * time.Second
+	d.ExpiryInterval = time.Duration(getenvInt("EXPIRY_INTERVAL_SECONDS", 60)) * time.Second
+	d.StorageDir = getenv("STORAGE_DIR", "./data/blobs")
+	d.WalkingSpeed = getenvInt("WALKING_SPEED_METERS_PER_MINUTE", 75)
+	d.MaxRouteKm = getenvInt("MAX_ROUTE_KM", 15)
+	d.AuctionFees = getenvFees("AUCTION_FEES", defaultAuctionFees)
 }
 
 func (d *Dungeons) ListenAndServe() error {
@@ -88,6 +103,43 @@ func getenvInt(key string, fallback int) int {
 	return n
 }
 
+var defaultAuctionFees = models.FeeSchedule{
+	"default": {
+		Deposit:    models.FeeRule{Percent: 1, Min: 1, Max: 100},
+		Commission: models.FeeRule{Percent: 5, Min: 1, Max: 1000},
+	},
+	"rare": {
+		Deposit:    models.FeeRule{Percent: 2, Min: 5, Max: 250},
+		Commission: models.FeeRule{Percent: 7, Min: 5, Max: 2500},
+	},
+	"epic": {
+		Deposit:    models.FeeRule{Percent: 3, Min: 10, Max: 500},
+		Commission: models.FeeRule{Percent: 8, Min: 10, Max: 5000},
+	},
+	"legendary": {
+		Deposit:    models.FeeRule{Percent: 4, Min: 25, Max: 1000},
+		Commission: models.FeeRule{Percent: 10, Min: 25, Max: 10000},
+	},
+}
+
+// getenvFees reads a JSON fee schedule keyed by rarity, e.g.
+// {"default":{"deposit":{"percent":1,"min":1,"max":100},"commission":{...}}}.
+func getenvFees(key string, fallback models.FeeSchedule) models.FeeSchedule {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback
+	}
+	var fees models.FeeSchedule
+	if err := json.Unmarshal([]byte(value), &fees); err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("invalid fee schedule, using defaults")
+		return fallback
+	}
+	if _, ok := fees["default"]; !ok {
+		fees["default"] = fallback["default"]
+	}
+	return fees
+}
+
 func normalizePort(port string) string {
 	port = strings.TrimSpace(port)
 	if port == "" {
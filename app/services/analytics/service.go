@@ -0,0 +1,111 @@
+package analytics
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/models"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+type Repository interface {
+	RunStateCounts(ctx context.Context, dungeonID string, from, to *time.Time) (map[models.RunState]int64, error)
+	StepKillDurations(ctx context.Context, dungeonID string, from, to *time.Time) (map[string][]float64, error)
+	Payout(ctx context.Context, dungeonID string, from, to *time.Time) (models.Payout, error)
+}
+
+type DungeonRepository interface {
+	GetDungeonByID(ctx context.Context, id string) (models.Dungeon, error)
+	ListStepsByDungeon(ctx context.Context, dungeonID string) ([]models.BossStep, error)
+}
+
+type Service struct {
+	repo     Repository
+	dungeons DungeonRepository
+}
+
+func New(repo Repository, dungeons DungeonRepository) *Service {
+	return &Service{repo: repo, dungeons: dungeons}
+}
+
+func (s *Service) DungeonStats(ctx context.Context, mjID, dungeonID string, from, to *time.Time) (models.DungeonAnalytics, error) {
+	var out models.DungeonAnalytics
+	if from != nil && to != nil && !from.Before(*to) {
+		return out, fmt.Errorf("from must be before to: %w", apperrors.ErrValidation)
+	}
+	d, err := s.dungeons.GetDungeonByID(ctx, dungeonID)
+	if err != nil {
+		return out, fmt.Errorf("get dungeon: %w", err)
+	}
+	if !d.RoleOf(mjID).Allows(models.CollaboratorViewer) {
+		return out, fmt.Errorf("cannot read stats of foreign dungeon: %w", apperrors.ErrForbidden)
+	}
+	steps, err := s.dungeons.ListStepsByDungeon(ctx, dungeonID)
+	if err != nil {
+		return out, fmt.Errorf("list steps: %w", err)
+	}
+
+	states, err := s.repo.RunStateCounts(ctx, dungeonID, from, to)
+	if err != nil {
+		return out, fmt.Errorf("run state counts: %w", err)
+	}
+	durations, err := s.repo.StepKillDurations(ctx, dungeonID, from, to)
+	if err != nil {
+		return out, fmt.Errorf("step kill durations: %w", err)
+	}
+	payout, err := s.repo.Payout(ctx, dungeonID, from, to)
+	if err != nil {
+		return out, fmt.Errorf("payout: %w", err)
+	}
+
+	out = models.DungeonAnalytics{
+		DungeonID: dungeonID,
+		From:      from,
+		To:        to,
+		Runs: models.RunStateCounts{
+			Active:    states[models.RunStateActive],
+			Completed: states[models.RunStateCompleted],
+			Abandoned: states[models.RunStateAbandoned],
+		},
+		Steps:  make([]models.StepFunnel, 0, len(steps)),
+		Payout: payout,
+	}
+	for _, n := range states {
+		out.Runs.Started += n
+	}
+
+	reached := out.Runs.Started
+	for _, st := range steps {
+		killed := int64(len(durations[st.ID]))
+		funnel := models.StepFunnel{
+			StepID:        st.ID,
+			Order:         st.Order,
+			Name:          st.Name,
+			Reached:       reached,
+			Killed:        killed,
+			DropOff:       max(reached-killed, 0),
+			MedianSeconds: median(durations[st.ID]),
+		}
+		if reached > 0 {
+			funnel.DropOffRate = math.Round(float64(funnel.DropOff)/float64(reached)*1000) / 1000
+		}
+		out.Steps = append(out.Steps, funnel)
+		reached = killed
+	}
+	return out, nil
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
@@ -0,0 +1,279 @@
+package auction
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/functions"
+	"dungeons/app/models"
+	"dungeons/app/mongodb"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// A bid placed this close to the end pushes the end back to now plus the
+// same window, so there is always time to answer it.
+const snipeWindow = 5 * time.Minute
+
+func (s *Service) CreateAuction(ctx context.Context, sellerID string, req models.CreateAuctionRequest) (models.Auction, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return models.Auction{}, fmt.Errorf("validate create auction: %w", apperrors.ErrValidation)
+	}
+	item, err := s.inventory.GetItemDef(ctx, req.ItemID)
+	if err != nil {
+		return models.Auction{}, fmt.Errorf("load item def: %w", err)
+	}
+	if !item.Tradable {
+		return models.Auction{}, fmt.Errorf("item not tradable: %w", apperrors.ErrConflict)
+	}
+
+	now := s.now()
+	deposit := s.fees.For(item.Rarity).Deposit.Apply(req.StartPrice)
+	auction := models.Auction{
+		ID:           functions.NewUUID(),
+		SellerID:     sellerID,
+		ItemID:       req.ItemID,
+		Qty:          req.Qty,
+		StartPrice:   req.StartPrice,
+		MinIncrement: req.MinIncrement,
+		BuyoutPrice:  req.BuyoutPrice,
+		Deposit:      deposit,
+		BidderIDs:    make([]string, 0),
+		Status:       models.ListingStatusActive,
+		EndsAt:       now.Add(time.Duration(req.DurationH) * time.Hour),
+		CreatedAt:    now,
+	}
+	err = mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+		if err := s.chargeDeposit(txCtx, sellerID, deposit, auction.ID, now); err != nil {
+			return err
+		}
+		if err := s.inventory.RemoveItem(txCtx, sellerID, req.ItemID, req.Qty, now); err != nil {
+			return fmt.Errorf("remove seller inventory for auction: %w", err)
+		}
+		if err := s.auction.CreateAuction(txCtx, auction); err != nil {
+			return fmt.Errorf("create auction: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Auction{}, fmt.Errorf("transaction create auction: %w", err)
+	}
+	return auction, nil
+}
+
+func (s *Service) ListAuctions(ctx context.Context, params models.QueryParams) ([]models.Auction, error) {
+	auctions, err := s.auction.ListOpenAuctions(ctx, s.now(), params)
+	if err != nil {
+		return nil, fmt.Errorf("list open auctions: %w", err)
+	}
+	return auctions, nil
+}
+
+func (s *Service) GetAuction(ctx context.Context, id string) (models.Auction, error) {
+	auction, err := s.auction.GetAuction(ctx, id)
+	if err != nil {
+		return models.Auction{}, fmt.Errorf("load auction: %w", err)
+	}
+	return auction, nil
+}
+
+// PlaceBid escrows the bid from the bidder's gold and refunds the player it
+// outbids. A leader raising their own bid only pays the difference. A bid at
+// or above the buyout price is capped to it and settles the auction.
+func (s *Service) PlaceBid(ctx context.Context, bidderID, auctionID string, req models.BidRequest) (models.Auction, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return models.Auction{}, fmt.Errorf("validate bid: %w", apperrors.ErrValidation)
+	}
+
+	now := s.now()
+	var out models.Auction
+	err := mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+		var err error
+		out, err = s.applyBid(txCtx, auctionID, bidderID, req, now)
+		return err
+	})
+	if err != nil {
+		return models.Auction{}, fmt.Errorf("transaction place bid: %w", err)
+	}
+	return out, nil
+}
+
+// applyBid places a validated bid. It must run in a transaction.
+func (s *Service) applyBid(txCtx context.Context, auctionID, bidderID string, req models.BidRequest, now time.Time) (models.Auction, error) {
+	auction, err := s.auction.GetAuction(txCtx, auctionID)
+	if err != nil {
+		return models.Auction{}, fmt.Errorf("load auction: %w", err)
+	}
+	if auction.Status != models.ListingStatusActive || !now.Before(auction.EndsAt) {
+		return models.Auction{}, fmt.Errorf("auction is closed: %w", apperrors.ErrConflict)
+	}
+	if auction.SellerID == bidderID {
+		return models.Auction{}, fmt.Errorf("seller cannot bid on own auction: %w", apperrors.ErrConflict)
+	}
+
+	amount := req.Amount
+	buyout := auction.BuyoutPrice > 0 && amount >= auction.BuyoutPrice
+	if buyout {
+		amount = auction.BuyoutPrice
+	} else if amount < auction.MinimumBid() {
+		return models.Auction{}, fmt.Errorf("bid must be at least %d: %w", auction.MinimumBid(), apperrors.ErrConflict)
+	}
+
+	debit := amount
+	switch auction.HighBidderID {
+	case "":
+	case bidderID:
+		debit -= auction.HighBid
+	default:
+		if err := s.release(txCtx, auction.HighBidderID, auction.HighBid, auction.ID, now); err != nil {
+			return models.Auction{}, fmt.Errorf("refund outbid player: %w", err)
+		}
+	}
+	if err := s.hold(txCtx, bidderID, debit, auction.ID, now); err != nil {
+		return models.Auction{}, fmt.Errorf("escrow bid: %w", err)
+	}
+
+	auction.HighBid = amount
+	auction.HighBidderID = bidderID
+	auction.BidCount++
+	if !slices.Contains(auction.BidderIDs, bidderID) {
+		auction.BidderIDs = append(auction.BidderIDs, bidderID)
+	}
+	bid := models.Bid{
+		ID:        functions.NewUUID(),
+		AuctionID: auction.ID,
+		BidderID:  bidderID,
+		Amount:    amount,
+		Buyout:    buyout,
+		CreatedAt: now,
+	}
+	if err := s.auction.InsertBid(txCtx, bid); err != nil {
+		return models.Auction{}, fmt.Errorf("insert bid: %w", err)
+	}
+
+	if buyout {
+		auction.EndsAt = now
+		return s.settle(txCtx, auction, now)
+	}
+	if auction.EndsAt.Sub(now) < snipeWindow {
+		auction.EndsAt = now.Add(snipeWindow)
+	}
+	out, err := s.auction.ReplaceOpenAuction(txCtx, auction)
+	if err != nil {
+		return models.Auction{}, fmt.Errorf("update auction after bid: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Service) ListBids(ctx context.Context, auctionID string, params models.QueryParams) ([]models.Bid, error) {
+	if _, err := s.auction.GetAuction(ctx, auctionID); err != nil {
+		return nil, fmt.Errorf("load auction: %w", err)
+	}
+	bids, err := s.auction.ListBids(ctx, auctionID, params)
+	if err != nil {
+		return nil, fmt.Errorf("list bids: %w", err)
+	}
+	return bids, nil
+}
+
+func (s *Service) MyBids(ctx context.Context, playerID string, params models.QueryParams) ([]models.MyBid, error) {
+	auctions, err := s.auction.ListAuctionsByBidder(ctx, playerID, params)
+	if err != nil {
+		return nil, fmt.Errorf("list auctions by bidder: %w", err)
+	}
+	out := make([]models.MyBid, 0, len(auctions))
+	for _, a := range auctions {
+		out = append(out, models.MyBid{Auction: a, Leading: a.HighBidderID == playerID})
+	}
+	return out, nil
+}
+
+// SettleAuctions closes every active auction past its end. Each one is
+// re-read inside its transaction, so a late bid that extended the end, or
+// another instance settling it first, makes it a no-op.
+func (s *Service) SettleAuctions(ctx context.Context) (int, error) {
+	now := s.now()
+	settled := 0
+	for {
+		due, err := s.auction.ListDueAuctions(ctx, now, expiryBatch)
+		if err != nil {
+			return settled, fmt.Errorf("list due auctions: %w", err)
+		}
+		for _, a := range due {
+			var ok bool
+			err := mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+				var err error
+				ok, err = s.settleDue(txCtx, a.ID, now)
+				return err
+			})
+			if err != nil {
+				return settled, fmt.Errorf("transaction settle auction %s: %w", a.ID, err)
+			}
+			if ok {
+				settled++
+			}
+		}
+		if len(due) < expiryBatch {
+			return settled, nil
+		}
+	}
+}
+
+// settleDue settles the auction if it is still active and past its end,
+// reporting whether it did. It must run in a transaction.
+func (s *Service) settleDue(txCtx context.Context, id string, now time.Time) (bool, error) {
+	auction, err := s.auction.GetAuction(txCtx, id)
+	if err != nil {
+		return false, fmt.Errorf("load auction: %w", err)
+	}
+	if auction.Status != models.ListingStatusActive || auction.EndsAt.After(now) {
+		return false, nil
+	}
+	if _, err := s.settle(txCtx, auction, now); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// settle hands the item to the winner and the escrowed bid to the seller, or
+// the item back to the seller when nobody bid. It must run in a transaction.
+func (s *Service) settle(txCtx context.Context, auction models.Auction, now time.Time) (models.Auction, error) {
+	if auction.HighBidderID == "" {
+		if err := s.inventory.AddItem(txCtx, auction.SellerID, auction.ItemID, auction.Qty, now); err != nil {
+			return models.Auction{}, fmt.Errorf("return unsold auction item: %w", err)
+		}
+		auction.Status = models.ListingStatusExpired
+	} else {
+		item, err := s.inventory.GetItemDef(txCtx, auction.ItemID)
+		if err != nil {
+			return models.Auction{}, fmt.Errorf("load item def: %w", err)
+		}
+		trade := models.Trade{
+			ID:         functions.NewUUID(),
+			BuyerID:    auction.HighBidderID,
+			SellerID:   auction.SellerID,
+			ListingID:  auction.ID,
+			ItemID:     auction.ItemID,
+			Qty:        auction.Qty,
+			TotalPrice: auction.HighBid,
+			Fee:        s.fees.For(item.Rarity).Commission.Apply(auction.HighBid),
+			CreatedAt:  now,
+		}
+		if err := s.inventory.AddItem(txCtx, auction.HighBidderID, auction.ItemID, auction.Qty, now); err != nil {
+			return models.Auction{}, fmt.Errorf("transfer item to winner: %w", err)
+		}
+		if err := s.payout(txCtx, models.AccountEscrow, trade, now); err != nil {
+			return models.Auction{}, err
+		}
+		if err := s.auction.InsertTrade(txCtx, trade); err != nil {
+			return models.Auction{}, fmt.Errorf("insert trade: %w", err)
+		}
+		auction.Status = models.ListingStatusSold
+	}
+	auction.SettledAt = &now
+	out, err := s.auction.ReplaceOpenAuction(txCtx, auction)
+	if err != nil {
+		return models.Auction{}, fmt.Errorf("close auction: %w", err)
+	}
+	return out, nil
+}
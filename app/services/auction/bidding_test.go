@@ -0,0 +1,179 @@
+package auction
+
+import (
+	"context"
+	"dungeons/app/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+type houseStub struct {
+	AuctionRepository
+	auction  models.Auction
+	bids     []models.Bid
+	trades   []models.Trade
+	treasury int64
+}
+
+func (h *houseStub) GetAuction(context.Context, string) (models.Auction, error) {
+	return h.auction, nil
+}
+
+func (h *houseStub) ReplaceOpenAuction(_ context.Context, auction models.Auction) (models.Auction, error) {
+	h.auction = auction
+	return auction, nil
+}
+
+func (h *houseStub) InsertBid(_ context.Context, bid models.Bid) error {
+	h.bids = append(h.bids, bid)
+	return nil
+}
+
+func (h *houseStub) InsertTrade(_ context.Context, trade models.Trade) error {
+	h.trades = append(h.trades, trade)
+	return nil
+}
+
+func (h *houseStub) CreditTreasury(_ context.Context, deposits, commissions int64, _ time.Time) error {
+	h.treasury += deposits + commissions
+	return nil
+}
+
+func (bagStub) GetItemDef(_ context.Context, itemID string) (models.ItemDef, error) {
+	return models.ItemDef{ID: itemID, Rarity: "common"}, nil
+}
+
+var bidT0 = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newBidding(auction models.Auction) (*Service, *houseStub, walletStub, bagStub) {
+	house := &houseStub{auction: auction}
+	wallets := walletStub{gold: map[string]int64{}}
+	bags := bagStub{items: map[string]int64{}}
+	fees := models.FeeSchedule{"default": {Commission: models.FeeRule{Percent: 10}}}
+	return &Service{auction: house, players: wallets, inventory: bags, fees: fees}, house, wallets, bags
+}
+
+func openAuction(endsIn time.Duration) models.Auction {
+	return models.Auction{
+		ID:           "a1",
+		SellerID:     "seller",
+		ItemID:       "sword",
+		Qty:          1,
+		StartPrice:   100,
+		MinIncrement: 10,
+		BuyoutPrice:  500,
+		BidderIDs:    []string{},
+		Status:       models.ListingStatusActive,
+		EndsAt:       bidT0.Add(endsIn),
+	}
+}
+
+func TestPlaceBidRefundsOutbidAndChargesLeaderTheDifference(t *testing.T) {
+	svc, house, wallets, _ := newBidding(openAuction(time.Hour))
+	ctx := context.Background()
+
+	for _, b := range []struct {
+		bidder string
+		amount int64
+	}{{"alice", 100}, {"bob", 120}, {"bob", 150}} {
+		if _, err := svc.applyBid(ctx, "a1", b.bidder, models.BidRequest{Amount: b.amount}, bidT0); err != nil {
+			t.Fatalf("%s bids %d: %v", b.bidder, b.amount, err)
+		}
+	}
+
+	alice, bob, escrow := wallets.gold[models.PlayerAccount("alice")], wallets.gold[models.PlayerAccount("bob")], wallets.gold[models.AccountEscrow]
+	if alice != 0 || bob != -150 || escrow != 150 {
+		t.Fatalf("alice %d, bob %d, escrow %d; want 0, -150, 150", alice, bob, escrow)
+	}
+	a := house.auction
+	if a.HighBid != 150 || a.HighBidderID != "bob" || a.BidCount != 3 || !slices.Equal(a.BidderIDs, []string{"alice", "bob"}) {
+		t.Fatalf("auction = %+v", a)
+	}
+	if len(house.bids) != 3 || !a.EndsAt.Equal(bidT0.Add(time.Hour)) {
+		t.Fatalf("%d bids, ends at %s", len(house.bids), a.EndsAt)
+	}
+}
+
+func TestPlaceBidAboveBuyoutSettlesAtBuyoutPrice(t *testing.T) {
+	svc, house, wallets, bags := newBidding(openAuction(time.Hour))
+	ctx := context.Background()
+
+	if _, err := svc.applyBid(ctx, "a1", "alice", models.BidRequest{Amount: 200}, bidT0); err != nil {
+		t.Fatal(err)
+	}
+	out, err := svc.applyBid(ctx, "a1", "bob", models.BidRequest{Amount: 800}, bidT0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Status != models.ListingStatusSold || out.HighBid != 500 || out.SettledAt == nil || !out.EndsAt.Equal(bidT0) {
+		t.Fatalf("auction = %+v", out)
+	}
+	if !house.bids[1].Buyout || house.bids[1].Amount != 500 {
+		t.Fatalf("buyout bid = %+v", house.bids[1])
+	}
+	if len(house.trades) != 1 || house.trades[0].TotalPrice != 500 || house.trades[0].Fee != 50 {
+		t.Fatalf("trades = %+v", house.trades)
+	}
+	gold := wallets.gold
+	if gold[models.PlayerAccount("alice")] != 0 || gold[models.PlayerAccount("bob")] != -500 || gold[models.PlayerAccount("seller")] != 450 {
+		t.Fatalf("gold = %v", gold)
+	}
+	if gold[models.AccountEscrow] != 0 || gold[models.AccountTreasury] != 50 || house.treasury != 50 {
+		t.Fatalf("escrow %d, treasury %d (%d)", gold[models.AccountEscrow], gold[models.AccountTreasury], house.treasury)
+	}
+	if bags.items["bob"] != 1 {
+		t.Fatalf("winner holds %d items, want 1", bags.items["bob"])
+	}
+}
+
+func TestPlaceBidInSnipeWindowExtendsEnd(t *testing.T) {
+	svc, house, _, _ := newBidding(openAuction(2 * time.Minute))
+	if _, err := svc.applyBid(context.Background(), "a1", "alice", models.BidRequest{Amount: 100}, bidT0); err != nil {
+		t.Fatal(err)
+	}
+	if want := bidT0.Add(snipeWindow); !house.auction.EndsAt.Equal(want) {
+		t.Fatalf("ends at %s, want %s", house.auction.EndsAt, want)
+	}
+}
+
+func TestSettleDue(t *testing.T) {
+	ctx := context.Background()
+	after := bidT0.Add(2 * time.Hour)
+
+	t.Run("without bids", func(t *testing.T) {
+		svc, house, wallets, bags := newBidding(openAuction(time.Hour))
+		if ok, err := svc.settleDue(ctx, "a1", bidT0); err != nil || ok {
+			t.Fatalf("settled before the end: %v, %v", ok, err)
+		}
+		if ok, err := svc.settleDue(ctx, "a1", after); err != nil || !ok {
+			t.Fatalf("settleDue = %v, %v", ok, err)
+		}
+		if house.auction.Status != models.ListingStatusExpired || bags.items["seller"] != 1 {
+			t.Fatalf("status %s, seller holds %d", house.auction.Status, bags.items["seller"])
+		}
+		if len(house.trades) != 0 || len(wallets.gold) != 0 {
+			t.Fatalf("trades %v, gold %v", house.trades, wallets.gold)
+		}
+		if ok, err := svc.settleDue(ctx, "a1", after); err != nil || ok {
+			t.Fatalf("settled twice: %v, %v", ok, err)
+		}
+	})
+
+	t.Run("with bids", func(t *testing.T) {
+		svc, house, wallets, bags := newBidding(openAuction(time.Hour))
+		if _, err := svc.applyBid(ctx, "a1", "alice", models.BidRequest{Amount: 300}, bidT0); err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := svc.settleDue(ctx, "a1", after); err != nil || !ok {
+			t.Fatalf("settleDue = %v, %v", ok, err)
+		}
+		if house.auction.Status != models.ListingStatusSold || bags.items["alice"] != 1 || bags.items["seller"] != 0 {
+			t.Fatalf("status %s, items %v", house.auction.Status, bags.items)
+		}
+		if wallets.gold[models.PlayerAccount("seller")] != 270 || wallets.gold[models.AccountTreasury] != 30 || wallets.gold[models.AccountEscrow] != 0 {
+			t.Fatalf("gold = %v", wallets.gold)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package auction
+
+import (
+	"context"
+	"dungeons/app/mongodb"
+	"fmt"
+	"time"
+)
+
+const expiryBatch = 100
+
+// ExpireListings gives the unsold quantity of every active listing past its
+// expiresAt back to the seller. The status flip only matches active
+// listings, so when several instances sweep at once each listing is
+// returned exactly once.
+func (s *Service) ExpireListings(ctx context.Context) (int, error) {
+	now := s.now()
+	expired := 0
+	for {
+		due, err := s.auction.ListExpired(ctx, now, expiryBatch)
+		if err != nil {
+			return expired, fmt.Errorf("list expired listings: %w", err)
+		}
+		for _, l := range due {
+			var ok bool
+			err := mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+				var err error
+				ok, err = s.expireOne(txCtx, l.ID, now)
+				return err
+			})
+			if err != nil {
+				return expired, fmt.Errorf("transaction expire listing %s: %w", l.ID, err)
+			}
+			if ok {
+				expired++
+			}
+		}
+		if len(due) < expiryBatch {
+			return expired, nil
+		}
+	}
+}
+
+// expireOne flips the listing to expired and returns its unsold quantity,
+// reporting whether this call did the flip. It must run in a transaction.
+func (s *Service) expireOne(txCtx context.Context, id string, now time.Time) (bool, error) {
+	listing, flipped, err := s.auction.ExpireListing(txCtx, id, now)
+	if err != nil {
+		return false, err
+	}
+	if !flipped || listing.Qty == 0 {
+		return flipped, nil
+	}
+	if err := s.inventory.AddItem(txCtx, listing.SellerID, listing.ItemID, listing.Qty, now); err != nil {
+		return false, fmt.Errorf("restore inventory on expiry: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,54 @@
+package auction
+
+import (
+	"context"
+	"dungeons/app/models"
+	"testing"
+	"time"
+)
+
+// shelfStub flips a listing only while it is active and past expiresAt, like
+// the conditional update in the repository.
+type shelfStub struct {
+	AuctionRepository
+	listings map[string]models.Listing
+}
+
+func (s *shelfStub) ExpireListing(_ context.Context, id string, now time.Time) (models.Listing, bool, error) {
+	l := s.listings[id]
+	if l.Status != models.ListingStatusActive || l.ExpiresAt == nil || l.ExpiresAt.After(now) {
+		return models.Listing{}, false, nil
+	}
+	before := l
+	l.Status = models.ListingStatusExpired
+	s.listings[id] = l
+	return before, true, nil
+}
+
+func TestExpireOneReturnsUnsoldQtyOnce(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := t0.Add(-time.Minute)
+	shelf := &shelfStub{listings: map[string]models.Listing{
+		"l1": {ID: "l1", SellerID: "seller", ItemID: "gem", Qty: 3, Status: models.ListingStatusActive, ExpiresAt: &expiresAt},
+		"l2": {ID: "l2", SellerID: "other", ItemID: "gem", Qty: 4, Status: models.ListingStatusSold, ExpiresAt: &expiresAt},
+	}}
+	bags := bagStub{items: map[string]int64{}}
+	svc := &Service{auction: shelf, inventory: bags}
+	ctx := context.Background()
+
+	if ok, err := svc.expireOne(ctx, "l1", t0); err != nil || !ok {
+		t.Fatalf("expireOne = %v, %v", ok, err)
+	}
+	if ok, err := svc.expireOne(ctx, "l1", t0); err != nil || ok {
+		t.Fatalf("second expireOne = %v, %v", ok, err)
+	}
+	if bags.items["seller"] != 3 {
+		t.Fatalf("seller got %d back, want 3", bags.items["seller"])
+	}
+	if ok, err := svc.expireOne(ctx, "l2", t0); err != nil || ok {
+		t.Fatalf("expireOne on a sold listing = %v, %v", ok, err)
+	}
+	if bags.items["other"] != 0 {
+		t.Fatalf("unflipped listing returned %d items", bags.items["other"])
+	}
+}
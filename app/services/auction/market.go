@@ -0,0 +1,90 @@
+package auction
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/models"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	defaultBuckets = 30
+	maxBuckets     = 200
+	marketWindow   = 7 * 24 * time.Hour
+
+	// A listing priced outside [low, high] times the 7-day average gets a
+	// warning.
+	priceWarnLow  = 0.5
+	priceWarnHigh = 2.0
+)
+
+// Go's zero time is a Monday, so truncating to a week lines up with the
+// Monday-based weeks of the history aggregation.
+var historyIntervals = map[string]time.Duration{
+	"hour": time.Hour,
+	"day":  24 * time.Hour,
+	"week": 7 * 24 * time.Hour,
+}
+
+// ItemHistory returns the last buckets intervals of OHLC unit prices for an
+// item, with its last sale and 7-day average. Intervals without trades are
+// left out.
+func (s *Service) ItemHistory(ctx context.Context, itemID, interval string, buckets int64) (models.ItemMarket, error) {
+	if interval == "" {
+		interval = "day"
+	}
+	step, ok := historyIntervals[interval]
+	if !ok {
+		return models.ItemMarket{}, fmt.Errorf("interval must be hour, day or week: %w", apperrors.ErrValidation)
+	}
+	if buckets <= 0 {
+		buckets = defaultBuckets
+	}
+	buckets = min(buckets, maxBuckets)
+	if _, err := s.inventory.GetItemDef(ctx, itemID); err != nil {
+		return models.ItemMarket{}, fmt.Errorf("load item def: %w", err)
+	}
+
+	now := s.now()
+	since := now.Truncate(step).Add(-time.Duration(buckets-1) * step)
+	history, err := s.auction.PriceHistory(ctx, itemID, interval, since)
+	if err != nil {
+		return models.ItemMarket{}, fmt.Errorf("load price history: %w", err)
+	}
+	market := models.ItemMarket{ItemID: itemID, Interval: interval, Buckets: history}
+
+	last, err := s.auction.LastTrade(ctx, itemID)
+	switch {
+	case err == nil:
+		market.LastSale = &models.LastSale{UnitPrice: float64(last.TotalPrice) / float64(last.Qty), Qty: last.Qty, At: last.CreatedAt}
+	case !errors.Is(err, apperrors.ErrNotFound):
+		return models.ItemMarket{}, fmt.Errorf("load last trade: %w", err)
+	}
+	if market.Last7d, err = s.auction.MarketSummary(ctx, itemID, now.Add(-marketWindow)); err != nil {
+		return models.ItemMarket{}, fmt.Errorf("load market summary: %w", err)
+	}
+	return market, nil
+}
+
+// priceWarning compares a unit price to the item's 7-day average. It stays
+// silent when the item has not traded recently.
+func (s *Service) priceWarning(ctx context.Context, itemID string, unitPrice int64) (string, error) {
+	summary, err := s.auction.MarketSummary(ctx, itemID, s.now().Add(-marketWindow))
+	if err != nil {
+		return "", fmt.Errorf("load market summary: %w", err)
+	}
+	if summary.Volume == 0 {
+		return "", nil
+	}
+	ratio := float64(unitPrice) / summary.AvgUnitPrice
+	switch {
+	case ratio < priceWarnLow:
+		return fmt.Sprintf("price is %.0f%% below the 7-day average of %.2f", math.Round((1-ratio)*100), summary.AvgUnitPrice), nil
+	case ratio > priceWarnHigh:
+		return fmt.Sprintf("price is %.0f%% above the 7-day average of %.2f", math.Round((ratio-1)*100), summary.AvgUnitPrice), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,210 @@
+package auction
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/functions"
+	"dungeons/app/models"
+	"dungeons/app/mongodb"
+	"fmt"
+	"time"
+)
+
+const (
+	matchBatch   = 50
+	defaultDepth = 20
+	maxDepth     = 50
+)
+
+// CreateBuyOrder escrows Qty*MaxUnitPrice from the buyer and fills what it
+// can right away against live listings; the rest rests on the book.
+func (s *Service) CreateBuyOrder(ctx context.Context, buyerID string, req models.CreateBuyOrderRequest) (models.BuyOrder, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return models.BuyOrder{}, fmt.Errorf("validate create buy order: %w", apperrors.ErrValidation)
+	}
+	item, err := s.inventory.GetItemDef(ctx, req.ItemID)
+	if err != nil {
+		return models.BuyOrder{}, fmt.Errorf("load item def: %w", err)
+	}
+	if !item.Tradable {
+		return models.BuyOrder{}, fmt.Errorf("item not tradable: %w", apperrors.ErrConflict)
+	}
+
+	now := s.now()
+	order := models.BuyOrder{
+		ID:           functions.NewUUID(),
+		BuyerID:      buyerID,
+		ItemID:       req.ItemID,
+		Qty:          req.Qty,
+		Remaining:    req.Qty,
+		MaxUnitPrice: req.MaxUnitPrice,
+		Status:       models.BuyOrderStatusOpen,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	err = mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+		if err := s.hold(txCtx, buyerID, req.Qty*req.MaxUnitPrice, order.ID, now); err != nil {
+			return fmt.Errorf("escrow buy order: %w", err)
+		}
+		if err := s.auction.CreateBuyOrder(txCtx, order); err != nil {
+			return fmt.Errorf("create buy order: %w", err)
+		}
+		return s.matchOrder(txCtx, &order, s.fees.For(item.Rarity).Commission, now)
+	})
+	if err != nil {
+		return models.BuyOrder{}, fmt.Errorf("transaction create buy order: %w", err)
+	}
+	return order, nil
+}
+
+func (s *Service) CancelBuyOrder(ctx context.Context, buyerID, orderID string) (models.BuyOrder, error) {
+	now := s.now()
+	var out models.BuyOrder
+	err := mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+		order, err := s.auction.GetBuyOrder(txCtx, orderID)
+		if err != nil {
+			return fmt.Errorf("load buy order: %w", err)
+		}
+		if order.BuyerID != buyerID {
+			return fmt.Errorf("cannot cancel foreign buy order: %w", apperrors.ErrForbidden)
+		}
+		if order.Status != models.BuyOrderStatusOpen {
+			return fmt.Errorf("buy order cannot be cancelled in current state: %w", apperrors.ErrConflict)
+		}
+		if err := s.release(txCtx, buyerID, order.Remaining*order.MaxUnitPrice, order.ID, now); err != nil {
+			return fmt.Errorf("refund buy order escrow: %w", err)
+		}
+		order.Status = models.BuyOrderStatusCancelled
+		order.UpdatedAt = now
+		out, err = s.auction.ReplaceOpenBuyOrder(txCtx, order)
+		if err != nil {
+			return fmt.Errorf("update buy order to cancelled: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.BuyOrder{}, fmt.Errorf("transaction cancel buy order: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Service) MyBuyOrders(ctx context.Context, buyerID string, params models.QueryParams) ([]models.BuyOrder, error) {
+	orders, err := s.auction.ListBuyOrdersByBuyer(ctx, buyerID, params)
+	if err != nil {
+		return nil, fmt.Errorf("list buy orders: %w", err)
+	}
+	return orders, nil
+}
+
+func (s *Service) OrderBook(ctx context.Context, itemID string, depth int64) (models.OrderBook, error) {
+	if _, err := s.inventory.GetItemDef(ctx, itemID); err != nil {
+		return models.OrderBook{}, fmt.Errorf("load item def: %w", err)
+	}
+	if depth <= 0 {
+		depth = defaultDepth
+	}
+	depth = min(depth, maxDepth)
+	bids, asks, err := s.auction.OrderBook(ctx, itemID, s.now(), depth)
+	if err != nil {
+		return models.OrderBook{}, fmt.Errorf("load order book: %w", err)
+	}
+	return models.OrderBook{ItemID: itemID, Bids: bids, Asks: asks}, nil
+}
+
+// matchListing fills a new listing against resting buy orders, best price
+// then oldest first. The resting order sets the price.
+func (s *Service) matchListing(txCtx context.Context, listing *models.Listing, commission models.FeeRule, now time.Time) error {
+	for listing.Qty > 0 {
+		orders, err := s.auction.ListMatchingOrders(txCtx, listing.ItemID, listing.PricePerUnit, listing.SellerID, matchBatch)
+		if err != nil {
+			return fmt.Errorf("list matching buy orders: %w", err)
+		}
+		if len(orders) == 0 {
+			return nil
+		}
+		for i := range orders {
+			if listing.Qty == 0 {
+				break
+			}
+			order := &orders[i]
+			if err := s.fill(txCtx, listing, order, min(listing.Qty, order.Remaining), order.MaxUnitPrice, commission, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matchOrder fills a new buy order against resting listings, cheapest then
+// oldest first. The resting listing sets the price.
+func (s *Service) matchOrder(txCtx context.Context, order *models.BuyOrder, commission models.FeeRule, now time.Time) error {
+	for order.Remaining > 0 {
+		listings, err := s.auction.ListMatchingListings(txCtx, order.ItemID, order.MaxUnitPrice, order.BuyerID, now, matchBatch)
+		if err != nil {
+			return fmt.Errorf("list matching listings: %w", err)
+		}
+		if len(listings) == 0 {
+			return nil
+		}
+		for i := range listings {
+			if order.Remaining == 0 {
+				break
+			}
+			listing := &listings[i]
+			if err := s.fill(txCtx, listing, order, min(listing.Qty, order.Remaining), listing.PricePerUnit, commission, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fill trades qty units from listing to order at price. The buyer pays out
+// of the order's escrow and gets back the part above price.
+func (s *Service) fill(txCtx context.Context, listing *models.Listing, order *models.BuyOrder, qty, price int64, commission models.FeeRule, now time.Time) error {
+	total := qty * price
+	trade := models.Trade{
+		ID:         functions.NewUUID(),
+		BuyerID:    order.BuyerID,
+		SellerID:   listing.SellerID,
+		ListingID:  listing.ID,
+		OrderID:    order.ID,
+		ItemID:     listing.ItemID,
+		Qty:        qty,
+		TotalPrice: total,
+		Fee:        commission.Apply(total),
+		CreatedAt:  now,
+	}
+	if err := s.inventory.AddItem(txCtx, order.BuyerID, listing.ItemID, qty, now); err != nil {
+		return fmt.Errorf("transfer item to buyer inventory: %w", err)
+	}
+	if err := s.payout(txCtx, models.AccountEscrow, trade, now); err != nil {
+		return err
+	}
+	if refund := qty*order.MaxUnitPrice - total; refund > 0 {
+		if err := s.release(txCtx, order.BuyerID, refund, order.ID, now); err != nil {
+			return fmt.Errorf("refund buy order price improvement: %w", err)
+		}
+	}
+
+	listing.Qty -= qty
+	if listing.Qty == 0 {
+		listing.Status = models.ListingStatusSold
+		listing.BuyerID = order.BuyerID
+	}
+	if _, err := s.auction.ReplaceListing(txCtx, *listing); err != nil {
+		return fmt.Errorf("update listing after fill: %w", err)
+	}
+	order.Remaining -= qty
+	order.UpdatedAt = now
+	if order.Remaining == 0 {
+		order.Status = models.BuyOrderStatusFilled
+	}
+	if _, err := s.auction.ReplaceOpenBuyOrder(txCtx, *order); err != nil {
+		return fmt.Errorf("update buy order after fill: %w", err)
+	}
+	if err := s.auction.InsertTrade(txCtx, trade); err != nil {
+		return fmt.Errorf("insert trade: %w", err)
+	}
+	return nil
+}
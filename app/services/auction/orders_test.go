@@ -0,0 +1,150 @@
+package auction
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/models"
+	"errors"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type bookStub struct {
+	AuctionRepository
+	orders   []models.BuyOrder
+	trades   []models.Trade
+	treasury int64
+}
+
+func (b *bookStub) ListMatchingOrders(_ context.Context, itemID string, minPrice int64, excludeBuyer string, limit int64) ([]models.BuyOrder, error) {
+	out := make([]models.BuyOrder, 0)
+	for _, o := range b.orders {
+		if o.ItemID == itemID && o.Status == models.BuyOrderStatusOpen && o.MaxUnitPrice >= minPrice && o.BuyerID != excludeBuyer {
+			out = append(out, o)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].MaxUnitPrice != out[j].MaxUnitPrice {
+			return out[i].MaxUnitPrice > out[j].MaxUnitPrice
+		}
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+	return out[:min(int64(len(out)), limit)], nil
+}
+
+func (b *bookStub) ReplaceOpenBuyOrder(_ context.Context, order models.BuyOrder) (models.BuyOrder, error) {
+	for i := range b.orders {
+		if b.orders[i].ID == order.ID {
+			b.orders[i] = order
+		}
+	}
+	return order, nil
+}
+
+func (b *bookStub) ReplaceListing(_ context.Context, listing models.Listing) (models.Listing, error) {
+	return listing, nil
+}
+
+func (b *bookStub) CreditTreasury(_ context.Context, deposits, commissions int64, _ time.Time) error {
+	b.treasury += deposits + commissions
+	return nil
+}
+
+func (b *bookStub) InsertTrade(_ context.Context, trade models.Trade) error {
+	b.trades = append(b.trades, trade)
+	return nil
+}
+
+type walletStub struct {
+	gold map[string]int64
+}
+
+func (w walletStub) Transfer(_ context.Context, t models.GoldTransfer, _ time.Time) error {
+	w.gold[t.From] -= t.Amount
+	w.gold[t.To] += t.Amount
+	return nil
+}
+
+type bagStub struct {
+	InventoryRepository
+	items map[string]int64
+}
+
+func (b bagStub) AddItem(_ context.Context, playerID, _ string, qty int64, _ time.Time) error {
+	b.items[playerID] += qty
+	return nil
+}
+
+func TestMatchListingFillsBestPriceThenOldestAndTakesCommission(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	order := func(id string, price, qty int64, at time.Duration) models.BuyOrder {
+		return models.BuyOrder{ID: id, BuyerID: id, ItemID: "gem", Qty: qty, Remaining: qty, MaxUnitPrice: price, Status: models.BuyOrderStatusOpen, CreatedAt: t0.Add(at)}
+	}
+	book := &bookStub{orders: []models.BuyOrder{
+		order("late", 12, 2, time.Minute),
+		order("early", 12, 2, 0),
+		order("lower", 11, 5, 0),
+		order("below", 9, 5, 0),
+	}}
+	wallets := walletStub{gold: map[string]int64{}}
+	bags := bagStub{items: map[string]int64{}}
+	svc := &Service{auction: book, players: wallets, inventory: bags}
+
+	listing := models.Listing{ID: "l1", SellerID: "seller", ItemID: "gem", Qty: 5, PricePerUnit: 10, Status: models.ListingStatusActive}
+	if err := svc.matchListing(context.Background(), &listing, models.FeeRule{Percent: 10, Min: 1}, t0); err != nil {
+		t.Fatal(err)
+	}
+
+	if listing.Qty != 0 || listing.Status != models.ListingStatusSold {
+		t.Fatalf("listing = %d left, %s; want sold out", listing.Qty, listing.Status)
+	}
+	want := []struct {
+		buyer string
+		qty   int64
+		total int64
+		fee   int64
+	}{{"early", 2, 24, 2}, {"late", 2, 24, 2}, {"lower", 1, 11, 1}}
+	if len(book.trades) != len(want) {
+		t.Fatalf("got %d trades, want %d", len(book.trades), len(want))
+	}
+	for i, w := range want {
+		tr := book.trades[i]
+		if tr.BuyerID != w.buyer || tr.Qty != w.qty || tr.TotalPrice != w.total || tr.Fee != w.fee || tr.OrderID != w.buyer {
+			t.Fatalf("trade %d = %+v, want %+v", i, tr, w)
+		}
+	}
+	seller, treasury := wallets.gold[models.PlayerAccount("seller")], wallets.gold[models.AccountTreasury]
+	if seller != 54 || treasury != 5 || book.treasury != 5 {
+		t.Fatalf("seller got %d gold and treasury %d (%d), want 54 and 5", seller, treasury, book.treasury)
+	}
+	if escrow := wallets.gold[models.AccountEscrow]; escrow != -59 {
+		t.Fatalf("escrow paid out %d, want 59", -escrow)
+	}
+	if book.orders[2].Remaining != 4 || book.orders[2].Status != models.BuyOrderStatusOpen {
+		t.Fatalf("partially filled order = %+v", book.orders[2])
+	}
+	if book.orders[3].Remaining != 5 {
+		t.Fatal("order below the ask price was filled")
+	}
+}
+
+func TestCreateBuyOrderRejectsOutOfBoundsQtyAndPrice(t *testing.T) {
+	wallets := walletStub{gold: map[string]int64{}}
+	svc := &Service{auction: &bookStub{}, players: wallets, inventory: bagStub{}, validate: validator.New(), now: time.Now}
+
+	for _, req := range []models.CreateBuyOrderRequest{
+		{ItemID: "gem", Qty: 2, MaxUnitPrice: 1<<62 + 1},
+		{ItemID: "gem", Qty: math.MaxInt64, MaxUnitPrice: 2},
+	} {
+		if _, err := svc.CreateBuyOrder(context.Background(), "buyer", req); !errors.Is(err, apperrors.ErrValidation) {
+			t.Fatalf("CreateBuyOrder(%d x %d) = %v, want validation error", req.Qty, req.MaxUnitPrice, err)
+		}
+	}
+	if len(wallets.gold) != 0 {
+		t.Fatalf("gold moved: %v", wallets.gold)
+	}
+}
@@ -16,10 +16,34 @@ import (
 type AuctionRepository interface {
 	EnsureIndexes(ctx context.Context) error
 	CreateListing(ctx context.Context, listing models.Listing) error
-	ListActive(ctx context.Context, params models.QueryParams) ([]models.Listing, error)
+	SearchListings(ctx context.Context, query models.ListingSearchQuery, now time.Time, params models.QueryParams) ([]models.Listing, error)
+	ListListingsBySeller(ctx context.Context, sellerID string, params models.QueryParams) ([]models.Listing, error)
+	ListTradesByPlayer(ctx context.Context, playerID string, side models.TradeSide, params models.QueryParams) ([]models.Trade, error)
 	GetByID(ctx context.Context, id string) (models.Listing, error)
 	ReplaceListing(ctx context.Context, listing models.Listing) (models.Listing, error)
 	InsertTrade(ctx context.Context, trade models.Trade) error
+	ListExpired(ctx context.Context, now time.Time, limit int64) ([]models.Listing, error)
+	ExpireListing(ctx context.Context, id string, now time.Time) (models.Listing, bool, error)
+	CreateAuction(ctx context.Context, auction models.Auction) error
+	GetAuction(ctx context.Context, id string) (models.Auction, error)
+	ReplaceOpenAuction(ctx context.Context, auction models.Auction) (models.Auction, error)
+	ListOpenAuctions(ctx context.Context, now time.Time, params models.QueryParams) ([]models.Auction, error)
+	ListAuctionsByBidder(ctx context.Context, bidderID string, params models.QueryParams) ([]models.Auction, error)
+	ListDueAuctions(ctx context.Context, now time.Time, limit int64) ([]models.Auction, error)
+	InsertBid(ctx context.Context, bid models.Bid) error
+	ListBids(ctx context.Context, auctionID string, params models.QueryParams) ([]models.Bid, error)
+	CreateBuyOrder(ctx context.Context, order models.BuyOrder) error
+	GetBuyOrder(ctx context.Context, id string) (models.BuyOrder, error)
+	ReplaceOpenBuyOrder(ctx context.Context, order models.BuyOrder) (models.BuyOrder, error)
+	ListBuyOrdersByBuyer(ctx context.Context, buyerID string, params models.QueryParams) ([]models.BuyOrder, error)
+	ListMatchingOrders(ctx context.Context, itemID string, minPrice int64, excludeBuyer string, limit int64) ([]models.BuyOrder, error)
+	ListMatchingListings(ctx context.Context, itemID string, maxPrice int64, excludeSeller string, now time.Time, limit int64) ([]models.Listing, error)
+	OrderBook(ctx context.Context, itemID string, now time.Time, depth int64) (bids, asks []models.BookLevel, err error)
+	CreditTreasury(ctx context.Context, deposits, commissions int64, updatedAt time.Time) error
+	GetTreasury(ctx context.Context) (models.Treasury, error)
+	PriceHistory(ctx context.Context, itemID, unit string, since time.Time) ([]models.PriceBucket, error)
+	LastTrade(ctx context.Context, itemID string) (models.Trade, error)
+	MarketSummary(ctx context.Context, itemID string, since time.Time) (models.MarketSummary, error)
 }
 
 type InventoryRepository interface {
@@ -29,25 +53,25 @@ type InventoryRepository interface {
 }
 
 type PlayerRepository interface {
-	GetByID(ctx context.Context, id string) (models.Player, error)
-	IncrementGold(ctx context.Context, id string, delta int64, updatedAt time.Time) (models.Player, error)
-	SetGold(ctx context.Context, id string, gold int64, updatedAt time.Time) (models.Player, error)
+	Transfer(ctx context.Context, t models.GoldTransfer, at time.Time) error
 }
 
 type Service struct {
 	auction   AuctionRepository
 	inventory InventoryRepository
 	players   PlayerRepository
+	fees      models.FeeSchedule
 	validate  *validator.Validate
 	client    *mongo.Client
 	now       func() time.Time
 }
 
-func New(auction AuctionRepository, inventory InventoryRepository, players PlayerRepository, validate *validator.Validate, client *mongo.Client) *Service {
+func New(auction AuctionRepository, inventory InventoryRepository, players PlayerRepository, fees models.FeeSchedule, validate *validator.Validate, client *mongo.Client) *Service {
 	return &Service{
 		auction:   auction,
 		inventory: inventory,
 		players:   players,
+		fees:      fees,
 		validate:  validate,
 		client:    client,
 		now:       func() time.Time { return time.Now().UTC() },
@@ -72,14 +96,20 @@ func (s *Service) CreateListing(ctx context.Context, sellerID string, req models
 	if !item.Tradable {
 		return models.Listing{}, fmt.Errorf("item not tradable: %w", apperrors.ErrConflict)
 	}
+	warning, err := s.priceWarning(ctx, req.ItemID, req.PricePerUnit)
+	if err != nil {
+		return models.Listing{}, err
+	}
 
 	now := s.now()
+	fees := s.fees.For(item.Rarity)
 	listing := models.Listing{
 		ID:           functions.NewUUID(),
 		SellerID:     sellerID,
 		ItemID:       req.ItemID,
 		Qty:          req.Qty,
 		PricePerUnit: req.PricePerUnit,
+		Deposit:      fees.Deposit.Apply(req.Qty * req.PricePerUnit),
 		Status:       models.ListingStatusActive,
 		CreatedAt:    now,
 	}
@@ -89,29 +119,68 @@ func (s *Service) CreateListing(ctx context.Context, sellerID string, req models
 	}
 
 	err = mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+		if err := s.chargeDeposit(txCtx, sellerID, listing.Deposit, listing.ID, now); err != nil {
+			return err
+		}
 		if err := s.inventory.RemoveItem(txCtx, sellerID, req.ItemID, req.Qty, now); err != nil {
 			return fmt.Errorf("remove seller inventory for listing: %w", err)
 		}
 		if err := s.auction.CreateListing(txCtx, listing); err != nil {
 			return fmt.Errorf("create listing: %w", err)
 		}
-		return nil
+		return s.matchListing(txCtx, &listing, fees.Commission, now)
 	})
 	if err != nil {
 		return models.Listing{}, fmt.Errorf("transaction create listing: %w", err)
 	}
 
+	listing.PriceWarning = warning
 	return listing, nil
 }
 
-func (s *Service) ListActive(ctx context.Context, params models.QueryParams) ([]models.Listing, error) {
-	listings, err := s.auction.ListActive(ctx, params)
+func (s *Service) ListActive(ctx context.Context, query models.ListingSearchQuery, params models.QueryParams) ([]models.Listing, error) {
+	if err := s.validate.Struct(query); err != nil {
+		return nil, fmt.Errorf("validate listing search: %w", apperrors.ErrValidation)
+	}
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		return nil, fmt.Errorf("minPrice exceeds maxPrice: %w", apperrors.ErrValidation)
+	}
+	listings, err := s.auction.SearchListings(ctx, query, s.now(), params)
 	if err != nil {
 		return nil, fmt.Errorf("list active listings: %w", err)
 	}
 	return listings, nil
 }
 
+func (s *Service) MyListings(ctx context.Context, sellerID string, params models.QueryParams) ([]models.Listing, error) {
+	listings, err := s.auction.ListListingsBySeller(ctx, sellerID, params)
+	if err != nil {
+		return nil, fmt.Errorf("list seller listings: %w", err)
+	}
+	return listings, nil
+}
+
+func (s *Service) MyTrades(ctx context.Context, playerID string, side models.TradeSide, params models.QueryParams) ([]models.MyTrade, error) {
+	switch side {
+	case "", models.TradeSideBuy, models.TradeSideSell:
+	default:
+		return nil, fmt.Errorf("side must be buy or sell: %w", apperrors.ErrValidation)
+	}
+	trades, err := s.auction.ListTradesByPlayer(ctx, playerID, side, params)
+	if err != nil {
+		return nil, fmt.Errorf("list player trades: %w", err)
+	}
+	out := make([]models.MyTrade, 0, len(trades))
+	for _, t := range trades {
+		mine := models.TradeSideBuy
+		if t.SellerID == playerID {
+			mine = models.TradeSideSell
+		}
+		out = append(out, models.MyTrade{Trade: t, Side: mine})
+	}
+	return out, nil
+}
+
 func (s *Service) Buy(ctx context.Context, buyerID, listingID string, req models.BuyListingRequest) (models.Listing, error) {
 	if err := s.validate.Struct(req); err != nil {
 		return models.Listing{}, fmt.Errorf("validate buy listing: %w", apperrors.ErrValidation)
@@ -133,24 +202,29 @@ func (s *Service) Buy(ctx context.Context, buyerID, listingID string, req models
 		return models.Listing{}, fmt.Errorf("listing expired: %w", apperrors.ErrConflict)
 	}
 
+	item, err := s.inventory.GetItemDef(ctx, listing.ItemID)
+	if err != nil {
+		return models.Listing{}, fmt.Errorf("load item def: %w", err)
+	}
+
 	now := s.now()
 	totalPrice := req.Qty * listing.PricePerUnit
+	trade := models.Trade{
+		ID:         functions.NewUUID(),
+		BuyerID:    buyerID,
+		SellerID:   listing.SellerID,
+		ListingID:  listing.ID,
+		ItemID:     listing.ItemID,
+		Qty:        req.Qty,
+		TotalPrice: totalPrice,
+		Fee:        s.fees.For(item.Rarity).Commission.Apply(totalPrice),
+		CreatedAt:  now,
+	}
 	var out models.Listing
 
 	err = mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
-		buyer, err := s.players.GetByID(txCtx, buyerID)
-		if err != nil {
-			return fmt.Errorf("load buyer: %w", err)
-		}
-		if buyer.Gold < totalPrice {
-			return fmt.Errorf("insufficient funds: %w", apperrors.ErrInsufficient)
-		}
-
-		if _, err := s.players.SetGold(txCtx, buyerID, buyer.Gold-totalPrice, now); err != nil {
-			return fmt.Errorf("debit buyer wallet: %w", err)
-		}
-		if _, err := s.players.IncrementGold(txCtx, listing.SellerID, totalPrice, now); err != nil {
-			return fmt.Errorf("credit seller wallet: %w", err)
+		if err := s.payout(txCtx, models.PlayerAccount(buyerID), trade, now); err != nil {
+			return err
 		}
 		if err := s.inventory.AddItem(txCtx, buyerID, listing.ItemID, req.Qty, now); err != nil {
 			return fmt.Errorf("transfer item to buyer inventory: %w", err)
@@ -167,17 +241,6 @@ func (s *Service) Buy(ctx context.Context, buyerID, listingID string, req models
 		if err != nil {
 			return fmt.Errorf("update listing status after buy: %w", err)
 		}
-
-		trade := models.Trade{
-			ID:         functions.NewUUID(),
-			BuyerID:    buyerID,
-			SellerID:   listing.SellerID,
-			ListingID:  listing.ID,
-			ItemID:     listing.ItemID,
-			Qty:        req.Qty,
-			TotalPrice: totalPrice,
-			CreatedAt:  now,
-		}
 		if err := s.auction.InsertTrade(txCtx, trade); err != nil {
 			return fmt.Errorf("insert trade: %w", err)
 		}
@@ -221,3 +284,88 @@ func (s *Service) Cancel(ctx context.Context, sellerID, listingID string) (model
 	}
 	return out, nil
 }
+
+func (s *Service) Treasury(ctx context.Context) (models.Treasury, error) {
+	treasury, err := s.auction.GetTreasury(ctx)
+	if err != nil {
+		return models.Treasury{}, fmt.Errorf("load treasury: %w", err)
+	}
+	return treasury, nil
+}
+
+// chargeDeposit moves a listing deposit from the seller to the treasury. The
+// deposit is kept whatever happens to the listing.
+func (s *Service) chargeDeposit(txCtx context.Context, sellerID string, deposit int64, ref string, now time.Time) error {
+	if deposit == 0 {
+		return nil
+	}
+	t := models.GoldTransfer{
+		From:   models.PlayerAccount(sellerID),
+		To:     models.AccountTreasury,
+		Amount: deposit,
+		Reason: models.LedgerReasonDeposit,
+		Ref:    ref,
+	}
+	if err := s.players.Transfer(txCtx, t, now); err != nil {
+		return fmt.Errorf("charge listing deposit: %w", err)
+	}
+	return s.auction.CreditTreasury(txCtx, deposit, 0, now)
+}
+
+// payout pays a trade out of from: the seller gets the total minus the
+// commission, which goes to the treasury.
+func (s *Service) payout(txCtx context.Context, from string, trade models.Trade, now time.Time) error {
+	sale := models.GoldTransfer{
+		From:   from,
+		To:     models.PlayerAccount(trade.SellerID),
+		Amount: trade.TotalPrice - trade.Fee,
+		Reason: models.LedgerReasonTrade,
+		Ref:    trade.ID,
+	}
+	if err := s.players.Transfer(txCtx, sale, now); err != nil {
+		return fmt.Errorf("pay seller: %w", err)
+	}
+	if trade.Fee == 0 {
+		return nil
+	}
+	commission := models.GoldTransfer{
+		From:   from,
+		To:     models.AccountTreasury,
+		Amount: trade.Fee,
+		Reason: models.LedgerReasonFee,
+		Ref:    trade.ID,
+	}
+	if err := s.players.Transfer(txCtx, commission, now); err != nil {
+		return fmt.Errorf("collect commission: %w", err)
+	}
+	return s.auction.CreditTreasury(txCtx, 0, trade.Fee, now)
+}
+
+// hold moves gold from a player into escrow; release gives it back.
+func (s *Service) hold(txCtx context.Context, playerID string, amount int64, ref string, now time.Time) error {
+	t := models.GoldTransfer{
+		From:   models.PlayerAccount(playerID),
+		To:     models.AccountEscrow,
+		Amount: amount,
+		Reason: models.LedgerReasonEscrow,
+		Ref:    ref,
+	}
+	if err := s.players.Transfer(txCtx, t, now); err != nil {
+		return fmt.Errorf("escrow gold: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) release(txCtx context.Context, playerID string, amount int64, ref string, now time.Time) error {
+	t := models.GoldTransfer{
+		From:   models.AccountEscrow,
+		To:     models.PlayerAccount(playerID),
+		Amount: amount,
+		Reason: models.LedgerReasonRefund,
+		Ref:    ref,
+	}
+	if err := s.players.Transfer(txCtx, t, now); err != nil {
+		return fmt.Errorf("release escrowed gold: %w", err)
+	}
+	return nil
+}
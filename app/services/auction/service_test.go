@@ -0,0 +1,30 @@
+package auction
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/models"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestCreateListingRejectsOversizedListing(t *testing.T) {
+	wallets := walletStub{gold: map[string]int64{}}
+	bags := bagStub{items: map[string]int64{}}
+	svc := &Service{auction: &bookStub{}, players: wallets, inventory: bags, validate: validator.New(), now: time.Now}
+
+	for _, req := range []models.CreateListingRequest{
+		{ItemID: "gem", Qty: 2, PricePerUnit: 1<<62 + 1},
+		{ItemID: "gem", Qty: 1000001, PricePerUnit: 1},
+	} {
+		if _, err := svc.CreateListing(context.Background(), "seller", req); !errors.Is(err, apperrors.ErrValidation) {
+			t.Fatalf("CreateListing(%d x %d) = %v, want validation error", req.Qty, req.PricePerUnit, err)
+		}
+	}
+	if len(wallets.gold) != 0 || len(bags.items) != 0 {
+		t.Fatalf("gold %v and items %v moved", wallets.gold, bags.items)
+	}
+}
@@ -0,0 +1,391 @@
+package campaign
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/functions"
+	"dungeons/app/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type Repository interface {
+	EnsureIndexes(ctx context.Context) error
+	CreateCampaign(ctx context.Context, c models.Campaign) error
+	ReplaceCampaign(ctx context.Context, c models.Campaign) (models.Campaign, error)
+	GetCampaignByID(ctx context.Context, id string) (models.Campaign, error)
+	ListCampaigns(ctx context.Context, filter bson.M, params models.QueryParams) ([]models.Campaign, error)
+	ListPublishedByDungeon(ctx context.Context, dungeonID string) ([]models.Campaign, error)
+	GetCompletion(ctx context.Context, campaignID, playerID string) (models.CampaignCompletion, error)
+	CreateCompletion(ctx context.Context, c models.CampaignCompletion) error
+}
+
+type DungeonRepository interface {
+	GetDungeonByID(ctx context.Context, id string) (models.Dungeon, error)
+}
+
+type RunRepository interface {
+	HasCompletedRun(ctx context.Context, playerID, dungeonID string) (bool, error)
+}
+
+type PlayerEconomyRepository interface {
+	Transfer(ctx context.Context, t models.GoldTransfer, at time.Time) error
+}
+
+type InventoryRepository interface {
+	ListInventory(ctx context.Context, playerID string) ([]models.InventoryEntry, error)
+	AddItem(ctx context.Context, playerID, itemID string, qty int64, updatedAt time.Time) error
+}
+
+type Service struct {
+	repo      Repository
+	dungeons  DungeonRepository
+	runs      RunRepository
+	players   PlayerEconomyRepository
+	inventory InventoryRepository
+	validate  *validator.Validate
+	now       func() time.Time
+}
+
+func New(repo Repository, dungeons DungeonRepository, runs RunRepository, players PlayerEconomyRepository, inventory InventoryRepository, validate *validator.Validate) *Service {
+	return &Service{
+		repo:      repo,
+		dungeons:  dungeons,
+		runs:      runs,
+		players:   players,
+		inventory: inventory,
+		validate:  validate,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (s *Service) EnsureIndexes(ctx context.Context) error {
+	if err := s.repo.EnsureIndexes(ctx); err != nil {
+		return fmt.Errorf("campaign ensure indexes: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) CreateCampaign(ctx context.Context, mjID string, req models.CampaignRequest) (models.Campaign, error) {
+	entries, err := s.buildEntries(ctx, mjID, req)
+	if err != nil {
+		return models.Campaign{}, err
+	}
+	now := s.now()
+	c := models.Campaign{
+		ID:          functions.NewUUID(),
+		Title:       req.Title,
+		Description: req.Description,
+		CreatedBy:   mjID,
+		Status:      models.CampaignStatusDraft,
+		Entries:     entries,
+		Rewards:     req.Rewards,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.CreateCampaign(ctx, c); err != nil {
+		return models.Campaign{}, fmt.Errorf("create campaign: %w", err)
+	}
+	return c, nil
+}
+
+func (s *Service) UpdateCampaign(ctx context.Context, mjID, campaignID string, req models.CampaignRequest) (models.Campaign, error) {
+	c, err := s.owned(ctx, mjID, campaignID)
+	if err != nil {
+		return models.Campaign{}, err
+	}
+	entries, err := s.buildEntries(ctx, mjID, req)
+	if err != nil {
+		return models.Campaign{}, err
+	}
+	c.Title = req.Title
+	c.Description = req.Description
+	c.Entries = entries
+	c.Rewards = req.Rewards
+	return s.save(ctx, c)
+}
+
+func (s *Service) SetStatus(ctx context.Context, mjID, campaignID string, status models.CampaignStatus) (models.Campaign, error) {
+	c, err := s.owned(ctx, mjID, campaignID)
+	if err != nil {
+		return models.Campaign{}, err
+	}
+	c.Status = status
+	return s.save(ctx, c)
+}
+
+func (s *Service) ListMine(ctx context.Context, mjID string, params models.QueryParams) ([]models.Campaign, error) {
+	out, err := s.repo.ListCampaigns(ctx, bson.M{"createdBy": mjID}, params)
+	if err != nil {
+		return nil, fmt.Errorf("list my campaigns: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Service) ListPublished(ctx context.Context, params models.QueryParams) ([]models.Campaign, error) {
+	out, err := s.repo.ListCampaigns(ctx, bson.M{"status": models.CampaignStatusPublished}, params)
+	if err != nil {
+		return nil, fmt.Errorf("list campaigns: %w", err)
+	}
+	return out, nil
+}
+
+// Progress shows where the player stands in the chain. Drafts are only
+// visible to their author.
+func (s *Service) Progress(ctx context.Context, playerID, campaignID string) (models.CampaignProgress, error) {
+	c, err := s.repo.GetCampaignByID(ctx, campaignID)
+	if err != nil {
+		return models.CampaignProgress{}, fmt.Errorf("get campaign: %w", err)
+	}
+	if c.Status != models.CampaignStatusPublished && c.CreatedBy != playerID {
+		return models.CampaignProgress{}, fmt.Errorf("campaign is not published: %w", apperrors.ErrNotFound)
+	}
+	st, err := s.playerState(ctx, playerID, c)
+	if err != nil {
+		return models.CampaignProgress{}, err
+	}
+	out := models.CampaignProgress{Campaign: c, Entries: make([]models.CampaignEntryProgress, 0, len(c.Entries)), Total: len(c.Entries)}
+	for i, e := range c.Entries {
+		entry := models.CampaignEntryProgress{
+			Order:     i + 1,
+			DungeonID: e.DungeonID,
+			Unlock:    e.Unlock,
+			Unlocked:  st.unlocked(c, i),
+			Completed: st.completed[e.DungeonID],
+		}
+		if d, err := s.dungeons.GetDungeonByID(ctx, e.DungeonID); err == nil {
+			entry.Title = d.Title
+		}
+		if entry.Completed {
+			out.Completed++
+		}
+		out.Entries = append(out.Entries, entry)
+	}
+	completion, err := s.repo.GetCompletion(ctx, c.ID, playerID)
+	if err == nil {
+		out.Completion = &completion
+	} else if !errors.Is(err, apperrors.ErrNotFound) {
+		return models.CampaignProgress{}, err
+	}
+	return out, nil
+}
+
+// CheckUnlocked lets a run start when the dungeon belongs to no published
+// campaign, or is unlocked in at least one of them.
+func (s *Service) CheckUnlocked(ctx context.Context, playerID, dungeonID string) error {
+	campaigns, err := s.repo.ListPublishedByDungeon(ctx, dungeonID)
+	if err != nil {
+		return err
+	}
+	for _, c := range campaigns {
+		st, err := s.playerState(ctx, playerID, c)
+		if err != nil {
+			return err
+		}
+		for i, e := range c.Entries {
+			if e.DungeonID == dungeonID && st.unlocked(c, i) {
+				return nil
+			}
+		}
+	}
+	if len(campaigns) == 0 {
+		return nil
+	}
+	return fmt.Errorf("dungeon %s is locked in campaign %s: %w", dungeonID, campaigns[0].ID, apperrors.ErrLocked)
+}
+
+// CompleteDungeon pays the rewards of every campaign the player has just
+// finished. It runs inside the attempt transaction, after the run was
+// marked completed, so that run counts.
+func (s *Service) CompleteDungeon(ctx context.Context, playerID, dungeonID string, now time.Time) ([]models.CampaignCompletion, error) {
+	campaigns, err := s.repo.ListPublishedByDungeon(ctx, dungeonID)
+	if err != nil {
+		return nil, err
+	}
+	var out []models.CampaignCompletion
+	for _, c := range campaigns {
+		if _, err := s.repo.GetCompletion(ctx, c.ID, playerID); err == nil {
+			continue
+		} else if !errors.Is(err, apperrors.ErrNotFound) {
+			return nil, err
+		}
+		done := true
+		for _, e := range c.Entries {
+			ok, err := s.runs.HasCompletedRun(ctx, playerID, e.DungeonID)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				done = false
+				break
+			}
+		}
+		if !done {
+			continue
+		}
+		completion := models.CampaignCompletion{
+			ID:          functions.NewUUID(),
+			CampaignID:  c.ID,
+			PlayerID:    playerID,
+			Rewards:     c.Rewards,
+			CompletedAt: now,
+		}
+		if err := s.repo.CreateCompletion(ctx, completion); err != nil {
+			return nil, err
+		}
+		reward := models.GoldTransfer{
+			From:   models.AccountMint,
+			To:     models.PlayerAccount(playerID),
+			Amount: c.Rewards.Gold,
+			Reason: models.LedgerReasonCampaignReward,
+			Ref:    completion.ID,
+		}
+		if err := s.players.Transfer(ctx, reward, now); err != nil {
+			return nil, fmt.Errorf("apply campaign gold: %w", err)
+		}
+		for _, item := range c.Rewards.Items {
+			if err := s.inventory.AddItem(ctx, playerID, item.ItemID, item.Qty, now); err != nil {
+				return nil, fmt.Errorf("apply campaign item %s: %w", item.ItemID, err)
+			}
+		}
+		out = append(out, completion)
+	}
+	return out, nil
+}
+
+func (s *Service) buildEntries(ctx context.Context, mjID string, req models.CampaignRequest) ([]models.CampaignEntry, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return nil, fmt.Errorf("validate campaign: %w", apperrors.ErrValidation)
+	}
+	if req.Rewards.Gold < 0 {
+		return nil, fmt.Errorf("negative campaign gold: %w", apperrors.ErrValidation)
+	}
+	for _, item := range req.Rewards.Items {
+		if item.ItemID == "" || item.Qty <= 0 {
+			return nil, fmt.Errorf("invalid campaign reward item: %w", apperrors.ErrValidation)
+		}
+	}
+	seen := make(map[string]bool, len(req.Entries))
+	entries := make([]models.CampaignEntry, 0, len(req.Entries))
+	for i, e := range req.Entries {
+		if seen[e.DungeonID] {
+			return nil, fmt.Errorf("dungeon %s listed twice: %w", e.DungeonID, apperrors.ErrValidation)
+		}
+		seen[e.DungeonID] = true
+		d, err := s.dungeons.GetDungeonByID(ctx, e.DungeonID)
+		if err != nil {
+			return nil, fmt.Errorf("get campaign dungeon: %w", err)
+		}
+		if !d.RoleOf(mjID).Allows(models.CollaboratorEditor) {
+			return nil, fmt.Errorf("cannot add foreign dungeon %s: %w", e.DungeonID, apperrors.ErrForbidden)
+		}
+		rules := e.Unlock
+		if rules == nil && i > 0 {
+			rules = []models.UnlockRule{{Kind: models.UnlockCompletePrevious}}
+		}
+		if rules == nil {
+			rules = make([]models.UnlockRule, 0)
+		}
+		for _, r := range rules {
+			if r.Kind == models.UnlockCompletePrevious && i == 0 {
+				return nil, fmt.Errorf("first entry has no previous dungeon: %w", apperrors.ErrValidation)
+			}
+		}
+		entries = append(entries, models.CampaignEntry{DungeonID: e.DungeonID, Unlock: rules})
+	}
+	return entries, nil
+}
+
+func (s *Service) owned(ctx context.Context, mjID, campaignID string) (models.Campaign, error) {
+	c, err := s.repo.GetCampaignByID(ctx, campaignID)
+	if err != nil {
+		return c, fmt.Errorf("get campaign: %w", err)
+	}
+	if c.CreatedBy != mjID {
+		return c, fmt.Errorf("campaign owner mismatch: %w", apperrors.ErrForbidden)
+	}
+	return c, nil
+}
+
+func (s *Service) save(ctx context.Context, c models.Campaign) (models.Campaign, error) {
+	c.UpdatedAt = s.now()
+	updated, err := s.repo.ReplaceCampaign(ctx, c)
+	if err != nil {
+		return models.Campaign{}, fmt.Errorf("update campaign: %w", err)
+	}
+	return updated, nil
+}
+
+type playerState struct {
+	completed map[string]bool
+	items     map[string]int64
+}
+
+func (s *Service) playerState(ctx context.Context, playerID string, c models.Campaign) (playerState, error) {
+	st := playerState{completed: map[string]bool{}, items: map[string]int64{}}
+	check := func(dungeonID string) error {
+		if _, ok := st.completed[dungeonID]; ok {
+			return nil
+		}
+		done, err := s.runs.HasCompletedRun(ctx, playerID, dungeonID)
+		if err != nil {
+			return fmt.Errorf("check completed run: %w", err)
+		}
+		st.completed[dungeonID] = done
+		return nil
+	}
+	needItems := false
+	for _, e := range c.Entries {
+		if err := check(e.DungeonID); err != nil {
+			return st, err
+		}
+		for _, r := range e.Unlock {
+			switch r.Kind {
+			case models.UnlockCompleteDungeon:
+				if err := check(r.DungeonID); err != nil {
+					return st, err
+				}
+			case models.UnlockHoldItem:
+				needItems = true
+			}
+		}
+	}
+	if needItems {
+		entries, err := s.inventory.ListInventory(ctx, playerID)
+		if err != nil {
+			return st, fmt.Errorf("list inventory: %w", err)
+		}
+		for _, e := range entries {
+			st.items[e.ItemID] += e.Qty
+		}
+	}
+	return st, nil
+}
+
+func (st playerState) unlocked(c models.Campaign, i int) bool {
+	rules := c.Entries[i].Unlock
+	if len(rules) == 0 {
+		return true
+	}
+	for _, r := range rules {
+		switch r.Kind {
+		case models.UnlockCompletePrevious:
+			if i > 0 && st.completed[c.Entries[i-1].DungeonID] {
+				return true
+			}
+		case models.UnlockCompleteDungeon:
+			if st.completed[r.DungeonID] {
+				return true
+			}
+		case models.UnlockHoldItem:
+			if st.items[r.ItemID] >= max(r.Qty, 1) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package campaign
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/models"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type repoStub struct {
+	campaigns []models.Campaign
+}
+
+func (s *repoStub) EnsureIndexes(context.Context) error                   { return nil }
+func (s *repoStub) CreateCampaign(context.Context, models.Campaign) error { return nil }
+func (s *repoStub) ReplaceCampaign(_ context.Context, c models.Campaign) (models.Campaign, error) {
+	return c, nil
+}
+func (s *repoStub) GetCampaignByID(context.Context, string) (models.Campaign, error) {
+	return s.campaigns[0], nil
+}
+func (s *repoStub) ListCampaigns(context.Context, bson.M, models.QueryParams) ([]models.Campaign, error) {
+	return s.campaigns, nil
+}
+func (s *repoStub) ListPublishedByDungeon(context.Context, string) ([]models.Campaign, error) {
+	return s.campaigns, nil
+}
+func (s *repoStub) GetCompletion(context.Context, string, string) (models.CampaignCompletion, error) {
+	return models.CampaignCompletion{}, apperrors.ErrNotFound
+}
+func (s *repoStub) CreateCompletion(context.Context, models.CampaignCompletion) error { return nil }
+
+type runStub map[string]bool
+
+func (s runStub) HasCompletedRun(_ context.Context, _, dungeonID string) (bool, error) {
+	return s[dungeonID], nil
+}
+
+type economyStub struct {
+	items []models.InventoryEntry
+}
+
+func (economyStub) Transfer(context.Context, models.GoldTransfer, time.Time) error {
+	return nil
+}
+func (s economyStub) ListInventory(context.Context, string) ([]models.InventoryEntry, error) {
+	return s.items, nil
+}
+func (economyStub) AddItem(context.Context, string, string, int64, time.Time) error { return nil }
+
+func TestCheckUnlockedFollowsChain(t *testing.T) {
+	chain := models.Campaign{ID: "c-1", Status: models.CampaignStatusPublished, Entries: []models.CampaignEntry{
+		{DungeonID: "d-1", Unlock: []models.UnlockRule{}},
+		{DungeonID: "d-2", Unlock: []models.UnlockRule{{Kind: models.UnlockCompletePrevious}}},
+		{DungeonID: "d-3", Unlock: []models.UnlockRule{{Kind: models.UnlockCompletePrevious}, {Kind: models.UnlockHoldItem, ItemID: "key", Qty: 1}}},
+	}}
+	repo := &repoStub{campaigns: []models.Campaign{chain}}
+	svc := New(repo, nil, runStub{"d-1": true}, economyStub{}, economyStub{}, validator.New())
+
+	if err := svc.CheckUnlocked(context.Background(), "p-1", "d-2"); err != nil {
+		t.Fatalf("d-2 should be unlocked after d-1: %v", err)
+	}
+	if err := svc.CheckUnlocked(context.Background(), "p-1", "d-3"); !errors.Is(err, apperrors.ErrLocked) {
+		t.Fatalf("expected d-3 locked, got %v", err)
+	}
+
+	svc = New(repo, nil, runStub{"d-1": true}, economyStub{}, economyStub{items: []models.InventoryEntry{{ItemID: "key", Qty: 1}}}, validator.New())
+	if err := svc.CheckUnlocked(context.Background(), "p-1", "d-3"); err != nil {
+		t.Fatalf("holding the key should unlock d-3: %v", err)
+	}
+}
+
+func TestCompleteDungeonNeedsWholeChain(t *testing.T) {
+	chain := models.Campaign{ID: "c-1", Status: models.CampaignStatusPublished, Rewards: models.Rewards{Gold: 500}, Entries: []models.CampaignEntry{
+		{DungeonID: "d-1"}, {DungeonID: "d-2"},
+	}}
+	repo := &repoStub{campaigns: []models.Campaign{chain}}
+	now := time.Now()
+
+	svc := New(repo, nil, runStub{"d-2": true}, economyStub{}, economyStub{}, validator.New())
+	done, err := svc.CompleteDungeon(context.Background(), "p-1", "d-2", now)
+	if err != nil || len(done) != 0 {
+		t.Fatalf("campaign must not complete with d-1 missing: %v %v", done, err)
+	}
+
+	svc = New(repo, nil, runStub{"d-1": true, "d-2": true}, economyStub{}, economyStub{}, validator.New())
+	done, err = svc.CompleteDungeon(context.Background(), "p-1", "d-2", now)
+	if err != nil || len(done) != 1 || done[0].Rewards.Gold != 500 {
+		t.Fatalf("expected one completion paying 500 gold, got %v %v", done, err)
+	}
+}
@@ -0,0 +1,140 @@
+package dungeon
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/functions"
+	"dungeons/app/geo"
+	"dungeons/app/models"
+	"dungeons/app/mongodb"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func (s *Service) SetTemplate(ctx context.Context, mjID, dungeonID string, isTemplate bool) (models.Dungeon, error) {
+	d, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorOwner)
+	if err != nil {
+		return models.Dungeon{}, err
+	}
+	if isTemplate {
+		steps, err := s.repo.ListStepsByDungeon(ctx, dungeonID)
+		if err != nil {
+			return models.Dungeon{}, fmt.Errorf("list steps: %w", err)
+		}
+		if len(steps) == 0 {
+			return models.Dungeon{}, fmt.Errorf("cannot template empty dungeon: %w", apperrors.ErrValidation)
+		}
+	}
+	d.IsTemplate = isTemplate
+	d.UpdatedAt = s.now()
+	updated, err := s.repo.UpdateDungeon(ctx, d)
+	if err != nil {
+		return models.Dungeon{}, fmt.Errorf("update template flag: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *Service) ListTemplates(ctx context.Context, params models.QueryParams) ([]models.Dungeon, error) {
+	list, err := s.repo.ListDungeonsByFilter(ctx, bson.M{"isTemplate": true}, params)
+	if err != nil {
+		return nil, fmt.Errorf("list templates: %w", err)
+	}
+	return list, nil
+}
+
+func (s *Service) CloneDungeon(ctx context.Context, mjID, dungeonID string, req models.CloneDungeonRequest) (models.Dungeon, []models.BossStep, error) {
+	src, err := s.repo.GetDungeonByID(ctx, dungeonID)
+	if err != nil {
+		return models.Dungeon{}, nil, fmt.Errorf("get dungeon: %w", err)
+	}
+	if !src.IsTemplate {
+		if err := checkAccess(src, mjID, models.CollaboratorViewer); err != nil {
+			return models.Dungeon{}, nil, err
+		}
+	}
+	return s.clone(ctx, mjID, src, req)
+}
+
+func (s *Service) InstantiateTemplate(ctx context.Context, mjID, templateID string, req models.CloneDungeonRequest) (models.Dungeon, []models.BossStep, error) {
+	src, err := s.repo.GetDungeonByID(ctx, templateID)
+	if err != nil {
+		return models.Dungeon{}, nil, fmt.Errorf("get template: %w", err)
+	}
+	if !src.IsTemplate {
+		return models.Dungeon{}, nil, fmt.Errorf("dungeon %s is not a template: %w", templateID, apperrors.ErrNotFound)
+	}
+	return s.clone(ctx, mjID, src, req)
+}
+
+func (s *Service) clone(ctx context.Context, mjID string, src models.Dungeon, req models.CloneDungeonRequest) (models.Dungeon, []models.BossStep, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return models.Dungeon{}, nil, fmt.Errorf("validate clone dungeon: %w", apperrors.ErrValidation)
+	}
+	srcSteps, err := s.repo.ListStepsByDungeon(ctx, src.ID)
+	if err != nil {
+		return models.Dungeon{}, nil, fmt.Errorf("list steps: %w", err)
+	}
+
+	now := s.now()
+	d := src
+	d.ID = functions.NewUUID()
+	d.CreatedBy = mjID
+	d.Status = models.DungeonStatusDraft
+	d.IsTemplate = false
+	d.Collaborators = nil
+	d.ClonedFrom = src.ID
+	d.PublishAt = nil
+	d.UnpublishAt = nil
+	d.ScheduleError = ""
+	d.CreatedAt = now
+	d.UpdatedAt = now
+	if req.Title != "" {
+		d.Title = req.Title
+	}
+	if req.AreaName != "" {
+		d.AreaName = req.AreaName
+	}
+
+	steps := make([]models.BossStep, 0, len(srcSteps))
+	for _, st := range srcSteps {
+		step := st
+		step.ID = functions.NewUUID()
+		step.DungeonID = d.ID
+		step.Rewards.Items = append([]models.RewardItem(nil), st.Rewards.Items...)
+		// A copy must never accept the source dungeon's printed codes.
+		if st.Proof != nil {
+			proof := *st.Proof
+			proof.Version = 0
+			if err := s.newProofSecret(&proof); err != nil {
+				return models.Dungeon{}, nil, err
+			}
+			step.Proof = &proof
+		}
+		step.CreatedAt = now
+		step.UpdatedAt = now
+		// The first step is the route anchor: every other boss keeps its
+		// distance and bearing from it.
+		if req.Anchor != nil && len(srcSteps) > 0 {
+			origin := srcSteps[0].Location
+			step.Location.Lat, step.Location.Lon = geo.Translate(origin.Lat, origin.Lon, req.Anchor.Lat, req.Anchor.Lon, st.Location.Lat, st.Location.Lon)
+		}
+		steps = append(steps, step)
+	}
+
+	d.Stats = computeStats(steps)
+
+	err = mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+		if err := s.repo.CreateDungeon(txCtx, d); err != nil {
+			return fmt.Errorf("create cloned dungeon: %w", err)
+		}
+		if err := s.repo.CreateSteps(txCtx, steps); err != nil {
+			return fmt.Errorf("create cloned steps: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Dungeon{}, nil, fmt.Errorf("transaction clone dungeon: %w", err)
+	}
+	return d, steps, nil
+}
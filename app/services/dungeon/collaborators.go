@@ -0,0 +1,149 @@
+package dungeon
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/models"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// authorize is the single permission gate for MJ operations on a dungeon.
+func (s *Service) authorize(ctx context.Context, mjID, dungeonID string, need models.CollaboratorRole) (models.Dungeon, error) {
+	d, err := s.repo.GetDungeonByID(ctx, dungeonID)
+	if err != nil {
+		return models.Dungeon{}, fmt.Errorf("get dungeon: %w", err)
+	}
+	if err := checkAccess(d, mjID, need); err != nil {
+		return models.Dungeon{}, err
+	}
+	return d, nil
+}
+
+func checkAccess(d models.Dungeon, mjID string, need models.CollaboratorRole) error {
+	if !d.RoleOf(mjID).Allows(need) {
+		return fmt.Errorf("%s access required on dungeon %s: %w", need, d.ID, apperrors.ErrForbidden)
+	}
+	return nil
+}
+
+func (s *Service) ListMine(ctx context.Context, mjID string, params models.QueryParams) ([]models.Dungeon, error) {
+	list, err := s.repo.ListDungeonsByFilter(ctx, bson.M{"$or": []bson.M{
+		{"createdBy": mjID},
+		{"collaborators.playerId": mjID},
+	}}, params)
+	if err != nil {
+		return nil, fmt.Errorf("list mj dungeons: %w", err)
+	}
+	return list, nil
+}
+
+func (s *Service) ListCollaborators(ctx context.Context, mjID, dungeonID string) ([]models.Collaborator, error) {
+	d, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorViewer)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.Collaborator, 0, len(d.Collaborators)+1)
+	out = append(out, models.Collaborator{PlayerID: d.CreatedBy, Role: models.CollaboratorOwner, AddedAt: d.CreatedAt})
+	out = append(out, d.Collaborators...)
+	return out, nil
+}
+
+func (s *Service) AddCollaborator(ctx context.Context, mjID, dungeonID string, req models.AddCollaboratorRequest) (models.Dungeon, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return models.Dungeon{}, fmt.Errorf("validate add collaborator: %w", apperrors.ErrValidation)
+	}
+	d, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorOwner)
+	if err != nil {
+		return models.Dungeon{}, err
+	}
+	if req.PlayerID == d.CreatedBy {
+		return models.Dungeon{}, fmt.Errorf("owner is already a collaborator: %w", apperrors.ErrConflict)
+	}
+	if err := s.requireMJ(ctx, req.PlayerID); err != nil {
+		return models.Dungeon{}, err
+	}
+	now := s.now()
+	collaborators := withoutCollaborator(d.Collaborators, req.PlayerID)
+	d.Collaborators = append(collaborators, models.Collaborator{PlayerID: req.PlayerID, Role: req.Role, AddedAt: now})
+	d.UpdatedAt = now
+	updated, err := s.repo.UpdateDungeon(ctx, d)
+	if err != nil {
+		return models.Dungeon{}, fmt.Errorf("add collaborator: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *Service) RemoveCollaborator(ctx context.Context, mjID, dungeonID, playerID string) (models.Dungeon, error) {
+	// Collaborators may always leave; only the owner may remove someone else.
+	need := models.CollaboratorOwner
+	if playerID == mjID {
+		need = models.CollaboratorViewer
+	}
+	d, err := s.authorize(ctx, mjID, dungeonID, need)
+	if err != nil {
+		return models.Dungeon{}, err
+	}
+	if playerID == d.CreatedBy {
+		return models.Dungeon{}, fmt.Errorf("owner cannot be removed, transfer ownership first: %w", apperrors.ErrConflict)
+	}
+	collaborators := withoutCollaborator(d.Collaborators, playerID)
+	if len(collaborators) == len(d.Collaborators) {
+		return models.Dungeon{}, fmt.Errorf("collaborator %s: %w", playerID, apperrors.ErrNotFound)
+	}
+	d.Collaborators = collaborators
+	d.UpdatedAt = s.now()
+	updated, err := s.repo.UpdateDungeon(ctx, d)
+	if err != nil {
+		return models.Dungeon{}, fmt.Errorf("remove collaborator: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *Service) TransferOwnership(ctx context.Context, mjID, dungeonID string, req models.TransferOwnershipRequest) (models.Dungeon, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return models.Dungeon{}, fmt.Errorf("validate transfer ownership: %w", apperrors.ErrValidation)
+	}
+	d, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorOwner)
+	if err != nil {
+		return models.Dungeon{}, err
+	}
+	if req.PlayerID == d.CreatedBy {
+		return models.Dungeon{}, fmt.Errorf("player already owns dungeon: %w", apperrors.ErrConflict)
+	}
+	if err := s.requireMJ(ctx, req.PlayerID); err != nil {
+		return models.Dungeon{}, err
+	}
+	now := s.now()
+	collaborators := withoutCollaborator(d.Collaborators, req.PlayerID)
+	d.Collaborators = append(collaborators, models.Collaborator{PlayerID: d.CreatedBy, Role: models.CollaboratorEditor, AddedAt: now})
+	d.CreatedBy = req.PlayerID
+	d.UpdatedAt = now
+	updated, err := s.repo.UpdateDungeon(ctx, d)
+	if err != nil {
+		return models.Dungeon{}, fmt.Errorf("transfer ownership: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *Service) requireMJ(ctx context.Context, playerID string) error {
+	p, err := s.players.GetByID(ctx, playerID)
+	if err != nil {
+		return fmt.Errorf("get collaborator player: %w", err)
+	}
+	if p.Role != models.RoleMJ {
+		return fmt.Errorf("player %s is not an mj: %w", playerID, apperrors.ErrValidation)
+	}
+	return nil
+}
+
+func withoutCollaborator(list []models.Collaborator, playerID string) []models.Collaborator {
+	out := make([]models.Collaborator, 0, len(list))
+	for _, c := range list {
+		if c.PlayerID != playerID {
+			out = append(out, c)
+		}
+	}
+	return out
+}
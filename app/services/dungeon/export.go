@@ -0,0 +1,41 @@
+package dungeon
+
+import (
+	"context"
+	"dungeons/app/geo"
+	"dungeons/app/models"
+
+	"golang.org/x/text/language"
+)
+
+const circleSegments = 64
+
+// MapGeoJSON draws each step a player can see as its catch zone.
+func (s *Service) MapGeoJSON(ctx context.Context, id string, prefs []language.Tag) (models.FeatureCollection, error) {
+	d, steps, err := s.GetPublishedByID(ctx, id, prefs)
+	if err != nil {
+		return models.FeatureCollection{}, err
+	}
+	features := make([]models.Feature, 0, len(steps))
+	for _, st := range steps {
+		ring := geo.Circle(st.Location.Lat, st.Location.Lon, st.Location.RadiusMeters, circleSegments)
+		features = append(features, models.Feature{
+			Type:     "Feature",
+			ID:       st.ID,
+			Geometry: models.Geometry{Type: "Polygon", Coordinates: [][][2]float64{ring}},
+			Properties: map[string]any{
+				"dungeonId":       d.ID,
+				"dungeonTitle":    d.Title,
+				"order":           st.Order,
+				"name":            st.Name,
+				"type":            st.Kind(),
+				"zoneDescription": st.ZoneDescription,
+				"difficulty":      st.Difficulty,
+				"center":          [2]float64{st.Location.Lon, st.Location.Lat},
+				"radiusMeters":    st.Location.RadiusMeters,
+				"lang":            st.Lang,
+			},
+		})
+	}
+	return models.NewFeatureCollection(features), nil
+}
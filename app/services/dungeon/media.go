@@ -0,0 +1,66 @@
+package dungeon
+
+import (
+	"context"
+	"dungeons/app/models"
+	"fmt"
+	"io"
+)
+
+// Replaced images stay in the store: their URLs are content-addressed and
+// may be shared with clones or still cached by clients.
+//
+// Only the image field is written, so a slow upload never overwrites edits
+// made meanwhile.
+
+func (s *Service) SetCover(ctx context.Context, mjID, dungeonID string, r io.Reader) (models.Dungeon, error) {
+	if _, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorEditor); err != nil {
+		return models.Dungeon{}, err
+	}
+	img, err := s.images.Store(ctx, r)
+	if err != nil {
+		return models.Dungeon{}, fmt.Errorf("store cover: %w", err)
+	}
+	return s.setCover(ctx, dungeonID, &img)
+}
+
+func (s *Service) RemoveCover(ctx context.Context, mjID, dungeonID string) (models.Dungeon, error) {
+	if _, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorEditor); err != nil {
+		return models.Dungeon{}, err
+	}
+	return s.setCover(ctx, dungeonID, nil)
+}
+
+func (s *Service) SetStepArt(ctx context.Context, mjID, dungeonID, stepID string, r io.Reader) (models.BossStep, error) {
+	if _, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorEditor); err != nil {
+		return models.BossStep{}, err
+	}
+	img, err := s.images.Store(ctx, r)
+	if err != nil {
+		return models.BossStep{}, fmt.Errorf("store step art: %w", err)
+	}
+	return s.setStepArt(ctx, dungeonID, stepID, &img)
+}
+
+func (s *Service) RemoveStepArt(ctx context.Context, mjID, dungeonID, stepID string) (models.BossStep, error) {
+	if _, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorEditor); err != nil {
+		return models.BossStep{}, err
+	}
+	return s.setStepArt(ctx, dungeonID, stepID, nil)
+}
+
+func (s *Service) setCover(ctx context.Context, dungeonID string, img *models.Image) (models.Dungeon, error) {
+	d, err := s.repo.SetCover(ctx, dungeonID, img, s.now())
+	if err != nil {
+		return models.Dungeon{}, fmt.Errorf("update dungeon cover: %w", err)
+	}
+	return d, nil
+}
+
+func (s *Service) setStepArt(ctx context.Context, dungeonID, stepID string, img *models.Image) (models.BossStep, error) {
+	step, err := s.repo.SetStepArt(ctx, dungeonID, stepID, img, s.now())
+	if err != nil {
+		return models.BossStep{}, fmt.Errorf("update step art: %w", err)
+	}
+	return step, nil
+}
@@ -0,0 +1,149 @@
+package dungeon
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/models"
+	"dungeons/app/otp"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	codeIssuer        = "Dungeons"
+	defaultCodePeriod = 30
+)
+
+func (s *Service) SetProofPolicy(ctx context.Context, mjID, dungeonID, stepID string, req models.ProofPolicyRequest) (models.BossStep, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return models.BossStep{}, fmt.Errorf("validate proof policy: %w", apperrors.ErrValidation)
+	}
+	step, err := s.editableStep(ctx, mjID, dungeonID, stepID)
+	if err != nil {
+		return models.BossStep{}, err
+	}
+	if req.Require == models.ProofGPS {
+		step.Proof = nil
+		return s.saveStep(ctx, step)
+	}
+
+	policy := models.ProofPolicy{Require: req.Require, Mode: req.Mode}
+	if policy.Mode == "" {
+		policy.Mode = models.CodeModeStatic
+	}
+	if policy.Mode == models.CodeModeTOTP {
+		policy.PeriodSeconds = req.PeriodSeconds
+		if policy.PeriodSeconds == 0 {
+			policy.PeriodSeconds = defaultCodePeriod
+		}
+	}
+	// Changing the policy keeps the secret so printed tags stay valid.
+	if step.Proof != nil {
+		policy.Secret, policy.Version, policy.RotatedAt = step.Proof.Secret, step.Proof.Version, step.Proof.RotatedAt
+	}
+	step.Proof = &policy
+	if policy.Secret == "" {
+		if err := s.newProofSecret(step.Proof); err != nil {
+			return models.BossStep{}, err
+		}
+	}
+	return s.saveStep(ctx, step)
+}
+
+func (s *Service) RotateProofCode(ctx context.Context, mjID, dungeonID, stepID string) (models.BossStep, error) {
+	step, err := s.editableStep(ctx, mjID, dungeonID, stepID)
+	if err != nil {
+		return models.BossStep{}, err
+	}
+	if _, needCode := step.Proofs(); !needCode {
+		return models.BossStep{}, fmt.Errorf("step %s does not use codes: %w", stepID, apperrors.ErrValidation)
+	}
+	if err := s.newProofSecret(step.Proof); err != nil {
+		return models.BossStep{}, err
+	}
+	return s.saveStep(ctx, step)
+}
+
+// RevokeProofCode drops the secret; the step falls back to GPS-only until a
+// new code policy is set.
+func (s *Service) RevokeProofCode(ctx context.Context, mjID, dungeonID, stepID string) (models.BossStep, error) {
+	step, err := s.editableStep(ctx, mjID, dungeonID, stepID)
+	if err != nil {
+		return models.BossStep{}, err
+	}
+	if step.Proof == nil {
+		return models.BossStep{}, fmt.Errorf("step %s has no code: %w", stepID, apperrors.ErrNotFound)
+	}
+	step.Proof = nil
+	return s.saveStep(ctx, step)
+}
+
+func (s *Service) CodeSheet(ctx context.Context, mjID, dungeonID string) ([]models.CodeSheetEntry, error) {
+	if _, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorEditor); err != nil {
+		return nil, err
+	}
+	steps, err := s.repo.ListStepsByDungeon(ctx, dungeonID)
+	if err != nil {
+		return nil, fmt.Errorf("list steps: %w", err)
+	}
+	out := make([]models.CodeSheetEntry, 0, len(steps))
+	for _, st := range steps {
+		if _, needCode := st.Proofs(); !needCode || st.Proof.Secret == "" {
+			continue
+		}
+		entry := models.CodeSheetEntry{
+			StepID:  st.ID,
+			Order:   st.Order,
+			Name:    st.Name,
+			Require: st.Proof.Require,
+			Mode:    st.Proof.Mode,
+			Version: st.Proof.Version,
+		}
+		switch st.Proof.Mode {
+		case models.CodeModeTOTP:
+			entry.KeyURI = otp.KeyURI(codeIssuer, st.Name, st.Proof.Secret, time.Duration(st.Proof.PeriodSeconds)*time.Second)
+		default:
+			code, err := otp.Static(st.Proof.Secret)
+			if err != nil {
+				return nil, fmt.Errorf("derive code for step %s: %w", st.ID, err)
+			}
+			entry.Code = code
+			entry.Payload = "dungeons://steps/" + url.PathEscape(st.ID) + "?code=" + code
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+func (s *Service) editableStep(ctx context.Context, mjID, dungeonID, stepID string) (models.BossStep, error) {
+	if _, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorEditor); err != nil {
+		return models.BossStep{}, err
+	}
+	step, err := s.repo.GetStep(ctx, dungeonID, stepID)
+	if err != nil {
+		return models.BossStep{}, fmt.Errorf("get step: %w", err)
+	}
+	return step, nil
+}
+
+func (s *Service) saveStep(ctx context.Context, step models.BossStep) (models.BossStep, error) {
+	step.UpdatedAt = s.now()
+	updated, err := s.repo.UpdateStep(ctx, step)
+	if err != nil {
+		return models.BossStep{}, fmt.Errorf("update step: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *Service) newProofSecret(policy *models.ProofPolicy) error {
+	secret, err := otp.NewSecret()
+	if err != nil {
+		return fmt.Errorf("generate step secret: %w", err)
+	}
+	now := s.now()
+	policy.Secret = secret
+	policy.Version++
+	policy.RotatedAt = &now
+	return nil
+}
@@ -0,0 +1,59 @@
+package dungeon
+
+import (
+	"context"
+	"dungeons/app/geo"
+	"dungeons/app/models"
+	"fmt"
+	"math"
+)
+
+type RouteOptions struct {
+	MetersPerMinute float64
+	// MaxMeters is the route length above which MJs get a warning.
+	MaxMeters float64
+}
+
+func (s *Service) PlanRoute(steps []models.BossStep) models.RoutePlan {
+	points := make([]geo.Point, 0, len(steps))
+	natural := make([]int, 0, len(steps))
+	for i, st := range steps {
+		points = append(points, geo.Point{Lat: st.Location.Lat, Lon: st.Location.Lon})
+		natural = append(natural, i)
+	}
+	plan := models.RoutePlan{
+		Legs:           make([]models.RouteLeg, 0, max(len(steps)-1, 0)),
+		SuggestedOrder: make([]string, 0, len(steps)),
+	}
+	for i := 1; i < len(steps); i++ {
+		meters := geo.PathMeters(points, []int{i - 1, i})
+		plan.Legs = append(plan.Legs, models.RouteLeg{FromStepID: steps[i-1].ID, ToStepID: steps[i].ID, Meters: meters})
+	}
+	plan.TotalMeters = geo.PathMeters(points, natural)
+	if s.route.MetersPerMinute > 0 {
+		plan.EstimatedMinutes = int(math.Ceil(plan.TotalMeters / s.route.MetersPerMinute))
+	}
+	suggested := geo.PlanRoute(points)
+	for _, i := range suggested {
+		plan.SuggestedOrder = append(plan.SuggestedOrder, steps[i].ID)
+	}
+	plan.SuggestedMeters = geo.PathMeters(points, suggested)
+	return plan
+}
+
+// DungeonRoute plans a dungeon in any status so MJs can check it while
+// editing. Only this view carries warnings; players get the bare plan.
+func (s *Service) DungeonRoute(ctx context.Context, mjID, dungeonID string) (models.RoutePlan, error) {
+	if _, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorViewer); err != nil {
+		return models.RoutePlan{}, err
+	}
+	steps, err := s.repo.ListStepsByDungeon(ctx, dungeonID)
+	if err != nil {
+		return models.RoutePlan{}, fmt.Errorf("list steps: %w", err)
+	}
+	plan := s.PlanRoute(steps)
+	if s.route.MaxMeters > 0 && plan.TotalMeters > s.route.MaxMeters {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("route is %.1f km, above the %.1f km guideline", plan.TotalMeters/1000, s.route.MaxMeters/1000))
+	}
+	return plan, nil
+}
@@ -0,0 +1,145 @@
+package dungeon
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/models"
+	"fmt"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"golang.org/x/text/language"
+)
+
+const scheduleBatch = 100
+
+func (s *Service) ScheduleDungeon(ctx context.Context, mjID, dungeonID string, req models.ScheduleDungeonRequest) (models.Dungeon, error) {
+	d, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorEditor)
+	if err != nil {
+		return models.Dungeon{}, err
+	}
+	if d.Status == models.DungeonStatusArchived {
+		return models.Dungeon{}, fmt.Errorf("cannot schedule archived dungeon: %w", apperrors.ErrValidation)
+	}
+	now := s.now()
+	if req.PublishAt != nil {
+		if d.Status != models.DungeonStatusDraft {
+			return models.Dungeon{}, fmt.Errorf("dungeon is already published: %w", apperrors.ErrValidation)
+		}
+		if !req.PublishAt.After(now) {
+			return models.Dungeon{}, fmt.Errorf("publishAt must be in the future: %w", apperrors.ErrValidation)
+		}
+		// Fail at scheduling time rather than silently at the start of the event.
+		steps, err := s.repo.ListStepsByDungeon(ctx, dungeonID)
+		if err != nil {
+			return models.Dungeon{}, fmt.Errorf("list steps: %w", err)
+		}
+		if err := checkPublishable(steps); err != nil {
+			return models.Dungeon{}, err
+		}
+	}
+	if req.UnpublishAt != nil {
+		if !req.UnpublishAt.After(now) {
+			return models.Dungeon{}, fmt.Errorf("unpublishAt must be in the future: %w", apperrors.ErrValidation)
+		}
+		if req.PublishAt != nil && !req.PublishAt.Before(*req.UnpublishAt) {
+			return models.Dungeon{}, fmt.Errorf("publishAt must be before unpublishAt: %w", apperrors.ErrValidation)
+		}
+	}
+
+	d.PublishAt = utcPtr(req.PublishAt)
+	d.UnpublishAt = utcPtr(req.UnpublishAt)
+	d.ScheduleError = ""
+	d.UpdatedAt = now
+	updated, err := s.repo.UpdateDungeon(ctx, d)
+	if err != nil {
+		return models.Dungeon{}, fmt.Errorf("schedule dungeon: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *Service) ListUpcoming(ctx context.Context, params models.QueryParams, prefs []language.Tag) ([]models.UpcomingDungeon, error) {
+	now := s.now()
+	list, err := s.repo.ListByScheduleField(ctx, bson.M{
+		"status":    models.DungeonStatusDraft,
+		"publishAt": bson.M{"$gt": now},
+	}, "publishAt", params)
+	if err != nil {
+		return nil, fmt.Errorf("list upcoming dungeons: %w", err)
+	}
+	out := make([]models.UpcomingDungeon, 0, len(list))
+	for _, d := range list {
+		localized, _ := localize(d, nil, prefs)
+		out = append(out, models.UpcomingDungeon{
+			Dungeon:         localized,
+			StartsInSeconds: int64(math.Ceil(d.PublishAt.Sub(now).Seconds())),
+		})
+	}
+	return out, nil
+}
+
+// RunSchedule publishes drafts whose publishAt has passed and archives
+// published dungeons whose unpublishAt has passed. A draft that fails the
+// publish checks keeps its draft status and the reason in scheduleError.
+func (s *Service) RunSchedule(ctx context.Context) (published, archived int, err error) {
+	now := s.now()
+	batch := models.QueryParams{Page: 1, Limit: scheduleBatch}
+
+	due, err := s.repo.ListByScheduleField(ctx, bson.M{
+		"status":    models.DungeonStatusDraft,
+		"publishAt": bson.M{"$lte": now},
+		"$or": bson.A{
+			bson.M{"unpublishAt": bson.M{"$exists": false}},
+			bson.M{"unpublishAt": bson.M{"$gt": now}},
+		},
+	}, "publishAt", batch)
+	if err != nil {
+		return 0, 0, fmt.Errorf("list dungeons due for publish: %w", err)
+	}
+	for _, d := range due {
+		steps, err := s.repo.ListStepsByDungeon(ctx, d.ID)
+		if err != nil {
+			return published, archived, fmt.Errorf("list steps: %w", err)
+		}
+		if err := checkPublishable(steps); err != nil {
+			if err := s.repo.FailSchedule(ctx, d.ID, err.Error(), now); err != nil {
+				return published, archived, err
+			}
+			continue
+		}
+		ok, err := s.repo.TransitionStatus(ctx, d.ID, models.DungeonStatusDraft, models.DungeonStatusPublished, bson.M{"stats": computeStats(steps)}, now)
+		if err != nil {
+			return published, archived, err
+		}
+		if ok {
+			published++
+		}
+	}
+
+	expired, err := s.repo.ListByScheduleField(ctx, bson.M{
+		"status":      models.DungeonStatusPublished,
+		"unpublishAt": bson.M{"$lte": now},
+	}, "unpublishAt", batch)
+	if err != nil {
+		return published, archived, fmt.Errorf("list dungeons due for archive: %w", err)
+	}
+	for _, d := range expired {
+		ok, err := s.repo.TransitionStatus(ctx, d.ID, models.DungeonStatusPublished, models.DungeonStatusArchived, nil, now)
+		if err != nil {
+			return published, archived, err
+		}
+		if ok {
+			archived++
+		}
+	}
+	return published, archived, nil
+}
+
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
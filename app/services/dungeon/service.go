@@ -4,37 +4,68 @@ import (
 	"context"
 	apperrors "dungeons/app/errors"
 	"dungeons/app/functions"
+	"dungeons/app/i18n"
 	"dungeons/app/models"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"golang.org/x/text/language"
 )
 
 type Repository interface {
 	EnsureIndexes(ctx context.Context) error
 	CreateDungeon(ctx context.Context, d models.Dungeon) error
 	UpdateDungeon(ctx context.Context, d models.Dungeon) (models.Dungeon, error)
+	SetCover(ctx context.Context, id string, cover *models.Image, updatedAt time.Time) (models.Dungeon, error)
 	GetDungeonByID(ctx context.Context, id string) (models.Dungeon, error)
 	ListDungeonsByFilter(ctx context.Context, filter bson.M, params models.QueryParams) ([]models.Dungeon, error)
+	ListByScheduleField(ctx context.Context, filter bson.M, field string, params models.QueryParams) ([]models.Dungeon, error)
+	TransitionStatus(ctx context.Context, id string, from, to models.DungeonStatus, set bson.M, updatedAt time.Time) (bool, error)
+	FailSchedule(ctx context.Context, id, reason string, updatedAt time.Time) error
+	SearchDungeons(ctx context.Context, filter bson.M, query models.DungeonSearchQuery, params models.QueryParams) (models.DungeonSearchResult, error)
+	UpdateDungeonStats(ctx context.Context, id string, stats models.DungeonStats) error
 	CreateStep(ctx context.Context, step models.BossStep) error
+	CreateSteps(ctx context.Context, steps []models.BossStep) error
 	UpdateStep(ctx context.Context, step models.BossStep) (models.BossStep, error)
+	SetStepArt(ctx context.Context, dungeonID, stepID string, art *models.Image, updatedAt time.Time) (models.BossStep, error)
 	GetStep(ctx context.Context, dungeonID, stepID string) (models.BossStep, error)
 	ListStepsByDungeon(ctx context.Context, dungeonID string) ([]models.BossStep, error)
 	ReorderSteps(ctx context.Context, dungeonID string, orderByStepID map[string]int, updatedAt time.Time) error
+	UnsetTranslation(ctx context.Context, dungeonID, lang string, updatedAt time.Time) error
+}
+
+type PlayerRepository interface {
+	GetByID(ctx context.Context, id string) (models.Player, error)
+}
+
+// ImageStore turns an upload into stored, metadata-free images.
+type ImageStore interface {
+	Store(ctx context.Context, r io.Reader) (models.Image, error)
 }
 
 type Service struct {
 	repo     Repository
+	players  PlayerRepository
+	images   ImageStore
+	route    RouteOptions
 	validate *validator.Validate
+	client   *mongo.Client
 	now      func() time.Time
 }
 
-func New(repo Repository, validate *validator.Validate) *Service {
+func New(repo Repository, players PlayerRepository, images ImageStore, route RouteOptions, validate *validator.Validate, client *mongo.Client) *Service {
 	return &Service{
 		repo:     repo,
+		players:  players,
+		images:   images,
+		route:    route,
 		validate: validate,
+		client:   client,
 		now:      func() time.Time { return time.Now().UTC() },
 	}
 }
@@ -50,11 +81,16 @@ func (s *Service) CreateDungeon(ctx context.Context, mjID string, req models.Cre
 	if err := s.validate.Struct(req); err != nil {
 		return models.Dungeon{}, fmt.Errorf("validate create dungeon: %w", apperrors.ErrValidation)
 	}
+	lang := i18n.DefaultLang
+	if req.DefaultLang != "" {
+		lang, _ = i18n.Normalize(req.DefaultLang)
+	}
 	now := s.now()
 	d := models.Dungeon{
 		ID:          functions.NewUUID(),
 		Title:       req.Title,
 		Description: req.Description,
+		DefaultLang: lang,
 		CreatedBy:   mjID,
 		AreaName:    req.AreaName,
 		Status:      models.DungeonStatusDraft,
@@ -71,16 +107,20 @@ func (s *Service) UpdateDungeon(ctx context.Context, mjID, dungeonID string, req
 	if err := s.validate.Struct(req); err != nil {
 		return models.Dungeon{}, fmt.Errorf("validate update dungeon: %w", apperrors.ErrValidation)
 	}
-	d, err := s.repo.GetDungeonByID(ctx, dungeonID)
+	d, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorEditor)
 	if err != nil {
-		return models.Dungeon{}, fmt.Errorf("get dungeon: %w", err)
-	}
-	if d.CreatedBy != mjID {
-		return models.Dungeon{}, fmt.Errorf("cannot update foreign dungeon: %w", apperrors.ErrForbidden)
+		return models.Dungeon{}, err
 	}
 	d.Title = req.Title
 	d.Description = req.Description
 	d.AreaName = req.AreaName
+	if req.DefaultLang != "" {
+		lang, _ := i18n.Normalize(req.DefaultLang)
+		if _, ok := d.Translations[lang]; ok {
+			return models.Dungeon{}, fmt.Errorf("remove the %s translation before making it the default: %w", lang, apperrors.ErrValidation)
+		}
+		d.DefaultLang = lang
+	}
 	if req.Status != "" {
 		d.Status = models.DungeonStatus(req.Status)
 	}
@@ -93,26 +133,20 @@ func (s *Service) UpdateDungeon(ctx context.Context, mjID, dungeonID string, req
 }
 
 func (s *Service) PublishDungeon(ctx context.Context, mjID, dungeonID string) (models.Dungeon, error) {
-	d, err := s.repo.GetDungeonByID(ctx, dungeonID)
+	d, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorEditor)
 	if err != nil {
-		return models.Dungeon{}, fmt.Errorf("get dungeon: %w", err)
-	}
-	if d.CreatedBy != mjID {
-		return models.Dungeon{}, fmt.Errorf("cannot publish foreign dungeon: %w", apperrors.ErrForbidden)
+		return models.Dungeon{}, err
 	}
 	steps, err := s.repo.ListStepsByDungeon(ctx, dungeonID)
 	if err != nil {
 		return models.Dungeon{}, fmt.Errorf("list steps: %w", err)
 	}
-	if len(steps) == 0 {
-		return models.Dungeon{}, fmt.Errorf("cannot publish empty dungeon: %w", apperrors.ErrValidation)
-	}
-	for _, st := range steps {
-		if st.Location.RadiusMeters <= 0 {
-			return models.Dungeon{}, fmt.Errorf("step %s has invalid radius: %w", st.ID, apperrors.ErrValidation)
-		}
+	if err := checkPublishable(steps); err != nil {
+		return models.Dungeon{}, err
 	}
 	d.Status = models.DungeonStatusPublished
+	d.Stats = computeStats(steps)
+	d.ScheduleError = ""
 	d.UpdatedAt = s.now()
 	updated, err := s.repo.UpdateDungeon(ctx, d)
 	if err != nil {
@@ -121,26 +155,56 @@ func (s *Service) PublishDungeon(ctx context.Context, mjID, dungeonID string) (m
 	return updated, nil
 }
 
-func (s *Service) ListPublished(ctx context.Context, params models.QueryParams) ([]models.Dungeon, error) {
-	list, err := s.repo.ListDungeonsByFilter(ctx, bson.M{"status": models.DungeonStatusPublished}, params)
+func checkPublishable(steps []models.BossStep) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("cannot publish empty dungeon: %w", apperrors.ErrValidation)
+	}
+	for _, st := range steps {
+		if st.Location.RadiusMeters <= 0 {
+			return fmt.Errorf("step %s has invalid radius: %w", st.ID, apperrors.ErrValidation)
+		}
+		if st.Kind() == models.StepTypeRiddle && (st.Riddle == nil || len(st.Riddle.AnswerHashes) == 0) {
+			return fmt.Errorf("riddle step %s has no answer: %w", st.ID, apperrors.ErrValidation)
+		}
+	}
+	return nil
+}
+
+func (s *Service) SearchPublished(ctx context.Context, query models.DungeonSearchQuery, params models.QueryParams, prefs []language.Tag) (models.DungeonSearchResult, error) {
+	if err := s.validate.Struct(query); err != nil {
+		return models.DungeonSearchResult{}, fmt.Errorf("validate dungeon search: %w", apperrors.ErrValidation)
+	}
+	if query.MinDifficulty != nil && query.MaxDifficulty != nil && *query.MinDifficulty > *query.MaxDifficulty {
+		return models.DungeonSearchResult{}, fmt.Errorf("minDifficulty exceeds maxDifficulty: %w", apperrors.ErrValidation)
+	}
+	query.Q = strings.TrimSpace(query.Q)
+	query.Area = strings.TrimSpace(query.Area)
+	res, err := s.repo.SearchDungeons(ctx, bson.M{"status": models.DungeonStatusPublished}, query, params)
 	if err != nil {
-		return nil, fmt.Errorf("list published dungeons: %w", err)
+		return models.DungeonSearchResult{}, fmt.Errorf("search published dungeons: %w", err)
+	}
+	for i, d := range res.Dungeons {
+		res.Dungeons[i], _ = localize(d, nil, prefs)
 	}
-	return list, nil
+	return res, nil
 }
 
-func (s *Service) GetPublishedByID(ctx context.Context, id string) (models.Dungeon, []models.BossStep, error) {
+func (s *Service) GetPublishedByID(ctx context.Context, id string, prefs []language.Tag) (models.Dungeon, []models.BossStep, error) {
 	d, err := s.repo.GetDungeonByID(ctx, id)
 	if err != nil {
 		return models.Dungeon{}, nil, fmt.Errorf("get dungeon: %w", err)
 	}
-	if d.Status != models.DungeonStatusPublished {
+	if !d.IsLive(s.now()) {
 		return models.Dungeon{}, nil, fmt.Errorf("dungeon is not published: %w", apperrors.ErrNotFound)
 	}
 	steps, err := s.repo.ListStepsByDungeon(ctx, id)
 	if err != nil {
 		return models.Dungeon{}, nil, fmt.Errorf("list steps: %w", err)
 	}
+	d, steps = localize(d, steps, prefs)
+	for i := range steps {
+		steps[i] = steps[i].PlayerView()
+	}
 	return d, steps, nil
 }
 
@@ -151,18 +215,20 @@ func (s *Service) CreateStep(ctx context.Context, mjID, dungeonID string, req mo
 	if req.Location.RadiusMeters <= 0 {
 		return models.BossStep{}, fmt.Errorf("radiusMeters must be positive: %w", apperrors.ErrValidation)
 	}
-	d, err := s.repo.GetDungeonByID(ctx, dungeonID)
+	stepType, riddle, err := buildStepType(req.Type, req.Riddle)
 	if err != nil {
-		return models.BossStep{}, fmt.Errorf("get dungeon: %w", err)
+		return models.BossStep{}, err
 	}
-	if d.CreatedBy != mjID {
-		return models.BossStep{}, fmt.Errorf("cannot modify foreign dungeon: %w", apperrors.ErrForbidden)
+	if _, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorEditor); err != nil {
+		return models.BossStep{}, err
 	}
 	now := s.now()
 	step := models.BossStep{
 		ID:              functions.NewUUID(),
 		DungeonID:       dungeonID,
 		Order:           req.Order,
+		Type:            stepType,
+		Riddle:          riddle,
 		Name:            req.Name,
 		Location:        req.Location,
 		ZoneDescription: req.ZoneDescription,
@@ -174,6 +240,9 @@ func (s *Service) CreateStep(ctx context.Context, mjID, dungeonID string, req mo
 	if err := s.repo.CreateStep(ctx, step); err != nil {
 		return models.BossStep{}, fmt.Errorf("create step: %w", err)
 	}
+	if err := s.refreshStats(ctx, dungeonID); err != nil {
+		return models.BossStep{}, err
+	}
 	return step, nil
 }
 
@@ -184,17 +253,25 @@ func (s *Service) UpdateStep(ctx context.Context, mjID, dungeonID, stepID string
 	if req.Location.RadiusMeters <= 0 {
 		return models.BossStep{}, fmt.Errorf("radiusMeters must be positive: %w", apperrors.ErrValidation)
 	}
-	d, err := s.repo.GetDungeonByID(ctx, dungeonID)
-	if err != nil {
-		return models.BossStep{}, fmt.Errorf("get dungeon: %w", err)
-	}
-	if d.CreatedBy != mjID {
-		return models.BossStep{}, fmt.Errorf("cannot modify foreign dungeon: %w", apperrors.ErrForbidden)
+	if _, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorEditor); err != nil {
+		return models.BossStep{}, err
 	}
 	step, err := s.repo.GetStep(ctx, dungeonID, stepID)
 	if err != nil {
 		return models.BossStep{}, fmt.Errorf("get step: %w", err)
 	}
+	// An empty type keeps the step's type, and its riddle unless a new one
+	// is sent.
+	stepType := req.Type
+	if stepType == "" {
+		stepType = step.Kind()
+	}
+	if stepType != step.Kind() || req.Riddle != nil {
+		step.Type, step.Riddle, err = buildStepType(stepType, req.Riddle)
+		if err != nil {
+			return models.BossStep{}, err
+		}
+	}
 	step.Name = req.Name
 	step.Location = req.Location
 	step.ZoneDescription = req.ZoneDescription
@@ -205,6 +282,9 @@ func (s *Service) UpdateStep(ctx context.Context, mjID, dungeonID, stepID string
 	if err != nil {
 		return models.BossStep{}, fmt.Errorf("update step: %w", err)
 	}
+	if err := s.refreshStats(ctx, dungeonID); err != nil {
+		return models.BossStep{}, err
+	}
 	return updated, nil
 }
 
@@ -212,12 +292,8 @@ func (s *Service) ReorderSteps(ctx context.Context, mjID, dungeonID string, req
 	if err := s.validate.Struct(req); err != nil {
 		return nil, fmt.Errorf("validate reorder steps: %w", apperrors.ErrValidation)
 	}
-	d, err := s.repo.GetDungeonByID(ctx, dungeonID)
-	if err != nil {
-		return nil, fmt.Errorf("get dungeon: %w", err)
-	}
-	if d.CreatedBy != mjID {
-		return nil, fmt.Errorf("cannot reorder foreign dungeon: %w", apperrors.ErrForbidden)
+	if _, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorEditor); err != nil {
+		return nil, err
 	}
 	steps, err := s.repo.ListStepsByDungeon(ctx, dungeonID)
 	if err != nil {
@@ -254,3 +330,28 @@ func (s *Service) GetStepByID(ctx context.Context, dungeonID, stepID string) (mo
 	}
 	return step, nil
 }
+
+func (s *Service) refreshStats(ctx context.Context, dungeonID string) error {
+	steps, err := s.repo.ListStepsByDungeon(ctx, dungeonID)
+	if err != nil {
+		return fmt.Errorf("list steps for stats: %w", err)
+	}
+	if err := s.repo.UpdateDungeonStats(ctx, dungeonID, computeStats(steps)); err != nil {
+		return fmt.Errorf("refresh dungeon stats: %w", err)
+	}
+	return nil
+}
+
+func computeStats(steps []models.BossStep) models.DungeonStats {
+	stats := models.DungeonStats{StepCount: len(steps)}
+	if len(steps) == 0 {
+		return stats
+	}
+	difficulty := 0
+	for _, st := range steps {
+		difficulty += st.Difficulty
+		stats.TotalRewardGold += st.Rewards.Gold
+	}
+	stats.AvgDifficulty = float64(difficulty) / float64(len(steps))
+	return stats
+}
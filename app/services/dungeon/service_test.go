@@ -0,0 +1,171 @@
+package dungeon
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/functions"
+	"dungeons/app/models"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type stepRepoStub struct {
+	Repository
+	dungeon models.Dungeon
+	step    models.BossStep
+}
+
+func (r *stepRepoStub) GetDungeonByID(context.Context, string) (models.Dungeon, error) {
+	return r.dungeon, nil
+}
+
+func (r *stepRepoStub) UpdateDungeon(_ context.Context, d models.Dungeon) (models.Dungeon, error) {
+	r.dungeon = d
+	return d, nil
+}
+
+func (r *stepRepoStub) GetStep(context.Context, string, string) (models.BossStep, error) {
+	return r.step, nil
+}
+
+func (r *stepRepoStub) UpdateStep(_ context.Context, step models.BossStep) (models.BossStep, error) {
+	r.step = step
+	return step, nil
+}
+
+func (r *stepRepoStub) ListStepsByDungeon(context.Context, string) ([]models.BossStep, error) {
+	return []models.BossStep{r.step}, nil
+}
+
+func (r *stepRepoStub) UpdateDungeonStats(context.Context, string, models.DungeonStats) error {
+	return nil
+}
+
+func TestUpdateStepWithoutTypeKeepsRiddle(t *testing.T) {
+	riddle, err := buildRiddle(&models.RiddleRequest{Question: "Who guards the gate?", Answers: []string{"Le Gardien"}, MaxTries: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &stepRepoStub{
+		dungeon: models.Dungeon{ID: "d1", CreatedBy: "mj"},
+		step:    models.BossStep{ID: "s1", DungeonID: "d1", Type: models.StepTypeRiddle, Riddle: riddle, Name: "Gate"},
+	}
+	svc := &Service{repo: repo, validate: validator.New(), now: time.Now}
+
+	req := models.UpdateBossStepRequest{
+		Name:            "Old gate",
+		Location:        models.BossLocation{Lat: 48.85, Lon: 2.35, RadiusMeters: 30},
+		ZoneDescription: "Behind the church",
+		Difficulty:      2,
+		Rewards:         models.Rewards{Gold: 10},
+	}
+	updated, err := svc.UpdateStep(context.Background(), "mj", "d1", "s1", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != "Old gate" || updated.Type != models.StepTypeRiddle || updated.Riddle == nil {
+		t.Fatalf("updated step = %+v", updated)
+	}
+	if updated.Riddle.Question != riddle.Question || len(updated.Riddle.AnswerHashes) != 1 {
+		t.Fatalf("riddle = %+v, want %+v", updated.Riddle, riddle)
+	}
+	if err := functions.CheckPassword("le gardien", updated.Riddle.AnswerHashes[0]); err != nil {
+		t.Fatalf("answer no longer matches: %v", err)
+	}
+}
+
+type mjStub struct {
+	PlayerRepository
+}
+
+func (mjStub) GetByID(_ context.Context, id string) (models.Player, error) {
+	return models.Player{ID: id, Role: models.RoleMJ}, nil
+}
+
+func TestCollaboratorAccess(t *testing.T) {
+	ctx := context.Background()
+	edit := models.UpdateDungeonRequest{Title: "Catacombs", Description: "Under the city", AreaName: "Paris"}
+	update := func(s *Service, mjID string) error {
+		_, err := s.UpdateDungeon(ctx, mjID, "d1", edit)
+		return err
+	}
+	list := func(s *Service, mjID string) error {
+		_, err := s.ListCollaborators(ctx, mjID, "d1")
+		return err
+	}
+
+	tests := []struct {
+		name  string
+		run   func(s *Service) error
+		want  error
+		check func(t *testing.T, d models.Dungeon)
+	}{
+		{name: "viewer cannot edit", run: func(s *Service) error { return update(s, "viewer") }, want: apperrors.ErrForbidden},
+		{name: "editor can edit", run: func(s *Service) error { return update(s, "editor") }},
+		{name: "editor cannot add collaborators", want: apperrors.ErrForbidden, run: func(s *Service) error {
+			_, err := s.AddCollaborator(ctx, "editor", "d1", models.AddCollaboratorRequest{PlayerID: "friend", Role: models.CollaboratorViewer})
+			return err
+		}},
+		{name: "editor cannot transfer ownership", want: apperrors.ErrForbidden, run: func(s *Service) error {
+			_, err := s.TransferOwnership(ctx, "editor", "d1", models.TransferOwnershipRequest{PlayerID: "editor"})
+			return err
+		}},
+		{name: "removed collaborator loses access", want: apperrors.ErrForbidden, run: func(s *Service) error {
+			if _, err := s.RemoveCollaborator(ctx, "owner", "d1", "editor"); err != nil {
+				return err
+			}
+			return list(s, "editor")
+		}},
+		{name: "collaborator can leave", run: func(s *Service) error {
+			_, err := s.RemoveCollaborator(ctx, "viewer", "d1", "viewer")
+			return err
+		}, check: func(t *testing.T, d models.Dungeon) {
+			if d.RoleOf("viewer") != "" {
+				t.Fatalf("viewer still has role %q", d.RoleOf("viewer"))
+			}
+		}},
+		{name: "previous owner becomes editor", run: func(s *Service) error {
+			if _, err := s.TransferOwnership(ctx, "owner", "d1", models.TransferOwnershipRequest{PlayerID: "editor"}); err != nil {
+				return err
+			}
+			if err := update(s, "owner"); err != nil {
+				return err
+			}
+			_, err := s.AddCollaborator(ctx, "owner", "d1", models.AddCollaboratorRequest{PlayerID: "friend", Role: models.CollaboratorViewer})
+			return err
+		}, want: apperrors.ErrForbidden, check: func(t *testing.T, d models.Dungeon) {
+			if d.CreatedBy != "editor" || d.RoleOf("owner") != models.CollaboratorEditor {
+				t.Fatalf("owner %s, previous owner role %q", d.CreatedBy, d.RoleOf("owner"))
+			}
+			if slices.ContainsFunc(d.Collaborators, func(c models.Collaborator) bool { return c.PlayerID == "editor" }) {
+				t.Fatal("new owner still listed as a collaborator")
+			}
+		}},
+		{name: "empty role refused", run: func(s *Service) error { return list(s, "blank") }, want: apperrors.ErrForbidden},
+		{name: "unknown role refused", run: func(s *Service) error { return list(s, "admin") }, want: apperrors.ErrForbidden},
+		{name: "stranger refused", run: func(s *Service) error { return list(s, "stranger") }, want: apperrors.ErrForbidden},
+		{name: "anonymous refused", run: func(s *Service) error { return list(s, "") }, want: apperrors.ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &stepRepoStub{dungeon: models.Dungeon{ID: "d1", CreatedBy: "owner", Collaborators: []models.Collaborator{
+				{PlayerID: "editor", Role: models.CollaboratorEditor},
+				{PlayerID: "viewer", Role: models.CollaboratorViewer},
+				{PlayerID: "blank"},
+				{PlayerID: "admin", Role: "admin"},
+			}}}
+			svc := &Service{repo: repo, players: mjStub{}, validate: validator.New(), now: time.Now}
+
+			if err := tt.run(svc); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			if tt.check != nil {
+				tt.check(t, repo.dungeon)
+			}
+		})
+	}
+}
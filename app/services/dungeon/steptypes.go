@@ -0,0 +1,60 @@
+package dungeon
+
+import (
+	apperrors "dungeons/app/errors"
+	"dungeons/app/functions"
+	"dungeons/app/models"
+	"fmt"
+)
+
+// maxAnswerBytes is the most bcrypt will hash; it applies to the normalised
+// answer, which can be longer in bytes than in characters.
+const maxAnswerBytes = 72
+
+func buildStepType(stepType models.StepType, req *models.RiddleRequest) (models.StepType, *models.Riddle, error) {
+	switch stepType {
+	case "", models.StepTypeLocation, models.StepTypePhoto:
+		if req != nil {
+			return "", nil, fmt.Errorf("riddle config on a %s step: %w", stepType, apperrors.ErrValidation)
+		}
+		if stepType == models.StepTypePhoto {
+			return models.StepTypePhoto, nil, nil
+		}
+		return models.StepTypeLocation, nil, nil
+	case models.StepTypeRiddle:
+		riddle, err := buildRiddle(req)
+		if err != nil {
+			return "", nil, err
+		}
+		return models.StepTypeRiddle, riddle, nil
+	default:
+		return "", nil, fmt.Errorf("unknown step type %q: %w", stepType, apperrors.ErrValidation)
+	}
+}
+
+func buildRiddle(req *models.RiddleRequest) (*models.Riddle, error) {
+	if req == nil {
+		return nil, fmt.Errorf("riddle config required: %w", apperrors.ErrValidation)
+	}
+	riddle := &models.Riddle{
+		Question:     req.Question,
+		AnswerHashes: make([]string, 0, len(req.Answers)),
+		MaxTries:     req.MaxTries,
+		Hints:        append([]models.RiddleHint{}, req.Hints...),
+	}
+	for _, answer := range req.Answers {
+		normalized := functions.NormalizeAnswer(answer)
+		if normalized == "" {
+			return nil, fmt.Errorf("blank riddle answer: %w", apperrors.ErrValidation)
+		}
+		if len(normalized) > maxAnswerBytes {
+			return nil, fmt.Errorf("riddle answer longer than %d bytes: %w", maxAnswerBytes, apperrors.ErrValidation)
+		}
+		hash, err := functions.HashAndSalt(normalized)
+		if err != nil {
+			return nil, fmt.Errorf("hash riddle answer: %w", err)
+		}
+		riddle.AnswerHashes = append(riddle.AnswerHashes, string(hash))
+	}
+	return riddle, nil
+}
@@ -0,0 +1,22 @@
+package dungeon
+
+import (
+	apperrors "dungeons/app/errors"
+	"dungeons/app/models"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBuildRiddleBoundsAnswerBytes(t *testing.T) {
+	// 70 accented letters lose their accents and fit.
+	fits := strings.Repeat("é", 70)
+	if _, err := buildRiddle(&models.RiddleRequest{Question: "Long?", Answers: []string{fits}}); err != nil {
+		t.Fatalf("70 accented letters: %v", err)
+	}
+	// 40 Cyrillic letters pass the 200 character limit but are 80 bytes.
+	long := strings.Repeat("Ж", 40)
+	if _, err := buildRiddle(&models.RiddleRequest{Question: "Long?", Answers: []string{long}}); !errors.Is(err, apperrors.ErrValidation) {
+		t.Fatalf("80 byte answer: %v, want validation error", err)
+	}
+}
@@ -0,0 +1,162 @@
+package dungeon
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/i18n"
+	"dungeons/app/models"
+	"dungeons/app/mongodb"
+	"fmt"
+	"sort"
+
+	"golang.org/x/text/language"
+)
+
+func localize(d models.Dungeon, steps []models.BossStep, prefs []language.Tag) (models.Dungeon, []models.BossStep) {
+	if d.DefaultLang == "" {
+		d.DefaultLang = i18n.DefaultLang
+	}
+	lang := i18n.Pick(prefs, d.Languages())
+	out := make([]models.BossStep, 0, len(steps))
+	for _, st := range steps {
+		out = append(out, st.Localized(lang, d.DefaultLang))
+	}
+	return d.Localized(lang), out
+}
+
+// translationLang normalizes lang and rejects the dungeon's own default
+// language, whose text lives on the base fields.
+func translationLang(d models.Dungeon, lang string) (string, error) {
+	tag, ok := i18n.Normalize(lang)
+	if !ok {
+		return "", fmt.Errorf("invalid language tag %q: %w", lang, apperrors.ErrValidation)
+	}
+	def := d.DefaultLang
+	if def == "" {
+		def = i18n.DefaultLang
+	}
+	if tag == def {
+		return "", fmt.Errorf("%s is the default language, edit the dungeon instead: %w", tag, apperrors.ErrValidation)
+	}
+	return tag, nil
+}
+
+func (s *Service) SetDungeonTranslation(ctx context.Context, mjID, dungeonID, lang string, req models.DungeonTranslation) (models.Dungeon, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return models.Dungeon{}, fmt.Errorf("validate dungeon translation: %w", apperrors.ErrValidation)
+	}
+	d, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorEditor)
+	if err != nil {
+		return models.Dungeon{}, err
+	}
+	tag, err := translationLang(d, lang)
+	if err != nil {
+		return models.Dungeon{}, err
+	}
+	if d.Translations == nil {
+		d.Translations = make(map[string]models.DungeonTranslation)
+	}
+	d.Translations[tag] = req
+	d.UpdatedAt = s.now()
+	updated, err := s.repo.UpdateDungeon(ctx, d)
+	if err != nil {
+		return models.Dungeon{}, fmt.Errorf("update dungeon translation: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *Service) SetStepTranslation(ctx context.Context, mjID, dungeonID, stepID, lang string, req models.StepTranslation) (models.BossStep, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return models.BossStep{}, fmt.Errorf("validate step translation: %w", apperrors.ErrValidation)
+	}
+	d, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorEditor)
+	if err != nil {
+		return models.BossStep{}, err
+	}
+	tag, err := translationLang(d, lang)
+	if err != nil {
+		return models.BossStep{}, err
+	}
+	step, err := s.repo.GetStep(ctx, dungeonID, stepID)
+	if err != nil {
+		return models.BossStep{}, fmt.Errorf("get step: %w", err)
+	}
+	if step.Translations == nil {
+		step.Translations = make(map[string]models.StepTranslation)
+	}
+	step.Translations[tag] = req
+	step.UpdatedAt = s.now()
+	updated, err := s.repo.UpdateStep(ctx, step)
+	if err != nil {
+		return models.BossStep{}, fmt.Errorf("update step translation: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *Service) RemoveTranslation(ctx context.Context, mjID, dungeonID, lang string) (models.Dungeon, error) {
+	d, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorEditor)
+	if err != nil {
+		return models.Dungeon{}, err
+	}
+	tag, err := translationLang(d, lang)
+	if err != nil {
+		return models.Dungeon{}, err
+	}
+	err = mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+		return s.repo.UnsetTranslation(txCtx, dungeonID, tag, s.now())
+	})
+	if err != nil {
+		return models.Dungeon{}, fmt.Errorf("transaction remove translation: %w", err)
+	}
+	updated, err := s.repo.GetDungeonByID(ctx, dungeonID)
+	if err != nil {
+		return models.Dungeon{}, fmt.Errorf("reload dungeon: %w", err)
+	}
+	return updated, nil
+}
+
+// TranslationReport lists, for every language used anywhere in the dungeon,
+// which parts still lack a translation.
+func (s *Service) TranslationReport(ctx context.Context, mjID, dungeonID string) (models.TranslationReport, error) {
+	d, err := s.authorize(ctx, mjID, dungeonID, models.CollaboratorViewer)
+	if err != nil {
+		return models.TranslationReport{}, err
+	}
+	steps, err := s.repo.ListStepsByDungeon(ctx, dungeonID)
+	if err != nil {
+		return models.TranslationReport{}, fmt.Errorf("list steps: %w", err)
+	}
+	if d.DefaultLang == "" {
+		d.DefaultLang = i18n.DefaultLang
+	}
+
+	used := make(map[string]struct{})
+	for lang := range d.Translations {
+		used[lang] = struct{}{}
+	}
+	for _, st := range steps {
+		for lang := range st.Translations {
+			used[lang] = struct{}{}
+		}
+	}
+	delete(used, d.DefaultLang)
+	langs := make([]string, 0, len(used))
+	for lang := range used {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	report := models.TranslationReport{DefaultLang: d.DefaultLang, Languages: make([]models.TranslationStatus, 0, len(langs))}
+	for _, lang := range langs {
+		_, hasDungeon := d.Translations[lang]
+		status := models.TranslationStatus{Lang: lang, Dungeon: hasDungeon, MissingSteps: make([]models.MissingStepTranslation, 0)}
+		for _, st := range steps {
+			if _, ok := st.Translations[lang]; !ok {
+				status.MissingSteps = append(status.MissingSteps, models.MissingStepTranslation{StepID: st.ID, Order: st.Order, Name: st.Name})
+			}
+		}
+		status.Complete = hasDungeon && len(status.MissingSteps) == 0
+		report.Languages = append(report.Languages, status)
+	}
+	return report, nil
+}
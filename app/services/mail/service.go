@@ -0,0 +1,361 @@
+package mail
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/functions"
+	"dungeons/app/models"
+	"dungeons/app/mongodb"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	mailTTL     = 30 * 24 * time.Hour
+	expiryBatch = 100
+	systemBatch = 500
+)
+
+type MailRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	CreateMail(ctx context.Context, mail ...models.Mail) error
+	GetMail(ctx context.Context, id string) (models.Mail, error)
+	MarkRead(ctx context.Context, id string, at time.Time) error
+	CloseMail(ctx context.Context, id string, status models.MailStatus, at time.Time) (models.Mail, error)
+	ListInbox(ctx context.Context, playerID string, now time.Time, params models.QueryParams) ([]models.Mail, error)
+	ListSent(ctx context.Context, playerID string, params models.QueryParams) ([]models.Mail, error)
+	ListDueMail(ctx context.Context, now time.Time, limit int64) ([]models.Mail, error)
+}
+
+type InventoryRepository interface {
+	GetItemDef(ctx context.Context, itemID string) (models.ItemDef, error)
+	AddItem(ctx context.Context, playerID, itemID string, qty int64, updatedAt time.Time) error
+	RemoveItem(ctx context.Context, playerID, itemID string, qty int64, updatedAt time.Time) error
+}
+
+type PlayerRepository interface {
+	GetByID(ctx context.Context, id string) (models.Player, error)
+	List(ctx context.Context, params models.QueryParams) ([]models.Player, error)
+	Transfer(ctx context.Context, t models.GoldTransfer, at time.Time) error
+}
+
+type Service struct {
+	mail      MailRepository
+	inventory InventoryRepository
+	players   PlayerRepository
+	validate  *validator.Validate
+	client    *mongo.Client
+	now       func() time.Time
+}
+
+func New(mail MailRepository, inventory InventoryRepository, players PlayerRepository, validate *validator.Validate, client *mongo.Client) *Service {
+	return &Service{
+		mail:      mail,
+		inventory: inventory,
+		players:   players,
+		validate:  validate,
+		client:    client,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (s *Service) EnsureIndexes(ctx context.Context) error {
+	if err := s.mail.EnsureIndexes(ctx); err != nil {
+		return fmt.Errorf("ensure mail indexes: %w", err)
+	}
+	return nil
+}
+
+// Send escrows the attachments out of the sender's inventory and gold in
+// the same transaction that stores the mail.
+func (s *Service) Send(ctx context.Context, fromID string, req models.SendMailRequest) (models.Mail, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return models.Mail{}, fmt.Errorf("validate send mail: %w", apperrors.ErrValidation)
+	}
+	if req.ToID == fromID {
+		return models.Mail{}, fmt.Errorf("cannot mail yourself: %w", apperrors.ErrValidation)
+	}
+	if _, err := s.players.GetByID(ctx, req.ToID); err != nil {
+		return models.Mail{}, fmt.Errorf("load recipient: %w", err)
+	}
+	if err := s.checkItems(ctx, req.Items, true); err != nil {
+		return models.Mail{}, err
+	}
+
+	now := s.now()
+	mail := newMail(req.ToID, req.Subject, req.Body, req.Items, req.Gold, now, mailTTL)
+	mail.FromID = fromID
+	err := mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+		for _, it := range mail.Items {
+			if err := s.inventory.RemoveItem(txCtx, fromID, it.ItemID, it.Qty, now); err != nil {
+				return fmt.Errorf("escrow attached item %s: %w", it.ItemID, err)
+			}
+		}
+		if mail.Gold > 0 {
+			t := models.GoldTransfer{
+				From:   models.PlayerAccount(fromID),
+				To:     models.AccountEscrow,
+				Amount: mail.Gold,
+				Reason: models.LedgerReasonEscrow,
+				Ref:    mail.ID,
+			}
+			if err := s.players.Transfer(txCtx, t, now); err != nil {
+				return fmt.Errorf("escrow attached gold: %w", err)
+			}
+		}
+		if err := s.mail.CreateMail(txCtx, mail); err != nil {
+			return fmt.Errorf("create mail: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Mail{}, fmt.Errorf("transaction send mail: %w", err)
+	}
+	return mail, nil
+}
+
+// SendSystem mails every recipient a copy, for compensation or prizes.
+// Nothing is escrowed: the gold is minted and the items created when each
+// copy is claimed. It returns the number of copies sent.
+func (s *Service) SendSystem(ctx context.Context, adminID string, req models.SystemMailRequest) (int, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return 0, fmt.Errorf("validate system mail: %w", apperrors.ErrValidation)
+	}
+	if err := s.checkItems(ctx, req.Items, false); err != nil {
+		return 0, err
+	}
+	recipients := req.ToIDs
+	if req.All {
+		ids, err := s.allPlayers(ctx)
+		if err != nil {
+			return 0, err
+		}
+		recipients = ids
+	} else {
+		for _, id := range recipients {
+			if _, err := s.players.GetByID(ctx, id); err != nil {
+				return 0, fmt.Errorf("load recipient: %w", err)
+			}
+		}
+	}
+
+	now := s.now()
+	ttl := mailTTL
+	if req.ExpiresInDays > 0 {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+	sent := 0
+	for start := 0; start < len(recipients); start += systemBatch {
+		batch := make([]models.Mail, 0, systemBatch)
+		for _, id := range recipients[start:min(start+systemBatch, len(recipients))] {
+			mail := newMail(id, req.Subject, req.Body, req.Items, req.Gold, now, ttl)
+			mail.System = true
+			mail.SentBy = adminID
+			batch = append(batch, mail)
+		}
+		if err := s.mail.CreateMail(ctx, batch...); err != nil {
+			return sent, fmt.Errorf("create system mail: %w", err)
+		}
+		sent += len(batch)
+	}
+	return sent, nil
+}
+
+// Get returns a mail to its sender or recipient and marks it read for the
+// recipient.
+func (s *Service) Get(ctx context.Context, playerID, mailID string) (models.Mail, error) {
+	mail, err := s.mail.GetMail(ctx, mailID)
+	if err != nil {
+		return models.Mail{}, fmt.Errorf("load mail: %w", err)
+	}
+	if mail.ToID != playerID && mail.FromID != playerID {
+		return models.Mail{}, fmt.Errorf("mail id %s: %w", mailID, apperrors.ErrNotFound)
+	}
+	if mail.ToID == playerID && mail.ReadAt == nil {
+		now := s.now()
+		if err := s.mail.MarkRead(ctx, mail.ID, now); err != nil {
+			return models.Mail{}, err
+		}
+		mail.ReadAt = &now
+	}
+	return mail, nil
+}
+
+// Claim hands the attachments to the recipient.
+func (s *Service) Claim(ctx context.Context, playerID, mailID string) (models.Mail, error) {
+	var out models.Mail
+	err := mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+		mail, err := s.mail.GetMail(txCtx, mailID)
+		if err != nil {
+			return fmt.Errorf("load mail: %w", err)
+		}
+		if mail.ToID != playerID {
+			return fmt.Errorf("mail id %s: %w", mailID, apperrors.ErrNotFound)
+		}
+		now := s.now()
+		if mail.Status != models.MailStatusSent || !now.Before(mail.ExpiresAt) {
+			return fmt.Errorf("mail attachments are no longer claimable: %w", apperrors.ErrConflict)
+		}
+		if !mail.HasAttachments() {
+			return fmt.Errorf("mail has no attachments: %w", apperrors.ErrConflict)
+		}
+		if out, err = s.mail.CloseMail(txCtx, mail.ID, models.MailStatusClaimed, now); err != nil {
+			return err
+		}
+		from := models.AccountEscrow
+		if mail.System {
+			from = models.AccountMint
+		}
+		return s.deliver(txCtx, mail, playerID, from, models.LedgerReasonMail, now)
+	})
+	if err != nil {
+		return models.Mail{}, fmt.Errorf("transaction claim mail: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Service) Inbox(ctx context.Context, playerID string, params models.QueryParams) ([]models.Mail, error) {
+	mail, err := s.mail.ListInbox(ctx, playerID, s.now(), params)
+	if err != nil {
+		return nil, fmt.Errorf("list mail inbox: %w", err)
+	}
+	return mail, nil
+}
+
+func (s *Service) Sent(ctx context.Context, playerID string, params models.QueryParams) ([]models.Mail, error) {
+	mail, err := s.mail.ListSent(ctx, playerID, params)
+	if err != nil {
+		return nil, fmt.Errorf("list sent mail: %w", err)
+	}
+	return mail, nil
+}
+
+// ExpireMail closes every unclaimed mail past its expiresAt and sends player
+// attachments back to the sender. Unclaimed system attachments are dropped.
+func (s *Service) ExpireMail(ctx context.Context) (int, error) {
+	now := s.now()
+	expired := 0
+	for {
+		due, err := s.mail.ListDueMail(ctx, now, expiryBatch)
+		if err != nil {
+			return expired, fmt.Errorf("list due mail: %w", err)
+		}
+		for _, m := range due {
+			var ok bool
+			err := mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+				mail, err := s.mail.GetMail(txCtx, m.ID)
+				if err != nil {
+					return fmt.Errorf("load mail: %w", err)
+				}
+				if mail.Status != models.MailStatusSent || mail.ExpiresAt.After(now) {
+					return nil
+				}
+				returned := !mail.System && mail.HasAttachments()
+				status := models.MailStatusExpired
+				if returned {
+					status = models.MailStatusReturned
+				}
+				if _, err := s.mail.CloseMail(txCtx, mail.ID, status, now); err != nil {
+					return err
+				}
+				if returned {
+					if err := s.deliver(txCtx, mail, mail.FromID, models.AccountEscrow, models.LedgerReasonRefund, now); err != nil {
+						return err
+					}
+				}
+				ok = true
+				return nil
+			})
+			if err != nil {
+				return expired, fmt.Errorf("transaction expire mail %s: %w", m.ID, err)
+			}
+			if ok {
+				expired++
+			}
+		}
+		if len(due) < expiryBatch {
+			return expired, nil
+		}
+	}
+}
+
+func newMail(toID, subject, body string, items []models.MailItem, gold int64, now time.Time, ttl time.Duration) models.Mail {
+	if items == nil {
+		items = make([]models.MailItem, 0)
+	}
+	return models.Mail{
+		ID:        functions.NewUUID(),
+		ToID:      toID,
+		Subject:   subject,
+		Body:      body,
+		Items:     items,
+		Gold:      gold,
+		Status:    models.MailStatusSent,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+}
+
+// checkItems makes sure every attached item exists. Players can only attach
+// tradable items; the system can attach anything.
+func (s *Service) checkItems(ctx context.Context, items []models.MailItem, tradable bool) error {
+	for _, it := range items {
+		item, err := s.inventory.GetItemDef(ctx, it.ItemID)
+		if err != nil {
+			return fmt.Errorf("load item def: %w", err)
+		}
+		if tradable && !item.Tradable {
+			return fmt.Errorf("item %s not tradable: %w", it.ItemID, apperrors.ErrConflict)
+		}
+	}
+	return nil
+}
+
+// allPlayers collects every player ID once, even if pages shift while new
+// players register.
+func (s *Service) allPlayers(ctx context.Context) ([]string, error) {
+	seen := map[string]bool{}
+	ids := make([]string, 0)
+	for page := int64(1); ; page++ {
+		batch, err := s.players.List(ctx, models.QueryParams{Page: page, Limit: 100})
+		if err != nil {
+			return nil, fmt.Errorf("list players: %w", err)
+		}
+		for _, p := range batch {
+			if !seen[p.ID] {
+				seen[p.ID] = true
+				ids = append(ids, p.ID)
+			}
+		}
+		if len(batch) < 100 {
+			return ids, nil
+		}
+	}
+}
+
+// deliver hands a mail's attachments to playerID, paying the gold out of
+// from. It must run in a transaction.
+func (s *Service) deliver(txCtx context.Context, mail models.Mail, playerID, from string, reason models.LedgerReason, now time.Time) error {
+	for _, it := range mail.Items {
+		if err := s.inventory.AddItem(txCtx, playerID, it.ItemID, it.Qty, now); err != nil {
+			return fmt.Errorf("deliver attached item %s: %w", it.ItemID, err)
+		}
+	}
+	if mail.Gold == 0 {
+		return nil
+	}
+	t := models.GoldTransfer{
+		From:   from,
+		To:     models.PlayerAccount(playerID),
+		Amount: mail.Gold,
+		Reason: reason,
+		Ref:    mail.ID,
+	}
+	if err := s.players.Transfer(txCtx, t, now); err != nil {
+		return fmt.Errorf("deliver attached gold: %w", err)
+	}
+	return nil
+}
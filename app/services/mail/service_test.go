@@ -0,0 +1,68 @@
+package mail
+
+import (
+	"context"
+	"dungeons/app/models"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type mailboxStub struct {
+	MailRepository
+	mail []models.Mail
+}
+
+func (m *mailboxStub) CreateMail(_ context.Context, mail ...models.Mail) error {
+	m.mail = append(m.mail, mail...)
+	return nil
+}
+
+// shiftingPlayers pretends a player registered between the first and second
+// page, pushing the last player of page one onto page two.
+type shiftingPlayers struct {
+	PlayerRepository
+	ids []string
+}
+
+func (p shiftingPlayers) List(_ context.Context, params models.QueryParams) ([]models.Player, error) {
+	start := (params.Page - 1) * params.Limit
+	if params.Page > 1 {
+		start--
+	}
+	out := make([]models.Player, 0)
+	for i := start; i < start+params.Limit && i < int64(len(p.ids)); i++ {
+		out = append(out, models.Player{ID: p.ids[i]})
+	}
+	return out, nil
+}
+
+func TestSendSystemToAllMailsEachPlayerOnce(t *testing.T) {
+	ids := make([]string, 150)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("p%03d", i)
+	}
+	box := &mailboxStub{}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc := &Service{mail: box, players: shiftingPlayers{ids: ids}, validate: validator.New(), now: func() time.Time { return now }}
+
+	sent, err := svc.SendSystem(context.Background(), "admin", models.SystemMailRequest{All: true, Subject: "Outage", Gold: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != len(ids) || len(box.mail) != len(ids) {
+		t.Fatalf("sent %d mails (%d stored), want %d", sent, len(box.mail), len(ids))
+	}
+	seen := map[string]bool{}
+	for _, m := range box.mail {
+		if seen[m.ToID] {
+			t.Fatalf("%s got two copies", m.ToID)
+		}
+		seen[m.ToID] = true
+		if !m.System || m.Gold != 100 || m.Status != models.MailStatusSent || !m.ExpiresAt.Equal(now.Add(mailTTL)) {
+			t.Fatalf("mail = %+v", m)
+		}
+	}
+}
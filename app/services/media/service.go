@@ -0,0 +1,104 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/imaging"
+	"dungeons/app/models"
+	"dungeons/app/storage"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	MaxImageBytes = 10 << 20
+	maxSide       = 2048
+	thumbSide     = 320
+	urlPrefix     = "/v1/media/"
+)
+
+var namePattern = regexp.MustCompile(`^[0-9a-f]{64}\.(jpg|png)$`)
+
+type Service struct {
+	blobs storage.BlobStore
+	now   func() time.Time
+}
+
+func New(blobs storage.BlobStore) *Service {
+	return &Service{
+		blobs: blobs,
+		now:   func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Store re-encodes the upload, which drops EXIF (GPS included), and saves
+// it next to a thumbnail under names derived from their content.
+func (s *Service) Store(ctx context.Context, r io.Reader) (models.Image, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxImageBytes+1))
+	if err != nil {
+		return models.Image{}, fmt.Errorf("read image: %w", err)
+	}
+	if len(data) > MaxImageBytes {
+		return models.Image{}, fmt.Errorf("image exceeds %d bytes: %w", MaxImageBytes, apperrors.ErrValidation)
+	}
+	img, format, err := imaging.Load(data)
+	if err != nil {
+		return models.Image{}, fmt.Errorf("%s: %w", err.Error(), apperrors.ErrValidation)
+	}
+	full, err := imaging.Encode(imaging.Fit(img, maxSide), format)
+	if err != nil {
+		return models.Image{}, err
+	}
+	thumb, err := imaging.Encode(imaging.Fit(img, thumbSide), format)
+	if err != nil {
+		return models.Image{}, err
+	}
+	fullName, err := s.put(ctx, full)
+	if err != nil {
+		return models.Image{}, err
+	}
+	thumbName, err := s.put(ctx, thumb)
+	if err != nil {
+		return models.Image{}, err
+	}
+	return models.Image{
+		URL:          urlPrefix + fullName,
+		ThumbnailURL: urlPrefix + thumbName,
+		ContentType:  full.ContentType,
+		Width:        full.Width,
+		Height:       full.Height,
+		UploadedAt:   s.now(),
+	}, nil
+}
+
+func (s *Service) put(ctx context.Context, enc imaging.Encoded) (string, error) {
+	sum := sha256.Sum256(enc.Data)
+	name := hex.EncodeToString(sum[:]) + "." + enc.Ext
+	if err := s.blobs.Put(ctx, "media/"+name, bytes.NewReader(enc.Data)); err != nil {
+		return "", fmt.Errorf("store image: %w", err)
+	}
+	return name, nil
+}
+
+// Open returns a stored image and its content type. name is the last path
+// segment of an Image URL.
+func (s *Service) Open(ctx context.Context, name string) (io.ReadCloser, string, error) {
+	if !namePattern.MatchString(name) {
+		return nil, "", fmt.Errorf("media %s: %w", name, apperrors.ErrNotFound)
+	}
+	contentType := "image/png"
+	if strings.HasSuffix(name, ".jpg") {
+		contentType = "image/jpeg"
+	}
+	blob, err := s.blobs.Open(ctx, "media/"+name)
+	if err != nil {
+		return nil, "", fmt.Errorf("open media: %w", err)
+	}
+	return blob, contentType, nil
+}
@@ -0,0 +1,403 @@
+package offer
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/functions"
+	"dungeons/app/models"
+	"dungeons/app/mongodb"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	defaultOfferTTL = 48 * time.Hour
+	expiryBatch     = 100
+)
+
+type OfferRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	CreateOffer(ctx context.Context, offer models.TradeOffer) error
+	GetOffer(ctx context.Context, id string) (models.TradeOffer, error)
+	ReplacePendingOffer(ctx context.Context, offer models.TradeOffer) (models.TradeOffer, error)
+	ListInbox(ctx context.Context, playerID string, now time.Time, params models.QueryParams) ([]models.TradeOffer, error)
+	ListSent(ctx context.Context, playerID string, params models.QueryParams) ([]models.TradeOffer, error)
+	ListDueOffers(ctx context.Context, now time.Time, limit int64) ([]models.TradeOffer, error)
+}
+
+type InventoryRepository interface {
+	GetItemDef(ctx context.Context, itemID string) (models.ItemDef, error)
+	AddItem(ctx context.Context, playerID, itemID string, qty int64, updatedAt time.Time) error
+	RemoveItem(ctx context.Context, playerID, itemID string, qty int64, updatedAt time.Time) error
+}
+
+type PlayerRepository interface {
+	GetByID(ctx context.Context, id string) (models.Player, error)
+	Transfer(ctx context.Context, t models.GoldTransfer, at time.Time) error
+}
+
+type Service struct {
+	offers    OfferRepository
+	inventory InventoryRepository
+	players   PlayerRepository
+	validate  *validator.Validate
+	client    *mongo.Client
+	now       func() time.Time
+}
+
+func New(offers OfferRepository, inventory InventoryRepository, players PlayerRepository, validate *validator.Validate, client *mongo.Client) *Service {
+	return &Service{
+		offers:    offers,
+		inventory: inventory,
+		players:   players,
+		validate:  validate,
+		client:    client,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (s *Service) EnsureIndexes(ctx context.Context) error {
+	if err := s.offers.EnsureIndexes(ctx); err != nil {
+		return fmt.Errorf("ensure offer indexes: %w", err)
+	}
+	return nil
+}
+
+// Create escrows the sender's side of the offer. The recipient's side is
+// only taken when they accept.
+func (s *Service) Create(ctx context.Context, fromID string, req models.CreateOfferRequest) (models.TradeOffer, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return models.TradeOffer{}, fmt.Errorf("validate create offer: %w", apperrors.ErrValidation)
+	}
+	if req.ToID == fromID {
+		return models.TradeOffer{}, fmt.Errorf("cannot trade with yourself: %w", apperrors.ErrValidation)
+	}
+	if _, err := s.players.GetByID(ctx, req.ToID); err != nil {
+		return models.TradeOffer{}, fmt.Errorf("load recipient: %w", err)
+	}
+	if err := s.checkSides(ctx, req.Give, req.Want); err != nil {
+		return models.TradeOffer{}, err
+	}
+
+	offer := s.newOffer(fromID, req.ToID, req.Give, req.Want, req.Message, req.ExpiresInH)
+	err := mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+		return s.propose(txCtx, offer)
+	})
+	if err != nil {
+		return models.TradeOffer{}, fmt.Errorf("transaction create offer: %w", err)
+	}
+	return offer, nil
+}
+
+// Counter closes an offer the player received and sends its author a new
+// one in its place. The original escrow goes back to its author.
+func (s *Service) Counter(ctx context.Context, playerID, offerID string, req models.CounterOfferRequest) (models.TradeOffer, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return models.TradeOffer{}, fmt.Errorf("validate counter offer: %w", apperrors.ErrValidation)
+	}
+	if err := s.checkSides(ctx, req.Give, req.Want); err != nil {
+		return models.TradeOffer{}, err
+	}
+
+	var counter models.TradeOffer
+	err := mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+		offer, err := s.pending(txCtx, offerID, playerID)
+		if err != nil {
+			return err
+		}
+		if _, err := s.close(txCtx, offer, models.OfferStatusCountered); err != nil {
+			return err
+		}
+		counter = s.newOffer(playerID, offer.FromID, req.Give, req.Want, req.Message, req.ExpiresInH)
+		counter.CounterOf = offer.ID
+		return s.propose(txCtx, counter)
+	})
+	if err != nil {
+		return models.TradeOffer{}, fmt.Errorf("transaction counter offer: %w", err)
+	}
+	return counter, nil
+}
+
+// Accept swaps both sides in one transaction: the recipient's side is taken
+// from them and the escrowed side is handed over.
+func (s *Service) Accept(ctx context.Context, playerID, offerID string) (models.TradeOffer, error) {
+	var out models.TradeOffer
+	err := mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+		offer, err := s.pending(txCtx, offerID, playerID)
+		if err != nil {
+			return err
+		}
+		now := s.now()
+		if err := s.swap(txCtx, offer, now); err != nil {
+			return err
+		}
+		offer.Status = models.OfferStatusAccepted
+		offer.RespondedAt = &now
+		out, err = s.offers.ReplacePendingOffer(txCtx, offer)
+		if err != nil {
+			return fmt.Errorf("accept offer: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.TradeOffer{}, fmt.Errorf("transaction accept offer: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Service) Decline(ctx context.Context, playerID, offerID string) (models.TradeOffer, error) {
+	var out models.TradeOffer
+	err := mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+		offer, err := s.pending(txCtx, offerID, playerID)
+		if err != nil {
+			return err
+		}
+		out, err = s.close(txCtx, offer, models.OfferStatusDeclined)
+		return err
+	})
+	if err != nil {
+		return models.TradeOffer{}, fmt.Errorf("transaction decline offer: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Service) Cancel(ctx context.Context, playerID, offerID string) (models.TradeOffer, error) {
+	var out models.TradeOffer
+	err := mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+		offer, err := s.offers.GetOffer(txCtx, offerID)
+		if err != nil {
+			return fmt.Errorf("load offer: %w", err)
+		}
+		if offer.FromID != playerID {
+			return fmt.Errorf("only the sender can cancel an offer: %w", apperrors.ErrForbidden)
+		}
+		if offer.Status != models.OfferStatusPending {
+			return fmt.Errorf("offer is %s: %w", offer.Status, apperrors.ErrConflict)
+		}
+		out, err = s.close(txCtx, offer, models.OfferStatusCancelled)
+		return err
+	})
+	if err != nil {
+		return models.TradeOffer{}, fmt.Errorf("transaction cancel offer: %w", err)
+	}
+	return out, nil
+}
+
+// Get only shows an offer to the two players in it.
+func (s *Service) Get(ctx context.Context, playerID, offerID string) (models.TradeOffer, error) {
+	offer, err := s.offers.GetOffer(ctx, offerID)
+	if err != nil {
+		return models.TradeOffer{}, fmt.Errorf("load offer: %w", err)
+	}
+	if offer.FromID != playerID && offer.ToID != playerID {
+		return models.TradeOffer{}, fmt.Errorf("offer id %s: %w", offerID, apperrors.ErrNotFound)
+	}
+	return offer, nil
+}
+
+func (s *Service) Inbox(ctx context.Context, playerID string, params models.QueryParams) ([]models.TradeOffer, error) {
+	offers, err := s.offers.ListInbox(ctx, playerID, s.now(), params)
+	if err != nil {
+		return nil, fmt.Errorf("list offer inbox: %w", err)
+	}
+	return offers, nil
+}
+
+func (s *Service) Sent(ctx context.Context, playerID string, params models.QueryParams) ([]models.TradeOffer, error) {
+	offers, err := s.offers.ListSent(ctx, playerID, params)
+	if err != nil {
+		return nil, fmt.Errorf("list sent offers: %w", err)
+	}
+	return offers, nil
+}
+
+// ExpireOffers returns the escrow of every pending offer past its expiresAt.
+// Each offer is re-read inside its transaction, so one answered or expired
+// by another instance in the meantime is skipped.
+func (s *Service) ExpireOffers(ctx context.Context) (int, error) {
+	now := s.now()
+	expired := 0
+	for {
+		due, err := s.offers.ListDueOffers(ctx, now, expiryBatch)
+		if err != nil {
+			return expired, fmt.Errorf("list due offers: %w", err)
+		}
+		for _, o := range due {
+			var ok bool
+			err := mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+				offer, err := s.offers.GetOffer(txCtx, o.ID)
+				if err != nil {
+					return fmt.Errorf("load offer: %w", err)
+				}
+				if offer.Status != models.OfferStatusPending || offer.ExpiresAt.After(now) {
+					return nil
+				}
+				if _, err := s.close(txCtx, offer, models.OfferStatusExpired); err != nil {
+					return err
+				}
+				ok = true
+				return nil
+			})
+			if err != nil {
+				return expired, fmt.Errorf("transaction expire offer %s: %w", o.ID, err)
+			}
+			if ok {
+				expired++
+			}
+		}
+		if len(due) < expiryBatch {
+			return expired, nil
+		}
+	}
+}
+
+func (s *Service) newOffer(fromID, toID string, give, want models.OfferSide, message string, expiresInH int64) models.TradeOffer {
+	now := s.now()
+	ttl := defaultOfferTTL
+	if expiresInH > 0 {
+		ttl = time.Duration(expiresInH) * time.Hour
+	}
+	if give.Items == nil {
+		give.Items = make([]models.OfferItem, 0)
+	}
+	if want.Items == nil {
+		want.Items = make([]models.OfferItem, 0)
+	}
+	return models.TradeOffer{
+		ID:        functions.NewUUID(),
+		FromID:    fromID,
+		ToID:      toID,
+		Give:      give,
+		Want:      want,
+		Message:   message,
+		Status:    models.OfferStatusPending,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+}
+
+func (s *Service) checkSides(ctx context.Context, give, want models.OfferSide) error {
+	if give.Empty() && want.Empty() {
+		return fmt.Errorf("offer is empty: %w", apperrors.ErrValidation)
+	}
+	for _, side := range []models.OfferSide{give, want} {
+		for _, it := range side.Items {
+			item, err := s.inventory.GetItemDef(ctx, it.ItemID)
+			if err != nil {
+				return fmt.Errorf("load item def: %w", err)
+			}
+			if !item.Tradable {
+				return fmt.Errorf("item %s not tradable: %w", it.ItemID, apperrors.ErrConflict)
+			}
+		}
+	}
+	return nil
+}
+
+// pending loads an offer the player received that can still be answered.
+func (s *Service) pending(txCtx context.Context, offerID, playerID string) (models.TradeOffer, error) {
+	offer, err := s.offers.GetOffer(txCtx, offerID)
+	if err != nil {
+		return models.TradeOffer{}, fmt.Errorf("load offer: %w", err)
+	}
+	if offer.ToID != playerID {
+		return models.TradeOffer{}, fmt.Errorf("only the recipient can answer an offer: %w", apperrors.ErrForbidden)
+	}
+	if offer.Status != models.OfferStatusPending || !s.now().Before(offer.ExpiresAt) {
+		return models.TradeOffer{}, fmt.Errorf("offer is no longer pending: %w", apperrors.ErrConflict)
+	}
+	return offer, nil
+}
+
+// propose escrows the sender's side and stores the offer. It must run in a
+// transaction.
+func (s *Service) propose(txCtx context.Context, offer models.TradeOffer) error {
+	now := s.now()
+	for _, it := range offer.Give.Items {
+		if err := s.inventory.RemoveItem(txCtx, offer.FromID, it.ItemID, it.Qty, now); err != nil {
+			return fmt.Errorf("escrow offered item %s: %w", it.ItemID, err)
+		}
+	}
+	if offer.Give.Gold > 0 {
+		t := models.GoldTransfer{
+			From:   models.PlayerAccount(offer.FromID),
+			To:     models.AccountEscrow,
+			Amount: offer.Give.Gold,
+			Reason: models.LedgerReasonEscrow,
+			Ref:    offer.ID,
+		}
+		if err := s.players.Transfer(txCtx, t, now); err != nil {
+			return fmt.Errorf("escrow offered gold: %w", err)
+		}
+	}
+	if err := s.offers.CreateOffer(txCtx, offer); err != nil {
+		return fmt.Errorf("create offer: %w", err)
+	}
+	return nil
+}
+
+// close gives the escrowed side back to the sender and moves the offer out
+// of pending. It must run in a transaction.
+func (s *Service) close(txCtx context.Context, offer models.TradeOffer, status models.OfferStatus) (models.TradeOffer, error) {
+	now := s.now()
+	offer.Status = status
+	offer.RespondedAt = &now
+	out, err := s.offers.ReplacePendingOffer(txCtx, offer)
+	if err != nil {
+		return models.TradeOffer{}, fmt.Errorf("close offer: %w", err)
+	}
+	for _, it := range offer.Give.Items {
+		if err := s.inventory.AddItem(txCtx, offer.FromID, it.ItemID, it.Qty, now); err != nil {
+			return models.TradeOffer{}, fmt.Errorf("return offered item %s: %w", it.ItemID, err)
+		}
+	}
+	if offer.Give.Gold > 0 {
+		t := models.GoldTransfer{
+			From:   models.AccountEscrow,
+			To:     models.PlayerAccount(offer.FromID),
+			Amount: offer.Give.Gold,
+			Reason: models.LedgerReasonRefund,
+			Ref:    offer.ID,
+		}
+		if err := s.players.Transfer(txCtx, t, now); err != nil {
+			return models.TradeOffer{}, fmt.Errorf("return offered gold: %w", err)
+		}
+	}
+	return out, nil
+}
+
+// swap takes the wanted side from the recipient and hands both sides over.
+// It must run in a transaction.
+func (s *Service) swap(txCtx context.Context, offer models.TradeOffer, now time.Time) error {
+	for _, it := range offer.Want.Items {
+		if err := s.inventory.RemoveItem(txCtx, offer.ToID, it.ItemID, it.Qty, now); err != nil {
+			return fmt.Errorf("take wanted item %s: %w", it.ItemID, err)
+		}
+		if err := s.inventory.AddItem(txCtx, offer.FromID, it.ItemID, it.Qty, now); err != nil {
+			return fmt.Errorf("deliver wanted item %s: %w", it.ItemID, err)
+		}
+	}
+	for _, it := range offer.Give.Items {
+		if err := s.inventory.AddItem(txCtx, offer.ToID, it.ItemID, it.Qty, now); err != nil {
+			return fmt.Errorf("deliver offered item %s: %w", it.ItemID, err)
+		}
+	}
+	transfers := []models.GoldTransfer{
+		{From: models.PlayerAccount(offer.ToID), To: models.PlayerAccount(offer.FromID), Amount: offer.Want.Gold},
+		{From: models.AccountEscrow, To: models.PlayerAccount(offer.ToID), Amount: offer.Give.Gold},
+	}
+	for _, t := range transfers {
+		if t.Amount == 0 {
+			continue
+		}
+		t.Reason = models.LedgerReasonTrade
+		t.Ref = offer.ID
+		if err := s.players.Transfer(txCtx, t, now); err != nil {
+			return fmt.Errorf("swap gold: %w", err)
+		}
+	}
+	return nil
+}
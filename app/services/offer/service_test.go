@@ -0,0 +1,76 @@
+package offer
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/models"
+	"errors"
+	"testing"
+	"time"
+)
+
+type walletStub struct {
+	gold map[string]int64
+}
+
+func (w walletStub) GetByID(_ context.Context, id string) (models.Player, error) {
+	return models.Player{ID: id}, nil
+}
+
+func (w walletStub) Transfer(_ context.Context, t models.GoldTransfer, _ time.Time) error {
+	w.gold[t.From] -= t.Amount
+	w.gold[t.To] += t.Amount
+	return nil
+}
+
+type bagStub struct {
+	items map[string]int64
+}
+
+func (b bagStub) GetItemDef(_ context.Context, itemID string) (models.ItemDef, error) {
+	return models.ItemDef{ID: itemID, Tradable: true}, nil
+}
+
+func (b bagStub) AddItem(_ context.Context, playerID, itemID string, qty int64, _ time.Time) error {
+	b.items[playerID+":"+itemID] += qty
+	return nil
+}
+
+func (b bagStub) RemoveItem(_ context.Context, playerID, itemID string, qty int64, _ time.Time) error {
+	if b.items[playerID+":"+itemID] < qty {
+		return apperrors.ErrConflict
+	}
+	b.items[playerID+":"+itemID] -= qty
+	return nil
+}
+
+func TestSwapHandsOverBothSides(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	wallets := walletStub{gold: map[string]int64{models.AccountEscrow: 30}}
+	bags := bagStub{items: map[string]int64{"bob:shield": 1}}
+	svc := &Service{inventory: bags, players: wallets}
+
+	offer := models.TradeOffer{
+		ID:     "o1",
+		FromID: "alice",
+		ToID:   "bob",
+		Give:   models.OfferSide{Items: []models.OfferItem{{ItemID: "sword", Qty: 2}}, Gold: 30},
+		Want:   models.OfferSide{Items: []models.OfferItem{{ItemID: "shield", Qty: 1}}, Gold: 5},
+	}
+	if err := svc.swap(context.Background(), offer, now); err != nil {
+		t.Fatal(err)
+	}
+
+	if bags.items["alice:shield"] != 1 || bags.items["bob:shield"] != 0 || bags.items["bob:sword"] != 2 {
+		t.Fatalf("items = %v", bags.items)
+	}
+	alice, bob := wallets.gold[models.PlayerAccount("alice")], wallets.gold[models.PlayerAccount("bob")]
+	if alice != 5 || bob != 25 || wallets.gold[models.AccountEscrow] != 0 {
+		t.Fatalf("alice got %d, bob %d, escrow left %d; want 5, 25, 0", alice, bob, wallets.gold[models.AccountEscrow])
+	}
+
+	offer.Want.Items[0].Qty = 3
+	if err := svc.swap(context.Background(), offer, now); !errors.Is(err, apperrors.ErrConflict) {
+		t.Fatalf("swap without the wanted items = %v, want conflict", err)
+	}
+}
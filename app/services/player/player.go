@@ -19,6 +19,7 @@ type Repository interface {
 	GetByEmail(ctx context.Context, email string) (models.Player, error)
 	List(ctx context.Context, params models.QueryParams) ([]models.Player, error)
 	UpdateDisplayName(ctx context.Context, id, displayName string, updatedAt time.Time) (models.Player, error)
+	Transfer(ctx context.Context, t models.GoldTransfer, at time.Time) error
 }
 
 type TokenSigner interface {
@@ -147,3 +148,30 @@ func (s *Service) UpdateDisplayName(ctx context.Context, id string, req models.U
 	}
 	return updated.ToResponse(), nil
 }
+
+// GrantGold mints gold for a player, or takes it back into the mint when
+// amount is negative. The admin and their memo end up in the ledger.
+func (s *Service) GrantGold(ctx context.Context, adminID, playerID string, req models.GrantGoldRequest) (models.PlayerResponse, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return models.PlayerResponse{}, fmt.Errorf("validate grant gold request: %w", apperrors.ErrValidation)
+	}
+	t := models.GoldTransfer{
+		From:   models.AccountMint,
+		To:     models.PlayerAccount(playerID),
+		Amount: req.Amount,
+		Reason: models.LedgerReasonAdminGrant,
+		Ref:    "admin:" + adminID,
+		Memo:   req.Memo,
+	}
+	if req.Amount < 0 {
+		t.From, t.To, t.Amount = t.To, t.From, -req.Amount
+	}
+	if err := s.repo.Transfer(ctx, t, s.now()); err != nil {
+		return models.PlayerResponse{}, fmt.Errorf("grant gold: %w", err)
+	}
+	player, err := s.repo.GetByID(ctx, playerID)
+	if err != nil {
+		return models.PlayerResponse{}, fmt.Errorf("get player after grant: %w", err)
+	}
+	return player.ToResponse(), nil
+}
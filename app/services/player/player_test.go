@@ -31,6 +31,9 @@ func (s *playerRepoStub) GetByEmail(context.Context, string) (models.Player, err
 func (s *playerRepoStub) List(context.Context, models.QueryParams) ([]models.Player, error) {
 	return nil, errors.New("not implemented")
 }
+func (s *playerRepoStub) Transfer(context.Context, models.GoldTransfer, time.Time) error {
+	return nil
+}
 func (s *playerRepoStub) UpdateDisplayName(context.Context, string, string, time.Time) (models.Player, error) {
 	return models.Player{}, errors.New("not implemented")
 }
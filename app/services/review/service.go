@@ -0,0 +1,188 @@
+package review
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/functions"
+	"dungeons/app/models"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type Repository interface {
+	EnsureIndexes(ctx context.Context) error
+	CreateReview(ctx context.Context, review models.Review) error
+	GetReviewByID(ctx context.Context, id string) (models.Review, error)
+	GetReviewByPlayer(ctx context.Context, dungeonID, playerID string) (models.Review, error)
+	ReplaceReview(ctx context.Context, review models.Review) (models.Review, error)
+	ListByDungeon(ctx context.Context, dungeonID string, params models.QueryParams) ([]models.Review, error)
+	RatingSummary(ctx context.Context, dungeonID string) (models.DungeonRating, error)
+}
+
+type DungeonRepository interface {
+	GetDungeonByID(ctx context.Context, id string) (models.Dungeon, error)
+	UpdateDungeonRating(ctx context.Context, id string, rating models.DungeonRating) error
+}
+
+type RunRepository interface {
+	HasCompletedRun(ctx context.Context, playerID, dungeonID string) (bool, error)
+}
+
+type Service struct {
+	repo     Repository
+	dungeons DungeonRepository
+	runs     RunRepository
+	validate *validator.Validate
+	now      func() time.Time
+}
+
+func New(repo Repository, dungeons DungeonRepository, runs RunRepository, validate *validator.Validate) *Service {
+	return &Service{
+		repo:     repo,
+		dungeons: dungeons,
+		runs:     runs,
+		validate: validate,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (s *Service) EnsureIndexes(ctx context.Context) error {
+	if err := s.repo.EnsureIndexes(ctx); err != nil {
+		return fmt.Errorf("review ensure indexes: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) Upsert(ctx context.Context, playerID, dungeonID string, req models.UpsertReviewRequest) (models.Review, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return models.Review{}, fmt.Errorf("validate review: %w", apperrors.ErrValidation)
+	}
+	completed, err := s.runs.HasCompletedRun(ctx, playerID, dungeonID)
+	if err != nil {
+		return models.Review{}, fmt.Errorf("check completed run: %w", err)
+	}
+	if !completed {
+		return models.Review{}, fmt.Errorf("dungeon must be completed before reviewing: %w", apperrors.ErrForbidden)
+	}
+
+	now := s.now()
+	var out models.Review
+	existing, err := s.repo.GetReviewByPlayer(ctx, dungeonID, playerID)
+	switch {
+	case err == nil:
+		existing.Rating = req.Rating
+		existing.Text = req.Text
+		existing.UpdatedAt = now
+		out, err = s.repo.ReplaceReview(ctx, existing)
+		if err != nil {
+			return models.Review{}, fmt.Errorf("update review: %w", err)
+		}
+	case errors.Is(err, apperrors.ErrNotFound):
+		out = models.Review{
+			ID:        functions.NewUUID(),
+			DungeonID: dungeonID,
+			PlayerID:  playerID,
+			Rating:    req.Rating,
+			Text:      req.Text,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := s.repo.CreateReview(ctx, out); err != nil {
+			return models.Review{}, fmt.Errorf("create review: %w", err)
+		}
+	default:
+		return models.Review{}, fmt.Errorf("load player review: %w", err)
+	}
+
+	if err := s.refreshRating(ctx, dungeonID); err != nil {
+		return models.Review{}, err
+	}
+	return out, nil
+}
+
+func (s *Service) List(ctx context.Context, dungeonID string, params models.QueryParams) ([]models.Review, error) {
+	d, err := s.dungeons.GetDungeonByID(ctx, dungeonID)
+	if err != nil {
+		return nil, fmt.Errorf("get dungeon: %w", err)
+	}
+	if !d.IsLive(s.now()) {
+		return nil, fmt.Errorf("dungeon is not published: %w", apperrors.ErrNotFound)
+	}
+	reviews, err := s.repo.ListByDungeon(ctx, dungeonID, params)
+	if err != nil {
+		return nil, fmt.Errorf("list reviews: %w", err)
+	}
+	return reviews, nil
+}
+
+func (s *Service) Reply(ctx context.Context, mjID, dungeonID, reviewID string, req models.ReplyReviewRequest) (models.Review, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return models.Review{}, fmt.Errorf("validate review reply: %w", apperrors.ErrValidation)
+	}
+	d, err := s.dungeons.GetDungeonByID(ctx, dungeonID)
+	if err != nil {
+		return models.Review{}, fmt.Errorf("get dungeon: %w", err)
+	}
+	if !d.RoleOf(mjID).Allows(models.CollaboratorEditor) {
+		return models.Review{}, fmt.Errorf("cannot reply on foreign dungeon: %w", apperrors.ErrForbidden)
+	}
+	review, err := s.repo.GetReviewByID(ctx, reviewID)
+	if err != nil {
+		return models.Review{}, fmt.Errorf("get review: %w", err)
+	}
+	if review.DungeonID != dungeonID {
+		return models.Review{}, fmt.Errorf("review %s: %w", reviewID, apperrors.ErrNotFound)
+	}
+	now := s.now()
+	reply := models.ReviewReply{AuthorID: mjID, Text: req.Text, CreatedAt: now, UpdatedAt: now}
+	if review.Reply != nil {
+		reply.CreatedAt = review.Reply.CreatedAt
+	}
+	review.Reply = &reply
+	updated, err := s.repo.ReplaceReview(ctx, review)
+	if err != nil {
+		return models.Review{}, fmt.Errorf("reply review: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *Service) Moderate(ctx context.Context, moderatorID, reviewID string, req models.ModerateReviewRequest) (models.Review, error) {
+	if err := s.validate.Struct(req); err != nil {
+		return models.Review{}, fmt.Errorf("validate review moderation: %w", apperrors.ErrValidation)
+	}
+	review, err := s.repo.GetReviewByID(ctx, reviewID)
+	if err != nil {
+		return models.Review{}, fmt.Errorf("get review: %w", err)
+	}
+	review.Hidden = *req.Hidden
+	review.HiddenBy = ""
+	review.HiddenReason = ""
+	if review.Hidden {
+		review.HiddenBy = moderatorID
+		review.HiddenReason = req.Reason
+	}
+	updated, err := s.repo.ReplaceReview(ctx, review)
+	if err != nil {
+		return models.Review{}, fmt.Errorf("moderate review: %w", err)
+	}
+	if err := s.refreshRating(ctx, review.DungeonID); err != nil {
+		return models.Review{}, err
+	}
+	return updated, nil
+}
+
+func (s *Service) refreshRating(ctx context.Context, dungeonID string) error {
+	rating, err := s.repo.RatingSummary(ctx, dungeonID)
+	if err != nil {
+		return fmt.Errorf("summarize rating: %w", err)
+	}
+	rating.Average = math.Round(rating.Average*100) / 100
+	if err := s.dungeons.UpdateDungeonRating(ctx, dungeonID, rating); err != nil {
+		return fmt.Errorf("update dungeon rating: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package run
+
+import (
+	"context"
+	"dungeons/app/i18n"
+	"dungeons/app/models"
+	"fmt"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+type trackPoint struct {
+	Step     models.BossStep
+	KilledAt time.Time
+}
+
+// track lists the player's kills in order, located at the boss they beat.
+func (s *Service) track(ctx context.Context, playerID, runID string, prefs []language.Tag) (models.Run, models.Dungeon, []trackPoint, error) {
+	run, err := s.Get(ctx, playerID, runID)
+	if err != nil {
+		return run, models.Dungeon{}, nil, err
+	}
+	dungeon, err := s.dungeons.GetDungeonByID(ctx, run.DungeonID)
+	if err != nil {
+		return run, dungeon, nil, fmt.Errorf("get dungeon: %w", err)
+	}
+	steps, err := s.dungeons.ListStepsByDungeon(ctx, run.DungeonID)
+	if err != nil {
+		return run, dungeon, nil, fmt.Errorf("list steps: %w", err)
+	}
+	if dungeon.DefaultLang == "" {
+		dungeon.DefaultLang = i18n.DefaultLang
+	}
+	lang := i18n.Pick(prefs, dungeon.Languages())
+	byID := make(map[string]models.BossStep, len(steps))
+	for _, st := range steps {
+		byID[st.ID] = st.Localized(lang, dungeon.DefaultLang)
+	}
+	points := make([]trackPoint, 0, len(run.KilledSteps))
+	for _, k := range run.KilledSteps {
+		if st, ok := byID[k.BossStepID]; ok {
+			points = append(points, trackPoint{Step: st, KilledAt: k.KilledAt})
+		}
+	}
+	return run, dungeon.Localized(lang), points, nil
+}
+
+func (s *Service) TrackGeoJSON(ctx context.Context, playerID, runID string, prefs []language.Tag) (models.FeatureCollection, error) {
+	run, dungeon, points, err := s.track(ctx, playerID, runID, prefs)
+	if err != nil {
+		return models.FeatureCollection{}, err
+	}
+	features := make([]models.Feature, 0, len(points)+1)
+	line := make([][2]float64, 0, len(points))
+	times := make([]string, 0, len(points))
+	for _, p := range points {
+		loc := p.Step.Location
+		features = append(features, models.PointFeature(p.Step.ID, loc.Lat, loc.Lon, map[string]any{
+			"order":    p.Step.Order,
+			"name":     p.Step.Name,
+			"killedAt": p.KilledAt.UTC().Format(time.RFC3339),
+		}))
+		line = append(line, [2]float64{loc.Lon, loc.Lat})
+		times = append(times, p.KilledAt.UTC().Format(time.RFC3339))
+	}
+	if len(line) >= 2 {
+		features = append(features, models.Feature{
+			Type:     "Feature",
+			ID:       run.ID,
+			Geometry: models.Geometry{Type: "LineString", Coordinates: line},
+			Properties: map[string]any{
+				"runId":        run.ID,
+				"dungeonTitle": dungeon.Title,
+				"state":        run.State,
+				"coordTimes":   times,
+			},
+		})
+	}
+	return models.NewFeatureCollection(features), nil
+}
+
+func (s *Service) TrackGPX(ctx context.Context, playerID, runID string, prefs []language.Tag) (models.GPX, error) {
+	run, dungeon, points, err := s.track(ctx, playerID, runID, prefs)
+	if err != nil {
+		return models.GPX{}, err
+	}
+	started := run.StartedAt.UTC()
+	out := models.GPX{
+		Version:  "1.1",
+		Creator:  "dungeons",
+		Metadata: models.GPXMetadata{Name: dungeon.Title, Time: &started},
+	}
+	segment := models.GPXSegment{Points: make([]models.GPXWaypoint, 0, len(points))}
+	for _, p := range points {
+		at := p.KilledAt.UTC()
+		wpt := models.GPXWaypoint{Lat: p.Step.Location.Lat, Lon: p.Step.Location.Lon, Time: &at, Name: p.Step.Name}
+		out.Waypoints = append(out.Waypoints, wpt)
+		segment.Points = append(segment.Points, wpt)
+	}
+	out.Tracks = []models.GPXTrack{{Name: dungeon.Title, Segments: []models.GPXSegment{segment}}}
+	return out, nil
+}
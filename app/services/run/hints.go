@@ -0,0 +1,78 @@
+package run
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/models"
+	"dungeons/app/mongodb"
+	"fmt"
+	"slices"
+)
+
+// BuyHint reveals a riddle hint for the current step. Buying the same hint
+// twice returns it again without charging.
+func (s *Service) BuyHint(ctx context.Context, playerID, runID, stepID string, index int) (models.HintResponse, error) {
+	var empty models.HintResponse
+	run, err := s.Get(ctx, playerID, runID)
+	if err != nil {
+		return empty, err
+	}
+	if run.State != models.RunStateActive {
+		return empty, fmt.Errorf("run is not active: %w", apperrors.ErrConflict)
+	}
+	step, err := s.dungeons.GetStep(ctx, run.DungeonID, stepID)
+	if err != nil {
+		return empty, fmt.Errorf("load step: %w", err)
+	}
+	if step.Kind() != models.StepTypeRiddle || step.Riddle == nil {
+		return empty, fmt.Errorf("step %s has no hints: %w", stepID, apperrors.ErrValidation)
+	}
+	if index < 0 || index >= len(step.Riddle.Hints) {
+		return empty, fmt.Errorf("hint %d: %w", index, apperrors.ErrNotFound)
+	}
+	if step.Order != run.CurrentStep {
+		return empty, fmt.Errorf("expected step order %d got %d: %w", run.CurrentStep, step.Order, apperrors.ErrWrongStepOrder)
+	}
+	hint := step.Riddle.Hints[index]
+	resp := models.HintResponse{RunID: runID, StepID: stepID, Index: index, Text: hint.Text, Cost: hint.Cost}
+
+	if slices.Contains(run.Hints[stepID], index) {
+		player, err := s.players.GetByID(ctx, playerID)
+		if err != nil {
+			return empty, fmt.Errorf("get player: %w", err)
+		}
+		resp.Player = player
+		return resp, nil
+	}
+
+	now := s.now()
+	err = mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+		added, err := s.runs.AddHintUsed(txCtx, runID, stepID, index)
+		if err != nil {
+			return err
+		}
+		if added && hint.Cost > 0 {
+			payment := models.GoldTransfer{
+				From:   models.PlayerAccount(playerID),
+				To:     models.AccountSink,
+				Amount: hint.Cost,
+				Reason: models.LedgerReasonHint,
+				Ref:    fmt.Sprintf("%s:%s:%d", runID, stepID, index),
+			}
+			if err := s.players.Transfer(txCtx, payment, now); err != nil {
+				return fmt.Errorf("pay hint: %w", err)
+			}
+			resp.Charged = true
+		}
+		player, err := s.players.GetByID(txCtx, playerID)
+		if err != nil {
+			return fmt.Errorf("get player: %w", err)
+		}
+		resp.Player = player
+		return nil
+	})
+	if err != nil {
+		return empty, fmt.Errorf("transaction buy hint: %w", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,235 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/functions"
+	"dungeons/app/models"
+	"dungeons/app/mongodb"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const MaxPhotoBytes = 8 << 20
+
+var photoExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// SubmitPhoto stores the upload and parks the run until an MJ reviews it.
+// The step is only paid on approval.
+func (s *Service) SubmitPhoto(ctx context.Context, playerID, runID, stepID string, req models.PhotoAttemptRequest, photo io.Reader) (models.PhotoSubmission, error) {
+	var empty models.PhotoSubmission
+	if err := s.validate.Struct(req); err != nil {
+		return empty, fmt.Errorf("validate photo attempt request: %w", apperrors.ErrValidation)
+	}
+	data, err := io.ReadAll(io.LimitReader(photo, MaxPhotoBytes+1))
+	if err != nil {
+		return empty, fmt.Errorf("read photo: %w", err)
+	}
+	if len(data) == 0 {
+		return empty, fmt.Errorf("photo is empty: %w", apperrors.ErrValidation)
+	}
+	if len(data) > MaxPhotoBytes {
+		return empty, fmt.Errorf("photo exceeds %d bytes: %w", MaxPhotoBytes, apperrors.ErrValidation)
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := photoExtensions[contentType]
+	if !ok {
+		return empty, fmt.Errorf("unsupported photo type %s: %w", contentType, apperrors.ErrValidation)
+	}
+
+	run, err := s.Get(ctx, playerID, runID)
+	if err != nil {
+		return empty, err
+	}
+	if run.State != models.RunStateActive {
+		return empty, fmt.Errorf("run is not active: %w", apperrors.ErrConflict)
+	}
+	if run.Pending != nil {
+		return empty, fmt.Errorf("a photo is already awaiting review: %w", apperrors.ErrConflict)
+	}
+	step, err := s.dungeons.GetStep(ctx, run.DungeonID, stepID)
+	if err != nil {
+		return empty, fmt.Errorf("load step: %w", err)
+	}
+	if step.Kind() != models.StepTypePhoto {
+		return empty, fmt.Errorf("step %s does not take photos: %w", stepID, apperrors.ErrValidation)
+	}
+
+	id := functions.NewUUID()
+	key := fmt.Sprintf("photos/%s/%s.%s", run.DungeonID, id, ext)
+	in := StepInput{Code: req.Code, PhotoKey: key}
+	if req.Lat != nil && req.Lon != nil {
+		in.Lat, in.Lon, in.HasPosition = *req.Lat, *req.Lon, true
+	}
+	checked, err := s.checkStep(ctx, run, step, in)
+	if err != nil {
+		return empty, err
+	}
+
+	if err := s.blobs.Put(ctx, key, bytes.NewReader(data)); err != nil {
+		return empty, fmt.Errorf("store photo: %w", err)
+	}
+	now := s.now()
+	sub := models.PhotoSubmission{
+		ID:          id,
+		RunID:       runID,
+		DungeonID:   run.DungeonID,
+		StepID:      stepID,
+		PlayerID:    playerID,
+		BlobKey:     key,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Lat:         req.Lat,
+		Lon:         req.Lon,
+		DistanceM:   checked.DistanceM,
+		Status:      models.PhotoStatusPending,
+		CreatedAt:   now,
+	}
+	err = mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+		if err := s.runs.CreatePhotoSubmission(txCtx, sub); err != nil {
+			return err
+		}
+		return s.runs.SetRunPending(txCtx, runID, &models.PendingProof{SubmissionID: id, StepID: stepID, SubmittedAt: now}, now)
+	})
+	if err != nil {
+		_ = s.blobs.Delete(ctx, key)
+		return empty, fmt.Errorf("transaction submit photo: %w", err)
+	}
+	return sub, nil
+}
+
+func (s *Service) ReviewQueue(ctx context.Context, mjID, dungeonID string, params models.QueryParams) ([]models.PhotoSubmission, error) {
+	dungeon, err := s.dungeons.GetDungeonByID(ctx, dungeonID)
+	if err != nil {
+		return nil, fmt.Errorf("get dungeon: %w", err)
+	}
+	if !dungeon.RoleOf(mjID).Allows(models.CollaboratorViewer) {
+		return nil, fmt.Errorf("cannot review foreign dungeon: %w", apperrors.ErrForbidden)
+	}
+	subs, err := s.runs.ListPhotoSubmissions(ctx, dungeonID, models.PhotoStatusPending, params)
+	if err != nil {
+		return nil, fmt.Errorf("list review queue: %w", err)
+	}
+	return subs, nil
+}
+
+func (s *Service) OpenSubmissionPhoto(ctx context.Context, mjID, submissionID string) (io.ReadCloser, models.PhotoSubmission, error) {
+	sub, err := s.reviewableSubmission(ctx, mjID, submissionID, models.CollaboratorViewer)
+	if err != nil {
+		return nil, sub, err
+	}
+	photo, err := s.blobs.Open(ctx, sub.BlobKey)
+	if err != nil {
+		return nil, sub, fmt.Errorf("open photo: %w", err)
+	}
+	return photo, sub, nil
+}
+
+func (s *Service) ApprovePhoto(ctx context.Context, mjID, submissionID string) (models.PhotoReviewResponse, error) {
+	var empty models.PhotoReviewResponse
+	sub, err := s.reviewableSubmission(ctx, mjID, submissionID, models.CollaboratorEditor)
+	if err != nil {
+		return empty, err
+	}
+	if sub.Status != models.PhotoStatusPending {
+		return empty, fmt.Errorf("photo already %s: %w", sub.Status, apperrors.ErrAlreadyHandled)
+	}
+	run, err := s.runs.GetRunByID(ctx, sub.RunID)
+	if err != nil {
+		return empty, fmt.Errorf("load run: %w", err)
+	}
+	if run.State != models.RunStateActive {
+		return empty, fmt.Errorf("run is not active: %w", apperrors.ErrConflict)
+	}
+	step, err := s.dungeons.GetStep(ctx, run.DungeonID, sub.StepID)
+	if err != nil {
+		return empty, fmt.Errorf("load step: %w", err)
+	}
+	if step.Order != run.CurrentStep {
+		return empty, fmt.Errorf("expected step order %d got %d: %w", run.CurrentStep, step.Order, apperrors.ErrWrongStepOrder)
+	}
+
+	now := s.now()
+	record := models.AttemptRecord{
+		ID:             functions.NewUUID(),
+		RunID:          run.ID,
+		DungeonID:      run.DungeonID,
+		StepID:         step.ID,
+		PlayerID:       run.PlayerID,
+		IdempotencyKey: "photo:" + sub.ID,
+		Rewards:        step.Rewards,
+		CreatedAt:      now,
+	}
+	sub.Status = models.PhotoStatusApproved
+	sub.ReviewedBy = mjID
+	sub.ReviewedAt = &now
+	sub.AttemptID = record.ID
+	attempt, err := s.grantStep(ctx, run, step, record, sub.DistanceM, func(txCtx context.Context) error {
+		return s.resolvePhoto(txCtx, sub)
+	})
+	if err != nil {
+		return empty, fmt.Errorf("transaction approve photo: %w", err)
+	}
+	return models.PhotoReviewResponse{Submission: sub, Attempt: &attempt}, nil
+}
+
+func (s *Service) RejectPhoto(ctx context.Context, mjID, submissionID string, req models.RejectPhotoRequest) (models.PhotoReviewResponse, error) {
+	var empty models.PhotoReviewResponse
+	if err := s.validate.Struct(req); err != nil {
+		return empty, fmt.Errorf("validate reject photo request: %w", apperrors.ErrValidation)
+	}
+	sub, err := s.reviewableSubmission(ctx, mjID, submissionID, models.CollaboratorEditor)
+	if err != nil {
+		return empty, err
+	}
+	if sub.Status != models.PhotoStatusPending {
+		return empty, fmt.Errorf("photo already %s: %w", sub.Status, apperrors.ErrAlreadyHandled)
+	}
+	now := s.now()
+	sub.Status = models.PhotoStatusRejected
+	sub.ReviewedBy = mjID
+	sub.ReviewedAt = &now
+	sub.Reason = req.Reason
+	err = mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+		if err := s.resolvePhoto(txCtx, sub); err != nil {
+			return err
+		}
+		return s.runs.SetRunPending(txCtx, sub.RunID, nil, now)
+	})
+	if err != nil {
+		return empty, fmt.Errorf("transaction reject photo: %w", err)
+	}
+	return models.PhotoReviewResponse{Submission: sub}, nil
+}
+
+func (s *Service) reviewableSubmission(ctx context.Context, mjID, submissionID string, need models.CollaboratorRole) (models.PhotoSubmission, error) {
+	sub, err := s.runs.GetPhotoSubmission(ctx, submissionID)
+	if err != nil {
+		return sub, fmt.Errorf("get photo submission: %w", err)
+	}
+	dungeon, err := s.dungeons.GetDungeonByID(ctx, sub.DungeonID)
+	if err != nil {
+		return sub, fmt.Errorf("get dungeon: %w", err)
+	}
+	if !dungeon.RoleOf(mjID).Allows(need) {
+		return sub, fmt.Errorf("cannot review foreign dungeon: %w", apperrors.ErrForbidden)
+	}
+	return sub, nil
+}
+
+func (s *Service) resolvePhoto(ctx context.Context, sub models.PhotoSubmission) error {
+	ok, err := s.runs.ResolvePhotoSubmission(ctx, sub)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("photo already reviewed: %w", apperrors.ErrAlreadyHandled)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package run
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/functions"
+	"dungeons/app/models"
+	"fmt"
+)
+
+// Sandbox runs let an MJ play through a draft dungeon. They live in their own
+// collection and never touch gold, inventory or the attempts ledger.
+
+func (s *Service) StartDryRun(ctx context.Context, mjID, dungeonID string) (models.Run, error) {
+	dungeon, err := s.dungeons.GetDungeonByID(ctx, dungeonID)
+	if err != nil {
+		return models.Run{}, fmt.Errorf("get dungeon for dry run: %w", err)
+	}
+	if !dungeon.RoleOf(mjID).Allows(models.CollaboratorViewer) {
+		return models.Run{}, fmt.Errorf("cannot dry run foreign dungeon: %w", apperrors.ErrForbidden)
+	}
+	if dungeon.Status == models.DungeonStatusArchived {
+		return models.Run{}, fmt.Errorf("dungeon is archived: %w", apperrors.ErrValidation)
+	}
+	steps, err := s.dungeons.ListStepsByDungeon(ctx, dungeonID)
+	if err != nil {
+		return models.Run{}, fmt.Errorf("list steps for dry run: %w", err)
+	}
+	if len(steps) == 0 {
+		return models.Run{}, fmt.Errorf("cannot dry run empty dungeon: %w", apperrors.ErrValidation)
+	}
+	now := s.now()
+	run := models.Run{
+		ID:          functions.NewUUID(),
+		DungeonID:   dungeonID,
+		PlayerID:    mjID,
+		State:       models.RunStateActive,
+		CurrentStep: 1,
+		KilledSteps: make([]models.KilledStep, 0),
+		StartedAt:   now,
+		UpdatedAt:   now,
+		DryRun:      true,
+	}
+	if err := s.runs.CreateSandboxRun(ctx, run); err != nil {
+		return models.Run{}, fmt.Errorf("create dry run: %w", err)
+	}
+	return run, nil
+}
+
+func (s *Service) GetDryRun(ctx context.Context, mjID, runID string) (models.Run, error) {
+	run, err := s.runs.GetSandboxRunByID(ctx, runID)
+	if err != nil {
+		return models.Run{}, fmt.Errorf("get dry run: %w", err)
+	}
+	if run.PlayerID != mjID {
+		return models.Run{}, fmt.Errorf("dry run owner mismatch: %w", apperrors.ErrForbidden)
+	}
+	return run, nil
+}
+
+func (s *Service) DryRunAttempt(ctx context.Context, mjID, runID, stepID string, req models.DryRunAttemptRequest) (models.DryRunAttemptResponse, error) {
+	var empty models.DryRunAttemptResponse
+	if err := s.validate.Struct(req); err != nil {
+		return empty, fmt.Errorf("validate dry run attempt request: %w", apperrors.ErrValidation)
+	}
+	run, err := s.GetDryRun(ctx, mjID, runID)
+	if err != nil {
+		return empty, err
+	}
+	if run.State != models.RunStateActive {
+		return empty, fmt.Errorf("dry run is not active: %w", apperrors.ErrConflict)
+	}
+	step, err := s.dungeons.GetStep(ctx, run.DungeonID, stepID)
+	if err != nil {
+		return empty, fmt.Errorf("load step: %w", err)
+	}
+
+	// Without a simulated position the MJ is teleported to the boss itself.
+	lat, lon := step.Location.Lat, step.Location.Lon
+	if req.Lat != nil && req.Lon != nil {
+		lat, lon = *req.Lat, *req.Lon
+	}
+	checked, err := s.checkStep(ctx, run, step, StepInput{Lat: lat, Lon: lon, HasPosition: true, Answer: req.Answer, Code: req.Code, DryRun: true})
+	if err != nil {
+		return empty, err
+	}
+
+	steps, err := s.dungeons.ListStepsByDungeon(ctx, run.DungeonID)
+	if err != nil {
+		return empty, fmt.Errorf("list steps for completion check: %w", err)
+	}
+	run = advanceRun(run, stepID, "", len(steps), s.now())
+	updated, err := s.runs.ReplaceSandboxRun(ctx, run)
+	if err != nil {
+		return empty, fmt.Errorf("update dry run progression: %w", err)
+	}
+	return models.DryRunAttemptResponse{
+		RunID:      runID,
+		StepID:     stepID,
+		Lat:        lat,
+		Lon:        lon,
+		DistanceM:  checked.DistanceM,
+		WouldAward: step.Rewards,
+		Run:        updated,
+		DryRun:     true,
+	}, nil
+}
@@ -4,9 +4,9 @@ import (
 	"context"
 	apperrors "dungeons/app/errors"
 	"dungeons/app/functions"
-	"dungeons/app/geo"
 	"dungeons/app/models"
 	"dungeons/app/mongodb"
+	"dungeons/app/storage"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -26,6 +26,16 @@ type RunRepository interface {
 	CreateAttemptRecord(ctx context.Context, record models.AttemptRecord) error
 	GetAttemptRecord(ctx context.Context, runID, stepID string) (models.AttemptRecord, error)
 	UpdateAttemptRecord(ctx context.Context, id string, response any, rewardApplied bool) error
+	CreateSandboxRun(ctx context.Context, run models.Run) error
+	GetSandboxRunByID(ctx context.Context, id string) (models.Run, error)
+	ReplaceSandboxRun(ctx context.Context, run models.Run) (models.Run, error)
+	IncrementStepTries(ctx context.Context, runID, stepID string, maxTries int) (models.Run, error)
+	AddHintUsed(ctx context.Context, runID, stepID string, index int) (bool, error)
+	SetRunPending(ctx context.Context, runID string, pending *models.PendingProof, updatedAt time.Time) error
+	CreatePhotoSubmission(ctx context.Context, sub models.PhotoSubmission) error
+	GetPhotoSubmission(ctx context.Context, id string) (models.PhotoSubmission, error)
+	ListPhotoSubmissions(ctx context.Context, dungeonID string, status models.PhotoStatus, params models.QueryParams) ([]models.PhotoSubmission, error)
+	ResolvePhotoSubmission(ctx context.Context, sub models.PhotoSubmission) (bool, error)
 }
 
 type DungeonRepository interface {
@@ -36,32 +46,44 @@ type DungeonRepository interface {
 
 type PlayerEconomyRepository interface {
 	GetByID(ctx context.Context, id string) (models.Player, error)
-	IncrementGold(ctx context.Context, id string, delta int64, updatedAt time.Time) (models.Player, error)
+	Transfer(ctx context.Context, t models.GoldTransfer, at time.Time) error
 }
 
 type InventoryRepository interface {
 	AddItem(ctx context.Context, playerID, itemID string, qty int64, updatedAt time.Time) error
 }
 
+// CampaignGate enforces campaign unlock chains and pays campaign rewards.
+type CampaignGate interface {
+	CheckUnlocked(ctx context.Context, playerID, dungeonID string) error
+	CompleteDungeon(ctx context.Context, playerID, dungeonID string, now time.Time) ([]models.CampaignCompletion, error)
+}
+
 type Service struct {
-	runs      RunRepository
-	dungeons  DungeonRepository
-	players   PlayerEconomyRepository
-	inventory InventoryRepository
-	validate  *validator.Validate
-	client    *mongo.Client
-	now       func() time.Time
+	runs       RunRepository
+	dungeons   DungeonRepository
+	players    PlayerEconomyRepository
+	inventory  InventoryRepository
+	blobs      storage.BlobStore
+	campaigns  CampaignGate
+	validators map[models.StepType]StepValidator
+	validate   *validator.Validate
+	client     *mongo.Client
+	now        func() time.Time
 }
 
-func New(runs RunRepository, dungeons DungeonRepository, players PlayerEconomyRepository, inventory InventoryRepository, validate *validator.Validate, client *mongo.Client) *Service {
+func New(runs RunRepository, dungeons DungeonRepository, players PlayerEconomyRepository, inventory InventoryRepository, blobs storage.BlobStore, campaigns CampaignGate, validate *validator.Validate, client *mongo.Client) *Service {
 	return &Service{
-		runs:      runs,
-		dungeons:  dungeons,
-		players:   players,
-		inventory: inventory,
-		validate:  validate,
-		client:    client,
-		now:       func() time.Time { return time.Now().UTC() },
+		runs:       runs,
+		dungeons:   dungeons,
+		players:    players,
+		inventory:  inventory,
+		blobs:      blobs,
+		campaigns:  campaigns,
+		validators: defaultValidators(runs),
+		validate:   validate,
+		client:     client,
+		now:        func() time.Time { return time.Now().UTC() },
 	}
 }
 
@@ -80,12 +102,17 @@ func (s *Service) Start(ctx context.Context, playerID string, req models.StartRu
 	if err != nil {
 		return models.Run{}, fmt.Errorf("get dungeon for run: %w", err)
 	}
-	if dungeon.Status != models.DungeonStatusPublished {
+	if !dungeon.IsLive(s.now()) {
 		return models.Run{}, fmt.Errorf("dungeon not published: %w", apperrors.ErrValidation)
 	}
 	if _, err := s.players.GetByID(ctx, playerID); err != nil {
 		return models.Run{}, fmt.Errorf("get player for run: %w", err)
 	}
+	if s.campaigns != nil {
+		if err := s.campaigns.CheckUnlocked(ctx, playerID, req.DungeonID); err != nil {
+			return models.Run{}, fmt.Errorf("check campaign unlocks: %w", err)
+		}
+	}
 	exists, err := s.runs.HasActiveRun(ctx, playerID, req.DungeonID)
 	if err != nil {
 		return models.Run{}, fmt.Errorf("check active run: %w", err)
@@ -150,14 +177,15 @@ func (s *Service) Attempt(ctx context.Context, playerID, runID, stepID string, r
 	if err != nil {
 		return empty, fmt.Errorf("load step: %w", err)
 	}
-	if step.Order != run.CurrentStep {
-		return empty, fmt.Errorf("expected step order %d got %d: %w", run.CurrentStep, step.Order, apperrors.ErrWrongStepOrder)
+	in := StepInput{Answer: req.Answer, Code: req.Code}
+	if req.Lat != nil && req.Lon != nil {
+		in.Lat, in.Lon, in.HasPosition = *req.Lat, *req.Lon, true
 	}
-
-	distance := geo.HaversineMeters(*req.Lat, *req.Lon, step.Location.Lat, step.Location.Lon)
-	if distance > step.Location.RadiusMeters {
-		return empty, fmt.Errorf("distance %.2f exceeds %.2f: %w", distance, step.Location.RadiusMeters, apperrors.ErrNotInRange)
+	checked, err := s.checkStep(ctx, run, step, in)
+	if err != nil {
+		return empty, err
 	}
+	run = checked.Run
 
 	if existing, err := s.runs.GetAttemptRecord(ctx, runID, stepID); err == nil {
 		if existing.IdempotencyKey != "" && existing.IdempotencyKey != req.IdempotencyKey {
@@ -176,85 +204,122 @@ func (s *Service) Attempt(ctx context.Context, playerID, runID, stepID string, r
 		return empty, fmt.Errorf("check attempt replay state: %w", err)
 	}
 
-	steps, err := s.dungeons.ListStepsByDungeon(ctx, run.DungeonID)
-	if err != nil {
-		return empty, fmt.Errorf("list steps for completion check: %w", err)
-	}
-	now := s.now()
 	record := models.AttemptRecord{
 		ID:             functions.NewUUID(),
 		RunID:          runID,
+		DungeonID:      run.DungeonID,
 		StepID:         stepID,
 		PlayerID:       playerID,
 		IdempotencyKey: req.IdempotencyKey,
 		RewardApplied:  false,
-		CreatedAt:      now,
+		Rewards:        step.Rewards,
+		CreatedAt:      s.now(),
+	}
+
+	response, txErr := s.grantStep(ctx, run, step, record, checked.DistanceM, nil)
+	if txErr != nil {
+		if errors.Is(txErr, apperrors.ErrAlreadyHandled) {
+			record, err := s.runs.GetAttemptRecord(ctx, runID, stepID)
+			if err != nil {
+				return empty, fmt.Errorf("load existing attempt after duplicate key: %w", err)
+			}
+			if record.IdempotencyKey != "" && record.IdempotencyKey != req.IdempotencyKey {
+				return empty, fmt.Errorf("attempt already handled with another idempotency key: %w", apperrors.ErrAlreadyHandled)
+			}
+			resp, convErr := decodeAttemptResponse(record.Response)
+			if convErr != nil {
+				return empty, fmt.Errorf("decode existing attempt response: %w", convErr)
+			}
+			resp.Idempotency = true
+			return resp, nil
+		}
+		return empty, fmt.Errorf("attempt transaction: %w", txErr)
 	}
+	return response, nil
+}
 
+// grantStep stores the attempt record, pays the step rewards and advances
+// the run in one transaction. also, when set, runs inside that transaction.
+func (s *Service) grantStep(ctx context.Context, run models.Run, step models.BossStep, record models.AttemptRecord, distance float64, also func(txCtx context.Context) error) (models.AttemptResponse, error) {
 	var response models.AttemptResponse
-	txErr := mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
+	steps, err := s.dungeons.ListStepsByDungeon(ctx, run.DungeonID)
+	if err != nil {
+		return response, fmt.Errorf("list steps for completion check: %w", err)
+	}
+	now := record.CreatedAt
+	err = mongodb.WithTransaction(ctx, s.client, func(txCtx context.Context) error {
 		if err := s.runs.CreateAttemptRecord(txCtx, record); err != nil {
 			return fmt.Errorf("create attempt idempotency record: %w", err)
 		}
 
-		updatedPlayer, err := s.players.IncrementGold(txCtx, playerID, step.Rewards.Gold, now)
-		if err != nil {
+		reward := models.GoldTransfer{
+			From:   models.AccountMint,
+			To:     models.PlayerAccount(record.PlayerID),
+			Amount: step.Rewards.Gold,
+			Reason: models.LedgerReasonStepReward,
+			Ref:    record.ID,
+		}
+		if err := s.players.Transfer(txCtx, reward, now); err != nil {
 			return fmt.Errorf("apply gold reward: %w", err)
 		}
 		for _, item := range step.Rewards.Items {
-			if err := s.inventory.AddItem(txCtx, playerID, item.ItemID, item.Qty, now); err != nil {
+			if err := s.inventory.AddItem(txCtx, record.PlayerID, item.ItemID, item.Qty, now); err != nil {
 				return fmt.Errorf("apply inventory reward item %s: %w", item.ItemID, err)
 			}
 		}
 
-		run.KilledSteps = append(run.KilledSteps, models.KilledStep{BossStepID: stepID, KilledAt: now, AttemptID: record.ID})
-		run.CurrentStep++
-		if run.CurrentStep > len(steps) {
-			run.State = models.RunStateCompleted
-			run.EndedAt = &now
-		}
-		run.UpdatedAt = now
+		run = advanceRun(run, step.ID, record.ID, len(steps), now)
+		run.Pending = nil
 
 		updatedRun, err := s.runs.ReplaceRun(txCtx, run)
 		if err != nil {
 			return fmt.Errorf("update run progression: %w", err)
 		}
 
+		var campaigns []models.CampaignCompletion
+		if updatedRun.State == models.RunStateCompleted && s.campaigns != nil {
+			campaigns, err = s.campaigns.CompleteDungeon(txCtx, record.PlayerID, run.DungeonID, now)
+			if err != nil {
+				return fmt.Errorf("complete campaigns: %w", err)
+			}
+		}
+		updatedPlayer, err := s.players.GetByID(txCtx, record.PlayerID)
+		if err != nil {
+			return fmt.Errorf("reload player after rewards: %w", err)
+		}
+
 		response = models.AttemptResponse{
-			RunID:       runID,
-			StepID:      stepID,
+			RunID:       run.ID,
+			StepID:      step.ID,
 			DistanceM:   distance,
 			Rewards:     step.Rewards,
 			Run:         updatedRun,
 			Player:      updatedPlayer,
 			Idempotency: false,
+			Campaigns:   campaigns,
 		}
 
 		if err := s.runs.UpdateAttemptRecord(txCtx, record.ID, response, true); err != nil {
 			return fmt.Errorf("persist attempt replay response: %w", err)
 		}
 
+		if also != nil {
+			return also(txCtx)
+		}
 		return nil
 	})
-	if txErr != nil {
-		if errors.Is(txErr, apperrors.ErrAlreadyHandled) {
-			record, err := s.runs.GetAttemptRecord(ctx, runID, stepID)
-			if err != nil {
-				return empty, fmt.Errorf("load existing attempt after duplicate key: %w", err)
-			}
-			if record.IdempotencyKey != "" && record.IdempotencyKey != req.IdempotencyKey {
-				return empty, fmt.Errorf("attempt already handled with another idempotency key: %w", apperrors.ErrAlreadyHandled)
-			}
-			resp, convErr := decodeAttemptResponse(record.Response)
-			if convErr != nil {
-				return empty, fmt.Errorf("decode existing attempt response: %w", convErr)
-			}
-			resp.Idempotency = true
-			return resp, nil
-		}
-		return empty, fmt.Errorf("attempt transaction: %w", txErr)
+	return response, err
+}
+
+func advanceRun(run models.Run, stepID, attemptID string, stepCount int, now time.Time) models.Run {
+	run.KilledSteps = append(run.KilledSteps, models.KilledStep{BossStepID: stepID, KilledAt: now, AttemptID: attemptID})
+	run.CurrentStep++
+	if run.CurrentStep > stepCount {
+		run.State = models.RunStateCompleted
+		run.EndedAt = &now
 	}
-	return response, nil
+	run.UpdatedAt = now
+	return run
 }
 
 func decodeAttemptResponse(raw any) (models.AttemptResponse, error) {
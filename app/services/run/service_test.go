@@ -3,8 +3,12 @@ package run
 import (
 	"context"
 	apperrors "dungeons/app/errors"
+	"dungeons/app/functions"
 	"dungeons/app/models"
+	"dungeons/app/otp"
+	"encoding/xml"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,9 +16,11 @@ import (
 )
 
 type runRepoStub struct {
-	run     models.Run
-	record  models.AttemptRecord
-	hasReco bool
+	run      models.Run
+	record   models.AttemptRecord
+	hasReco  bool
+	replaced models.Run
+	tries    int
 }
 
 func (s *runRepoStub) EnsureIndexes(context.Context) error         { return nil }
@@ -37,6 +43,42 @@ func (s *runRepoStub) GetAttemptRecord(context.Context, string, string) (models.
 	return models.AttemptRecord{}, apperrors.ErrNotFound
 }
 func (s *runRepoStub) UpdateAttemptRecord(context.Context, string, any, bool) error { return nil }
+func (s *runRepoStub) CreateSandboxRun(context.Context, models.Run) error           { return nil }
+func (s *runRepoStub) GetSandboxRunByID(context.Context, string) (models.Run, error) {
+	return s.run, nil
+}
+func (s *runRepoStub) ReplaceSandboxRun(_ context.Context, run models.Run) (models.Run, error) {
+	s.replaced = run
+	return run, nil
+}
+
+func (s *runRepoStub) IncrementStepTries(_ context.Context, _, stepID string, maxTries int) (models.Run, error) {
+	if maxTries > 0 && s.tries >= maxTries {
+		return models.Run{}, apperrors.ErrNoTriesLeft
+	}
+	s.tries++
+	run := s.run
+	run.Tries = map[string]int{stepID: s.tries}
+	return run, nil
+}
+func (s *runRepoStub) SetRunPending(context.Context, string, *models.PendingProof, time.Time) error {
+	return nil
+}
+func (s *runRepoStub) CreatePhotoSubmission(context.Context, models.PhotoSubmission) error {
+	return nil
+}
+func (s *runRepoStub) GetPhotoSubmission(context.Context, string) (models.PhotoSubmission, error) {
+	return models.PhotoSubmission{}, apperrors.ErrNotFound
+}
+func (s *runRepoStub) ListPhotoSubmissions(context.Context, string, models.PhotoStatus, models.QueryParams) ([]models.PhotoSubmission, error) {
+	return nil, nil
+}
+func (s *runRepoStub) ResolvePhotoSubmission(context.Context, models.PhotoSubmission) (bool, error) {
+	return true, nil
+}
+func (s *runRepoStub) AddHintUsed(context.Context, string, string, int) (bool, error) {
+	return true, nil
+}
 
 type dungeonRepoStub struct {
 	dungeon models.Dungeon
@@ -59,21 +101,35 @@ type playerRepoStub struct{}
 func (playerRepoStub) GetByID(context.Context, string) (models.Player, error) {
 	return models.Player{}, nil
 }
-func (playerRepoStub) IncrementGold(context.Context, string, int64, time.Time) (models.Player, error) {
-	return models.Player{}, nil
+func (playerRepoStub) Transfer(context.Context, models.GoldTransfer, time.Time) error {
+	return nil
 }
 
 type inventoryRepoStub struct{}
 
 func (inventoryRepoStub) AddItem(context.Context, string, string, int64, time.Time) error { return nil }
 
+type forbiddenEconomyStub struct{ t *testing.T }
+
+func (s forbiddenEconomyStub) GetByID(context.Context, string) (models.Player, error) {
+	return models.Player{}, nil
+}
+func (s forbiddenEconomyStub) Transfer(context.Context, models.GoldTransfer, time.Time) error {
+	s.t.Fatalf("dry run must not move gold")
+	return nil
+}
+func (s forbiddenEconomyStub) AddItem(context.Context, string, string, int64, time.Time) error {
+	s.t.Fatalf("dry run must not grant items")
+	return nil
+}
+
 func TestAttemptWrongStepOrder(t *testing.T) {
 	lat := 48.8566
 	lon := 2.3522
 	runs := &runRepoStub{run: models.Run{ID: "run-1", DungeonID: "d-1", PlayerID: "p-1", State: models.RunStateActive, CurrentStep: 2}}
 	dungeons := &dungeonRepoStub{step: models.BossStep{ID: "s-1", DungeonID: "d-1", Order: 1, Location: models.BossLocation{Lat: 48.8566, Lon: 2.3522, RadiusMeters: 100}}}
 
-	svc := New(runs, dungeons, playerRepoStub{}, inventoryRepoStub{}, validator.New(), nil)
+	svc := New(runs, dungeons, playerRepoStub{}, inventoryRepoStub{}, nil, nil, validator.New(), nil)
 	_, err := svc.Attempt(context.Background(), "p-1", "run-1", "s-1", models.AttemptRequest{Lat: &lat, Lon: &lon, IdempotencyKey: "idem-key-123"})
 	if !errors.Is(err, apperrors.ErrWrongStepOrder) {
 		t.Fatalf("expected wrong step order error, got %v", err)
@@ -94,7 +150,7 @@ func TestAttemptIdempotentReplay(t *testing.T) {
 	}
 	dungeons := &dungeonRepoStub{step: models.BossStep{ID: "s-1", DungeonID: "d-1", Order: 1, Location: models.BossLocation{Lat: 48.8566, Lon: 2.3522, RadiusMeters: 100}}}
 
-	svc := New(runs, dungeons, playerRepoStub{}, inventoryRepoStub{}, validator.New(), nil)
+	svc := New(runs, dungeons, playerRepoStub{}, inventoryRepoStub{}, nil, nil, validator.New(), nil)
 	resp, err := svc.Attempt(context.Background(), "p-1", "run-1", "s-1", models.AttemptRequest{Lat: &lat, Lon: &lon, IdempotencyKey: "idem-key-123"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -106,3 +162,151 @@ func TestAttemptIdempotentReplay(t *testing.T) {
 		t.Fatalf("unexpected replay payload: %#v", resp)
 	}
 }
+
+func TestDryRunAttemptSimulatesWithoutRewards(t *testing.T) {
+	step := models.BossStep{
+		ID:        "s-1",
+		DungeonID: "d-1",
+		Order:     1,
+		Location:  models.BossLocation{Lat: 48.8566, Lon: 2.3522, RadiusMeters: 50},
+		Rewards:   models.Rewards{Gold: 75},
+	}
+	runs := &runRepoStub{run: models.Run{ID: "dry-1", DungeonID: "d-1", PlayerID: "mj-1", State: models.RunStateActive, CurrentStep: 1, DryRun: true}}
+	dungeons := &dungeonRepoStub{step: step, steps: []models.BossStep{step}}
+
+	economy := forbiddenEconomyStub{t: t}
+	svc := New(runs, dungeons, economy, economy, nil, nil, validator.New(), nil)
+	resp, err := svc.DryRunAttempt(context.Background(), "mj-1", "dry-1", "s-1", models.DryRunAttemptRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.WouldAward.Gold != 75 || !resp.DryRun {
+		t.Fatalf("unexpected dry run payload: %#v", resp)
+	}
+	if runs.replaced.State != models.RunStateCompleted {
+		t.Fatalf("expected sandbox run to complete, got %s", runs.replaced.State)
+	}
+}
+
+func riddleStep(t *testing.T, maxTries int) models.BossStep {
+	t.Helper()
+	hash, err := functions.HashAndSalt(functions.NormalizeAnswer("Éléphant"))
+	if err != nil {
+		t.Fatalf("hash answer: %v", err)
+	}
+	return models.BossStep{
+		ID:        "s-1",
+		DungeonID: "d-1",
+		Order:     1,
+		Type:      models.StepTypeRiddle,
+		Riddle:    &models.Riddle{Question: "Grey and large?", AnswerHashes: []string{string(hash)}, MaxTries: maxTries},
+		Location:  models.BossLocation{Lat: 48.8566, Lon: 2.3522, RadiusMeters: 100},
+	}
+}
+
+func TestAttemptRiddleCountsWrongAnswers(t *testing.T) {
+	lat := 48.8566
+	lon := 2.3522
+	runs := &runRepoStub{run: models.Run{ID: "run-1", DungeonID: "d-1", PlayerID: "p-1", State: models.RunStateActive, CurrentStep: 1}}
+	dungeons := &dungeonRepoStub{step: riddleStep(t, 2)}
+	svc := New(runs, dungeons, playerRepoStub{}, inventoryRepoStub{}, nil, nil, validator.New(), nil)
+
+	req := models.AttemptRequest{Lat: &lat, Lon: &lon, IdempotencyKey: "idem-key-123", Answer: "giraffe"}
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Attempt(context.Background(), "p-1", "run-1", "s-1", req); !errors.Is(err, apperrors.ErrWrongAnswer) {
+			t.Fatalf("try %d: expected wrong answer, got %v", i+1, err)
+		}
+	}
+	req.Answer = "elephant"
+	if _, err := svc.Attempt(context.Background(), "p-1", "run-1", "s-1", req); !errors.Is(err, apperrors.ErrNoTriesLeft) {
+		t.Fatalf("expected no tries left, got %v", err)
+	}
+}
+
+func TestDryRunRiddleIgnoresCaseAndAccents(t *testing.T) {
+	step := riddleStep(t, 1)
+	runs := &runRepoStub{run: models.Run{ID: "dry-1", DungeonID: "d-1", PlayerID: "mj-1", State: models.RunStateActive, CurrentStep: 1, DryRun: true}}
+	dungeons := &dungeonRepoStub{step: step, steps: []models.BossStep{step}}
+	economy := forbiddenEconomyStub{t: t}
+	svc := New(runs, dungeons, economy, economy, nil, nil, validator.New(), nil)
+
+	if _, err := svc.DryRunAttempt(context.Background(), "mj-1", "dry-1", "s-1", models.DryRunAttemptRequest{Answer: "  ELEPHANT "}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if runs.tries != 0 {
+		t.Fatalf("dry run must not consume tries, got %d", runs.tries)
+	}
+}
+
+func TestDryRunCodeOnlyStepIgnoresDistance(t *testing.T) {
+	secret, err := otp.NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := models.BossStep{
+		ID:        "s-1",
+		DungeonID: "d-1",
+		Order:     1,
+		Proof:     &models.ProofPolicy{Require: models.ProofCode, Mode: models.CodeModeStatic, Secret: secret},
+		Location:  models.BossLocation{Lat: 48.8566, Lon: 2.3522, RadiusMeters: 20},
+	}
+	runs := &runRepoStub{run: models.Run{ID: "dry-1", DungeonID: "d-1", PlayerID: "mj-1", State: models.RunStateActive, CurrentStep: 1, DryRun: true}}
+	dungeons := &dungeonRepoStub{step: step, steps: []models.BossStep{step}}
+	economy := forbiddenEconomyStub{t: t}
+	svc := New(runs, dungeons, economy, economy, nil, nil, validator.New(), nil)
+
+	farLat, farLon := 45.764, 4.8357
+	req := models.DryRunAttemptRequest{Lat: &farLat, Lon: &farLon, Code: "00000000"}
+	if _, err := svc.DryRunAttempt(context.Background(), "mj-1", "dry-1", "s-1", req); !errors.Is(err, apperrors.ErrInvalidProof) {
+		t.Fatalf("expected invalid proof, got %v", err)
+	}
+	req.Code, _ = otp.Static(secret)
+	if _, err := svc.DryRunAttempt(context.Background(), "mj-1", "dry-1", "s-1", req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAttemptPhotoStepNeedsUpload(t *testing.T) {
+	lat := 48.8566
+	lon := 2.3522
+	runs := &runRepoStub{run: models.Run{ID: "run-1", DungeonID: "d-1", PlayerID: "p-1", State: models.RunStateActive, CurrentStep: 1}}
+	dungeons := &dungeonRepoStub{step: models.BossStep{ID: "s-1", DungeonID: "d-1", Order: 1, Type: models.StepTypePhoto, Location: models.BossLocation{Lat: lat, Lon: lon, RadiusMeters: 100}}}
+
+	svc := New(runs, dungeons, playerRepoStub{}, inventoryRepoStub{}, nil, nil, validator.New(), nil)
+	_, err := svc.Attempt(context.Background(), "p-1", "run-1", "s-1", models.AttemptRequest{Lat: &lat, Lon: &lon, IdempotencyKey: "idem-key-123"})
+	if !errors.Is(err, apperrors.ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	_, err = svc.SubmitPhoto(context.Background(), "p-1", "run-1", "s-1", models.PhotoAttemptRequest{Lat: &lat, Lon: &lon}, strings.NewReader("not an image"))
+	if !errors.Is(err, apperrors.ErrValidation) {
+		t.Fatalf("expected validation error for non-image upload, got %v", err)
+	}
+}
+
+func TestTrackGPXListsKillsInOrder(t *testing.T) {
+	killed := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	runs := &runRepoStub{run: models.Run{
+		ID: "run-1", DungeonID: "d-1", PlayerID: "p-1", State: models.RunStateActive, CurrentStep: 3,
+		KilledSteps: []models.KilledStep{{BossStepID: "s-2", KilledAt: killed}, {BossStepID: "s-1", KilledAt: killed.Add(time.Hour)}},
+	}}
+	dungeons := &dungeonRepoStub{
+		dungeon: models.Dungeon{ID: "d-1", Title: "Crypte", DefaultLang: "fr"},
+		steps: []models.BossStep{
+			{ID: "s-1", Name: "Liche", Location: models.BossLocation{Lat: 48.1, Lon: 2.1}},
+			{ID: "s-2", Name: "Goule", Location: models.BossLocation{Lat: 48.2, Lon: 2.2}},
+		},
+	}
+	svc := New(runs, dungeons, playerRepoStub{}, inventoryRepoStub{}, nil, nil, validator.New(), nil)
+	track, err := svc.TrackGPX(context.Background(), "p-1", "run-1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, err := xml.Marshal(track)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), `<trkpt lat="48.2" lon="2.2"><time>2026-05-01T10:00:00Z</time><name>Goule</name></trkpt><trkpt lat="48.1"`) {
+		t.Fatalf("unexpected gpx: %s", body)
+	}
+}
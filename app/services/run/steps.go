@@ -0,0 +1,139 @@
+package run
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"dungeons/app/functions"
+	"dungeons/app/geo"
+	"dungeons/app/models"
+	"dungeons/app/otp"
+	"fmt"
+	"time"
+)
+
+type StepInput struct {
+	Lat         float64
+	Lon         float64
+	HasPosition bool
+	Answer      string
+	Code        string
+	DryRun      bool
+	// PhotoKey is the stored upload; only SubmitPhoto sets it.
+	PhotoKey string
+	// SkipRange is set by checkStep when the proof policy does not require GPS.
+	SkipRange bool
+}
+
+// StepResult carries the run back because some validators update it (try
+// counters) and the caller must not overwrite that with a stale copy.
+type StepResult struct {
+	Run       models.Run
+	DistanceM float64
+}
+
+type StepValidator interface {
+	Validate(ctx context.Context, run models.Run, step models.BossStep, in StepInput) (StepResult, error)
+}
+
+func defaultValidators(runs RunRepository) map[models.StepType]StepValidator {
+	return map[models.StepType]StepValidator{
+		models.StepTypeLocation: locationValidator{},
+		models.StepTypeRiddle:   riddleValidator{runs: runs},
+		models.StepTypePhoto:    photoValidator{},
+	}
+}
+
+func (s *Service) checkStep(ctx context.Context, run models.Run, step models.BossStep, in StepInput) (StepResult, error) {
+	if step.Order != run.CurrentStep {
+		return StepResult{Run: run}, fmt.Errorf("expected step order %d got %d: %w", run.CurrentStep, step.Order, apperrors.ErrWrongStepOrder)
+	}
+	needGPS, needCode := step.Proofs()
+	// A dry run without a code simulates a successful scan.
+	if needCode && !(in.DryRun && in.Code == "") {
+		if !verifyCode(step.Proof, in.Code, s.now()) {
+			return StepResult{Run: run}, fmt.Errorf("code rejected for step %s: %w", step.ID, apperrors.ErrInvalidProof)
+		}
+	}
+	if needGPS && !in.HasPosition {
+		return StepResult{Run: run}, fmt.Errorf("position required: %w", apperrors.ErrValidation)
+	}
+	in.SkipRange = !needGPS
+
+	validator, ok := s.validators[step.Kind()]
+	if !ok {
+		return StepResult{Run: run}, fmt.Errorf("unsupported step type %q: %w", step.Kind(), apperrors.ErrValidation)
+	}
+	return validator.Validate(ctx, run, step, in)
+}
+
+type locationValidator struct{}
+
+func (locationValidator) Validate(_ context.Context, run models.Run, step models.BossStep, in StepInput) (StepResult, error) {
+	res := StepResult{Run: run}
+	if !in.HasPosition {
+		return res, nil
+	}
+	distance := geo.HaversineMeters(in.Lat, in.Lon, step.Location.Lat, step.Location.Lon)
+	res.DistanceM = distance
+	if !in.SkipRange && distance > step.Location.RadiusMeters {
+		return res, fmt.Errorf("distance %.2f exceeds %.2f: %w", distance, step.Location.RadiusMeters, apperrors.ErrNotInRange)
+	}
+	return res, nil
+}
+
+type riddleValidator struct {
+	runs RunRepository
+}
+
+func (v riddleValidator) Validate(ctx context.Context, run models.Run, step models.BossStep, in StepInput) (StepResult, error) {
+	res, err := locationValidator{}.Validate(ctx, run, step, in)
+	if err != nil {
+		return res, err
+	}
+	if step.Riddle == nil {
+		return res, fmt.Errorf("riddle step %s has no riddle: %w", step.ID, apperrors.ErrValidation)
+	}
+	answer := functions.NormalizeAnswer(in.Answer)
+	if answer == "" {
+		return res, fmt.Errorf("answer required: %w", apperrors.ErrValidation)
+	}
+	// The try is reserved before comparing so parallel guesses cannot exceed
+	// the budget. Dry runs do not count.
+	if !in.DryRun {
+		updated, err := v.runs.IncrementStepTries(ctx, run.ID, step.ID, step.Riddle.MaxTries)
+		if err != nil {
+			return res, err
+		}
+		res.Run = updated
+	}
+	for _, hash := range step.Riddle.AnswerHashes {
+		if functions.CheckPassword(answer, hash) == nil {
+			return res, nil
+		}
+	}
+	if step.Riddle.MaxTries > 0 && !in.DryRun {
+		left := max(step.Riddle.MaxTries-res.Run.Tries[step.ID], 0)
+		return res, fmt.Errorf("%d tries left: %w", left, apperrors.ErrWrongAnswer)
+	}
+	return res, fmt.Errorf("wrong answer: %w", apperrors.ErrWrongAnswer)
+}
+
+// photoValidator only accepts photo uploads; rewards wait for MJ review.
+type photoValidator struct{}
+
+func (photoValidator) Validate(ctx context.Context, run models.Run, step models.BossStep, in StepInput) (StepResult, error) {
+	if in.PhotoKey == "" && !in.DryRun {
+		return StepResult{Run: run}, fmt.Errorf("step %s needs a photo upload: %w", step.ID, apperrors.ErrValidation)
+	}
+	return locationValidator{}.Validate(ctx, run, step, in)
+}
+
+func verifyCode(policy *models.ProofPolicy, code string, now time.Time) bool {
+	if policy == nil || policy.Secret == "" || code == "" {
+		return false
+	}
+	if policy.Mode == models.CodeModeTOTP {
+		return otp.VerifyTOTP(policy.Secret, code, now, time.Duration(policy.PeriodSeconds)*time.Second, otp.DefaultSkew)
+	}
+	return otp.VerifyStatic(policy.Secret, code)
+}
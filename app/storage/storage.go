@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	apperrors "dungeons/app/errors"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9_./-]*$`)
+
+// ValidKey rejects keys that could escape the store root.
+func ValidKey(key string) bool {
+	if !keyPattern.MatchString(key) {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("create blob root: %w", err)
+	}
+	return &LocalStore{root: root}, nil
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", fmt.Errorf("blob key %q: %w", key, apperrors.ErrValidation)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes to a temporary file first so readers never see a partial blob.
+func (s *LocalStore) Put(_ context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create blob dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("create temp blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close blob: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("commit blob: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStore) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("blob %s: %w", key, apperrors.ErrNotFound)
+		}
+		return nil, fmt.Errorf("open blob: %w", err)
+	}
+	return f, nil
+}
+
+func (s *LocalStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("delete blob: %w", err)
+	}
+	return nil
+}
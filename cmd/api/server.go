@@ -3,29 +3,58 @@ package main
 import (
 	"context"
 	"dungeons/app/auth"
+	analyticscontroller "dungeons/app/controllers/analytics"
 	auctioncontroller "dungeons/app/controllers/auction"
+	campaigncontroller "dungeons/app/controllers/campaign"
 	dungeoncontroller "dungeons/app/controllers/dungeon"
 	inventorycontroller "dungeons/app/controllers/inventory"
+	mailcontroller "dungeons/app/controllers/mail"
+	mediacontroller "dungeons/app/controllers/media"
+	offercontroller "dungeons/app/controllers/offer"
 	playercontroller "dungeons/app/controllers/player"
+	reviewcontroller "dungeons/app/controllers/review"
 	runcontroller "dungeons/app/controllers/run"
+	vendorcontroller "dungeons/app/controllers/vendor"
+	"dungeons/app/jobs"
 	"dungeons/app/mongodb"
+	analyticsrepo "dungeons/app/repositories/analytics"
 	auctionrepo "dungeons/app/repositories/auction"
+	campaignrepo "dungeons/app/repositories/campaign"
 	dungeonrepo "dungeons/app/repositories/dungeon"
 	inventoryrepo "dungeons/app/repositories/inventory"
+	mailrepo "dungeons/app/repositories/mail"
+	offerrepo "dungeons/app/repositories/offer"
 	playerrepo "dungeons/app/repositories/player"
+	reviewrepo "dungeons/app/repositories/review"
 	runrepo "dungeons/app/repositories/run"
+	vendorrepo "dungeons/app/repositories/vendor"
+	analyticsroutes "dungeons/app/routes/analytics"
 	auctionroutes "dungeons/app/routes/auction"
+	campaignroutes "dungeons/app/routes/campaign"
 	dungeonroutes "dungeons/app/routes/dungeon"
 	inventoryroutes "dungeons/app/routes/inventory"
+	mailroutes "dungeons/app/routes/mail"
+	mediaroutes "dungeons/app/routes/media"
+	offerroutes "dungeons/app/routes/offer"
 	playerroutes "dungeons/app/routes/player"
+	reviewroutes "dungeons/app/routes/review"
 	runroutes "dungeons/app/routes/run"
+	vendorroutes "dungeons/app/routes/vendor"
 	"dungeons/app/seed"
 	"dungeons/app/server"
+	analyticsservice "dungeons/app/services/analytics"
 	auctionservice "dungeons/app/services/auction"
+	campaignservice "dungeons/app/services/campaign"
 	dungeonservice "dungeons/app/services/dungeon"
 	inventoryservice "dungeons/app/services/inventory"
+	mailservice "dungeons/app/services/mail"
+	mediaservice "dungeons/app/services/media"
+	offerservice "dungeons/app/services/offer"
 	playerservice "dungeons/app/services/player"
+	reviewservice "dungeons/app/services/review"
 	runservice "dungeons/app/services/run"
+	vendorservice "dungeons/app/services/vendor"
+	"dungeons/app/storage"
 	"errors"
 	"os"
 
@@ -70,17 +99,36 @@ func newDungeonsServer() error {
 
 	validate := validator.New()
 
+	blobs, err := storage.NewLocalStore(srv.StorageDir)
+	if err != nil {
+		return err
+	}
+
 	playerRepository := playerrepo.NewMongoRepository(srv.Database, srv.DBTimeout)
 	dungeonRepository := dungeonrepo.NewMongoRepository(srv.Database, srv.DBTimeout)
 	runRepository := runrepo.NewMongoRepository(srv.Database, srv.DBTimeout)
 	inventoryRepository := inventoryrepo.NewMongoRepository(srv.Database, srv.DBTimeout)
 	auctionRepository := auctionrepo.NewMongoRepository(srv.Database, srv.DBTimeout)
-
+	reviewRepository := reviewrepo.NewMongoRepository(srv.Database, srv.DBTimeout)
+	analyticsRepository := analyticsrepo.NewMongoRepository(srv.Database, srv.DBTimeout)
+	campaignRepository := campaignrepo.NewMongoRepository(srv.Database, srv.DBTimeout)
+	offerRepository := offerrepo.NewMongoRepository(srv.Database, srv.DBTimeout)
+	mailRepository := mailrepo.NewMongoRepository(srv.Database, srv.DBTimeout)
+	vendorRepository := vendorrepo.NewMongoRepository(srv.Database, srv.DBTimeout)
+
+	mediaSvc := mediaservice.New(blobs)
 	playerSvc := playerservice.New(playerRepository, validate, playerservice.NewHMACTokenSigner(srv.TokenKey), srv.TokenTTL)
-	dungeonSvc := dungeonservice.New(dungeonRepository, validate)
-	runSvc := runservice.New(runRepository, dungeonRepository, playerRepository, inventoryRepository, validate, srv.MongoClient)
+	routeOptions := dungeonservice.RouteOptions{MetersPerMinute: float64(srv.WalkingSpeed), MaxMeters: float64(srv.MaxRouteKm) * 1000}
+	dungeonSvc := dungeonservice.New(dungeonRepository, playerRepository, mediaSvc, routeOptions, validate, srv.MongoClient)
+	campaignSvc := campaignservice.New(campaignRepository, dungeonRepository, runRepository, playerRepository, inventoryRepository, validate)
+	runSvc := runservice.New(runRepository, dungeonRepository, playerRepository, inventoryRepository, blobs, campaignSvc, validate, srv.MongoClient)
 	inventorySvc := inventoryservice.New(inventoryRepository)
-	auctionSvc := auctionservice.New(auctionRepository, inventoryRepository, playerRepository, validate, srv.MongoClient)
+	auctionSvc := auctionservice.New(auctionRepository, inventoryRepository, playerRepository, srv.AuctionFees, validate, srv.MongoClient)
+	reviewSvc := reviewservice.New(reviewRepository, dungeonRepository, runRepository, validate)
+	analyticsSvc := analyticsservice.New(analyticsRepository, dungeonRepository)
+	offerSvc := offerservice.New(offerRepository, inventoryRepository, playerRepository, validate, srv.MongoClient)
+	mailSvc := mailservice.New(mailRepository, inventoryRepository, playerRepository, validate, srv.MongoClient)
+	vendorSvc := vendorservice.New(vendorRepository, inventoryRepository, playerRepository, validate, srv.MongoClient)
 
 	for _, ensure := range []func(context.Context) error{
 		playerSvc.EnsureIndexes,
@@ -88,6 +136,11 @@ func newDungeonsServer() error {
 		runSvc.EnsureIndexes,
 		inventorySvc.EnsureIndexes,
 		auctionSvc.EnsureIndexes,
+		reviewSvc.EnsureIndexes,
+		campaignSvc.EnsureIndexes,
+		offerSvc.EnsureIndexes,
+		mailSvc.EnsureIndexes,
+		vendorSvc.EnsureIndexes,
 	} {
 		if err := ensure(context.Background()); err != nil {
 			return err
@@ -105,6 +158,13 @@ func newDungeonsServer() error {
 	runHandler := runcontroller.New(runSvc)
 	inventoryHandler := inventorycontroller.New(inventorySvc)
 	auctionHandler := auctioncontroller.New(auctionSvc)
+	reviewHandler := reviewcontroller.New(reviewSvc)
+	analyticsHandler := analyticscontroller.New(analyticsSvc)
+	mediaHandler := mediacontroller.New(mediaSvc)
+	campaignHandler := campaigncontroller.New(campaignSvc)
+	offerHandler := offercontroller.New(offerSvc)
+	mailHandler := mailcontroller.New(mailSvc)
+	vendorHandler := vendorcontroller.New(vendorSvc)
 
 	authMiddleware := auth.RequireAuth(srv.TokenKey)
 	v1 := srv.Router.Group("/v1")
@@ -113,6 +173,65 @@ func newDungeonsServer() error {
 	runroutes.SetupRouter(v1, runHandler, authMiddleware)
 	inventoryroutes.SetupRouter(v1, inventoryHandler, authMiddleware)
 	auctionroutes.SetupRouter(v1, auctionHandler, authMiddleware)
+	reviewroutes.SetupRouter(v1, reviewHandler, authMiddleware)
+	analyticsroutes.SetupRouter(v1, analyticsHandler, authMiddleware)
+	mediaroutes.SetupRouter(v1, mediaHandler)
+	campaignroutes.SetupRouter(v1, campaignHandler, authMiddleware)
+	offerroutes.SetupRouter(v1, offerHandler, authMiddleware)
+	mailroutes.SetupRouter(v1, mailHandler, authMiddleware)
+	vendorroutes.SetupRouter(v1, vendorHandler, authMiddleware)
+
+	jobs.Start(context.Background(), jobs.Job{
+		Name:     "dungeon-schedule",
+		Interval: srv.ScheduleInterval,
+		Run: func(ctx context.Context) error {
+			published, archived, err := dungeonSvc.RunSchedule(ctx)
+			if published > 0 || archived > 0 {
+				log.Info().Int("published", published).Int("archived", archived).Msg("dungeon schedule applied")
+			}
+			return err
+		},
+	}, jobs.Job{
+		Name:     "auction-expiry",
+		Interval: srv.ExpiryInterval,
+		Run: func(ctx context.Context) error {
+			expired, err := auctionSvc.ExpireListings(ctx)
+			if expired > 0 {
+				log.Info().Int("expired", expired).Msg("auction listings expired")
+			}
+			return err
+		},
+	}, jobs.Job{
+		Name:     "auction-settle",
+		Interval: srv.ExpiryInterval,
+		Run: func(ctx context.Context) error {
+			settled, err := auctionSvc.SettleAuctions(ctx)
+			if settled > 0 {
+				log.Info().Int("settled", settled).Msg("auctions settled")
+			}
+			return err
+		},
+	}, jobs.Job{
+		Name:     "offer-expiry",
+		Interval: srv.ExpiryInterval,
+		Run: func(ctx context.Context) error {
+			expired, err := offerSvc.ExpireOffers(ctx)
+			if expired > 0 {
+				log.Info().Int("expired", expired).Msg("trade offers expired")
+			}
+			return err
+		},
+	}, jobs.Job{
+		Name:     "mail-expiry",
+		Interval: srv.ExpiryInterval,
+		Run: func(ctx context.Context) error {
+			expired, err := mailSvc.ExpireMail(ctx)
+			if expired > 0 {
+				log.Info().Int("expired", expired).Msg("mail expired")
+			}
+			return err
+		},
+	})
 
 	server.SetServer(srv)
 	return nil
@@ -0,0 +1,107 @@
+// Command reconcile checks player gold balances against the gold ledger.
+// It exits non-zero on any mismatch. Gold keeps moving while the API runs,
+// so run it against a quiet database for an exact answer.
+package main
+
+import (
+	"context"
+	"dungeons/app/models"
+	"dungeons/app/mongodb"
+	auctionrepo "dungeons/app/repositories/auction"
+	playerrepo "dungeons/app/repositories/player"
+	"dungeons/app/server"
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/rs/zerolog/log"
+)
+
+func main() {
+	backfill := flag.Bool("backfill", false, "book the gold of players without any ledger entry as an opening balance")
+	flag.Parse()
+
+	if os.Getenv("MODE") == "" {
+		if err := godotenv.Load(); err != nil {
+			var pathErr *os.PathError
+			if !errors.As(err, &pathErr) {
+				log.Fatal().Err(err).Msg("load env")
+			}
+		}
+	}
+
+	srv := &server.Dungeons{}
+	srv.ParseParameters()
+
+	ctx, cancel := context.WithTimeout(context.Background(), srv.DBTimeout)
+	defer cancel()
+	client, err := mongodb.OpenMongoDB(ctx, srv.DBHost)
+	if err != nil {
+		log.Fatal().Err(err).Msg("connect mongo")
+	}
+	defer client.Disconnect(context.Background())
+
+	db := client.Database(srv.DBName)
+	mismatches, err := reconcile(context.Background(), playerrepo.NewMongoRepository(db, srv.DBTimeout), auctionrepo.NewMongoRepository(db, srv.DBTimeout), *backfill)
+	if err != nil {
+		log.Fatal().Err(err).Msg("reconcile failed")
+	}
+	if mismatches > 0 {
+		log.Error().Int("mismatches", mismatches).Msg("gold ledger does not reconcile")
+		os.Exit(1)
+	}
+	log.Info().Msg("gold ledger reconciles")
+}
+
+func reconcile(ctx context.Context, players *playerrepo.MongoRepository, auction *auctionrepo.MongoRepository, backfill bool) (int, error) {
+	balances, err := players.LedgerBalances(ctx)
+	if err != nil {
+		return 0, err
+	}
+	mismatches := 0
+
+	unbalanced, err := players.UnbalancedTransfers(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range unbalanced {
+		log.Error().Str("transfer", id).Msg("transfer entries do not sum to zero")
+		mismatches++
+	}
+
+	for page := int64(1); ; page++ {
+		batch, err := players.List(ctx, models.QueryParams{Page: page, Limit: 100})
+		if err != nil {
+			return 0, err
+		}
+		for _, p := range batch {
+			account := models.PlayerAccount(p.ID)
+			ledger, booked := balances[account]
+			if !booked && backfill && p.Gold > 0 {
+				if err := players.RecordOpening(ctx, p.ID, p.Gold, p.CreatedAt); err != nil {
+					return 0, err
+				}
+				log.Info().Str("player", p.ID).Int64("gold", p.Gold).Msg("opening balance booked")
+				continue
+			}
+			if p.Gold != ledger {
+				log.Error().Str("player", p.ID).Int64("gold", p.Gold).Int64("ledger", ledger).Msg("balance mismatch")
+				mismatches++
+			}
+		}
+		if len(batch) < 100 {
+			break
+		}
+	}
+
+	treasury, err := auction.GetTreasury(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if treasury.Total != balances[models.AccountTreasury] {
+		log.Error().Int64("treasury", treasury.Total).Int64("ledger", balances[models.AccountTreasury]).Msg("treasury mismatch")
+		mismatches++
+	}
+	return mismatches, nil
+}